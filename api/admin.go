@@ -0,0 +1,223 @@
+package api
+
+import (
+	"context"
+	logger "github.com/VerzCar/vyf-lib-logger"
+	"github.com/VerzCar/vyf-vote-circle/api/model"
+	"github.com/VerzCar/vyf-vote-circle/app/config"
+	"github.com/VerzCar/vyf-vote-circle/app/database"
+	routerContext "github.com/VerzCar/vyf-vote-circle/app/router/ctx"
+	"strconv"
+)
+
+const (
+	// userOptionAuditsLimit of the audits returned for the option of a user
+	userOptionAuditsLimit = 100
+)
+
+// AdminService manages the options of the users with their package and
+// the limits overriding the plan of the package. Every change is audited
+// with the admin, that changed it. The changes apply immediately to the
+// quotas enforced for the user.
+type AdminService interface {
+	UserOption(
+		ctx context.Context,
+		userIdentityId string,
+	) (*model.AdminUserOptionResponse, error)
+	UpdateUserOption(
+		ctx context.Context,
+		userIdentityId string,
+		userOptionUpdateRequest *model.UserOptionUpdateRequest,
+	) (*model.AdminUserOptionResponse, error)
+	UserOptionAudits(
+		ctx context.Context,
+		userIdentityId string,
+	) ([]*model.UserOptionAudit, error)
+}
+
+type AdminRepository interface {
+	UserOptionByUserIdentityId(userIdentityId string) (*model.UserOption, error)
+	SaveUserOption(
+		option *model.UserOption,
+		audits []*model.UserOptionAudit,
+	) (*model.UserOption, error)
+	UserOptionAuditsByUserIdentityId(
+		userIdentityId string,
+		limit int,
+	) ([]*model.UserOptionAudit, error)
+}
+
+type AdminOptionService interface {
+	UserOptionOfUser(
+		userIdentityId string,
+	) (*model.UserOptionResponse, error)
+}
+
+type adminService struct {
+	storage           AdminRepository
+	userOptionService AdminOptionService
+	config            *config.Config
+	log               logger.Logger
+}
+
+func NewAdminService(
+	adminRepo AdminRepository,
+	userOptionService AdminOptionService,
+	config *config.Config,
+	log logger.Logger,
+) AdminService {
+	return &adminService{
+		storage:           adminRepo,
+		userOptionService: userOptionService,
+		config:            config,
+		log:               log,
+	}
+}
+
+// UserOption stored for the user and the resulting quotas of the user.
+// If no option is stored for the user, the overrides are empty.
+func (c *adminService) UserOption(
+	ctx context.Context,
+	userIdentityId string,
+) (*model.AdminUserOptionResponse, error) {
+	if _, err := routerContext.ContextToAuthClaims(ctx); err != nil {
+		c.log.Errorf("error getting auth claims: %s", err)
+		return nil, err
+	}
+
+	option, err := c.storedUserOption(userIdentityId)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return c.adminUserOptionResponse(option)
+}
+
+// UpdateUserOption of the user with the given package and limits.
+// The option is created if the user has none yet. A changed package changes
+// the quotas of the plan, the limits that are not 0 keep overriding them.
+// Each changed field is audited with the admin, that changed it.
+func (c *adminService) UpdateUserOption(
+	ctx context.Context,
+	userIdentityId string,
+	userOptionUpdateRequest *model.UserOptionUpdateRequest,
+) (*model.AdminUserOptionResponse, error) {
+	authClaims, err := routerContext.ContextToAuthClaims(ctx)
+
+	if err != nil {
+		c.log.Errorf("error getting auth claims: %s", err)
+		return nil, err
+	}
+
+	option, err := c.storedUserOption(userIdentityId)
+
+	if err != nil {
+		return nil, err
+	}
+
+	audits := make([]*model.UserOptionAudit, 0)
+	audit := func(field string, previous string, current string) {
+		if previous == current {
+			return
+		}
+
+		audits = append(
+			audits, &model.UserOptionAudit{
+				IdentityID: userIdentityId,
+				ChangedBy:  authClaims.Subject,
+				Field:      field,
+				Previous:   previous,
+				Current:    current,
+			},
+		)
+	}
+	updateLimit := func(field string, limit *int, value *int) {
+		if value == nil {
+			return
+		}
+
+		audit(field, strconv.Itoa(*limit), strconv.Itoa(*value))
+		*limit = *value
+	}
+
+	if userOptionUpdateRequest.Package != nil {
+		audit("package", option.Package.String(), userOptionUpdateRequest.Package.String())
+		option.Package = *userOptionUpdateRequest.Package
+	}
+
+	updateLimit("maxCircles", &option.MaxCircles, userOptionUpdateRequest.MaxCircles)
+	updateLimit("maxVoters", &option.MaxVoters, userOptionUpdateRequest.MaxVoters)
+	updateLimit("maxCandidates", &option.MaxCandidates, userOptionUpdateRequest.MaxCandidates)
+	updateLimit("maxPrivateVoters", &option.MaxPrivateVoters, userOptionUpdateRequest.MaxPrivateVoters)
+	updateLimit("maxPrivateCandidates", &option.MaxPrivateCandidates, userOptionUpdateRequest.MaxPrivateCandidates)
+	updateLimit("maxImageUploads", &option.MaxImageUploads, userOptionUpdateRequest.MaxImageUploads)
+	updateLimit("maxWebhooks", &option.MaxWebhooks, userOptionUpdateRequest.MaxWebhooks)
+
+	if len(audits) > 0 {
+		option, err = c.storage.SaveUserOption(option, audits)
+
+		if err != nil {
+			return nil, err
+		}
+
+		c.log.Infof("user option of user %s changed by %s in %d fields", userIdentityId, authClaims.Subject, len(audits))
+	}
+
+	return c.adminUserOptionResponse(option)
+}
+
+// UserOptionAudits of the option of the user with the latest changes first
+func (c *adminService) UserOptionAudits(
+	ctx context.Context,
+	userIdentityId string,
+) ([]*model.UserOptionAudit, error) {
+	if _, err := routerContext.ContextToAuthClaims(ctx); err != nil {
+		c.log.Errorf("error getting auth claims: %s", err)
+		return nil, err
+	}
+
+	audits, err := c.storage.UserOptionAuditsByUserIdentityId(userIdentityId, userOptionAuditsLimit)
+
+	if err != nil && !database.RecordNotFound(err) {
+		return nil, err
+	}
+
+	if audits == nil {
+		audits = make([]*model.UserOptionAudit, 0)
+	}
+
+	return audits, nil
+}
+
+// storedUserOption of the user or a new option with the smallest
+// package and without overrides, if the user has none yet
+func (c *adminService) storedUserOption(userIdentityId string) (*model.UserOption, error) {
+	option, err := c.storage.UserOptionByUserIdentityId(userIdentityId)
+
+	if err != nil && !database.RecordNotFound(err) {
+		return nil, err
+	}
+
+	if database.RecordNotFound(err) {
+		return &model.UserOption{
+			IdentityID: userIdentityId,
+			Package:    model.SubscriptionPackageS,
+		}, nil
+	}
+
+	return option, nil
+}
+
+func (c *adminService) adminUserOptionResponse(option *model.UserOption) (*model.AdminUserOptionResponse, error) {
+	userOption, err := c.userOptionService.UserOptionOfUser(option.IdentityID)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &model.AdminUserOptionResponse{
+		Overrides: option,
+		Option:    userOption,
+	}, nil
+}
@@ -0,0 +1,125 @@
+package api
+
+import (
+	"context"
+	awsx "github.com/VerzCar/vyf-lib-awsx"
+	logger "github.com/VerzCar/vyf-lib-logger"
+	"github.com/VerzCar/vyf-vote-circle/api/model"
+	"github.com/VerzCar/vyf-vote-circle/app/config"
+	"github.com/VerzCar/vyf-vote-circle/utils"
+	"gorm.io/gorm"
+	"testing"
+)
+
+func TestAdminService_UpdateUserOption(t *testing.T) {
+	conf := &config.Config{}
+	conf.Plans.S = config.Plan{MaxCircles: 1, MaxVoters: 10, MaxCandidates: 5}
+	conf.Plans.M = config.Plan{MaxCircles: 5, MaxVoters: 100, MaxCandidates: 20}
+	log := logger.NewLogger(utils.FromBase("app/config/"))
+	ctx := context.WithValue(context.Background(), "AuthClaimsContextKey", &awsx.JWTToken{Subject: "admin"})
+	packageM := model.SubscriptionPackageM
+
+	tests := []struct {
+		name              string
+		option            *model.UserOption
+		expectedCircles   int
+		expectedVoters    int
+		expectedAudits    int
+		expectedPackage   model.SubscriptionPackage
+		expectedOverrides int
+	}{
+		{
+			name:            "Test UpdateUserOption changes the quotas with the package",
+			option:          &model.UserOption{IdentityID: "user", Package: model.SubscriptionPackageS},
+			expectedCircles: 5,
+			expectedVoters:  100,
+			expectedAudits:  1,
+			expectedPackage: model.SubscriptionPackageM,
+		},
+		{
+			name:            "Test UpdateUserOption changes the quotas with the package of a new option",
+			expectedCircles: 5,
+			expectedVoters:  100,
+			expectedAudits:  1,
+			expectedPackage: model.SubscriptionPackageM,
+		},
+		{
+			name:              "Test UpdateUserOption keeps the overridden limits with the package",
+			option:            &model.UserOption{IdentityID: "user", Package: model.SubscriptionPackageS, MaxVoters: 50},
+			expectedCircles:   5,
+			expectedVoters:    50,
+			expectedAudits:    1,
+			expectedPackage:   model.SubscriptionPackageM,
+			expectedOverrides: 50,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(
+			tt.name, func(t *testing.T) {
+				repo := &mockAdminRepository{option: tt.option}
+				service := NewAdminService(repo, NewUserOptionService(repo, conf, log), conf, log)
+
+				result, err := service.UpdateUserOption(ctx, "user", &model.UserOptionUpdateRequest{Package: &packageM})
+
+				if err != nil {
+					t.Fatalf("Expected no error, but got: %v", err)
+				}
+				if result.Option.Package != tt.expectedPackage {
+					t.Errorf("Expected package: %v, but got: %v", tt.expectedPackage, result.Option.Package)
+				}
+				if result.Option.MaxCircles != tt.expectedCircles {
+					t.Errorf("Expected max circles: %v, but got: %v", tt.expectedCircles, result.Option.MaxCircles)
+				}
+				if result.Option.MaxVoters != tt.expectedVoters {
+					t.Errorf("Expected max voters: %v, but got: %v", tt.expectedVoters, result.Option.MaxVoters)
+				}
+				if result.Overrides.MaxVoters != tt.expectedOverrides {
+					t.Errorf("Expected overridden voters: %v, but got: %v", tt.expectedOverrides, result.Overrides.MaxVoters)
+				}
+				if len(repo.audits) != tt.expectedAudits {
+					t.Errorf("Expected audits: %v, but got: %v", tt.expectedAudits, len(repo.audits))
+				}
+			},
+		)
+	}
+}
+
+// mockAdminRepository stores the option of a single user in memory
+type mockAdminRepository struct {
+	option *model.UserOption
+	audits []*model.UserOptionAudit
+}
+
+func (m *mockAdminRepository) UserOptionByUserIdentityId(userIdentityId string) (*model.UserOption, error) {
+	if m.option == nil || m.option.IdentityID != userIdentityId {
+		return nil, gorm.ErrRecordNotFound
+	}
+
+	option := *m.option
+	return &option, nil
+}
+
+func (m *mockAdminRepository) SaveUserOption(
+	option *model.UserOption,
+	audits []*model.UserOptionAudit,
+) (*model.UserOption, error) {
+	saved := *option
+	m.option = &saved
+	m.audits = append(m.audits, audits...)
+	return option, nil
+}
+
+func (m *mockAdminRepository) UserOptionAuditsByUserIdentityId(
+	userIdentityId string,
+	limit int,
+) ([]*model.UserOptionAudit, error) {
+	return m.audits, nil
+}
+
+func (m *mockAdminRepository) CircleUsagesOfUser(
+	userIdentityId string,
+	imageSrcPattern string,
+) ([]*model.CircleUsage, error) {
+	return nil, nil
+}
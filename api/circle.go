@@ -51,6 +51,10 @@ type CircleService interface {
 	AddToGlobalCircle(
 		ctx context.Context,
 	) error
+	CircleRounds(
+		ctx context.Context,
+		circleId int64,
+	) ([]*model.CircleRoundResponse, error)
 }
 
 type CircleRepository interface {
@@ -59,9 +63,15 @@ type CircleRepository interface {
 	Circles(userIdentityId string) ([]*model.Circle, error)
 	CirclesFiltered(name string) ([]*model.CirclePaginated, error)
 	CirclesOfInterest(userIdentityId string) ([]*model.CirclePaginated, error)
-	UpdateCircle(circle *model.Circle) (*model.Circle, error)
+	UpdateCircle(
+		circle *model.Circle,
+		outboxEvents model.OutboxEventsCallback,
+	) (*model.Circle, error)
 	CreateNewCircle(circle *model.Circle) (*model.Circle, error)
-	CreateNewCircleVoter(voter *model.CircleVoter) (*model.CircleVoter, error)
+	CreateNewCircleVoter(
+		voter *model.CircleVoter,
+		outboxEvents model.OutboxEventsCallback,
+	) (*model.CircleVoter, error)
 	IsVoterInCircle(userIdentityId string, circleId int64) (bool, error)
 	IsCandidateInCircle(
 		userIdentityId string,
@@ -74,11 +84,12 @@ type CircleRepository interface {
 	ExistVoteByCircleId(
 		circleId int64,
 	) (bool, error)
+	CircleRounds(circleId int64) ([]*model.CircleRound, error)
 }
 
 type CircleUserOptionService interface {
-	UserOption(
-		ctx context.Context,
+	UserOptionOfUser(
+		userIdentityId string,
 	) (*model.UserOptionResponse, error)
 }
 
@@ -135,6 +146,68 @@ func (c *circleService) Circle(
 	return circle, nil
 }
 
+// CircleRounds of the multi round circle, the given circle is any round of.
+// The first round is the circle itself, the further rounds contain their
+// circle and result once they have been created.
+func (c *circleService) CircleRounds(
+	ctx context.Context,
+	circleId int64,
+) ([]*model.CircleRoundResponse, error) {
+	circle, err := c.Circle(ctx, circleId)
+
+	if err != nil {
+		return nil, err
+	}
+
+	firstRound := circle
+
+	if circle.FirstRoundID != nil {
+		firstRound, err = c.storage.CircleById(*circle.FirstRoundID)
+
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	rounds, err := c.storage.CircleRounds(firstRound.ID)
+
+	if err != nil {
+		return nil, err
+	}
+
+	responses := make([]*model.CircleRoundResponse, 0, len(rounds)+1)
+	responses = append(
+		responses, &model.CircleRoundResponse{
+			ValidFrom:  firstRound.ValidFrom,
+			ValidUntil: firstRound.ValidUntil,
+			Stage:      &firstRound.Stage,
+			Result:     firstRound.Result,
+			CircleID:   &firstRound.ID,
+			Number:     1,
+		},
+	)
+
+	for _, round := range rounds {
+		validUntil := round.ValidUntil
+		response := &model.CircleRoundResponse{
+			ValidFrom:  round.ValidFrom,
+			ValidUntil: &validUntil,
+			CircleID:   round.RoundCircleID,
+			Number:     round.Number,
+			Candidates: round.Candidates,
+		}
+
+		if round.RoundCircle != nil {
+			response.Stage = &round.RoundCircle.Stage
+			response.Result = round.RoundCircle.Result
+		}
+
+		responses = append(responses, response)
+	}
+
+	return responses, nil
+}
+
 // Circles will determine all the circles the authenticated
 // user has and returns the circles as a list.
 // If the user hasn't any circles the return value will be empty.
@@ -315,6 +388,25 @@ func (c *circleService) UpdateCircle(
 		circle.ValidUntil = nil
 	}
 
+	// check if new commitment deadline is given and is before the circle closes
+	// otherwise check if current commitment deadline is still before the circle closes
+	if circleUpdateRequest.CommitmentDeadline != nil {
+		commitmentDeadline, err := extractCommitmentDeadline(
+			currentTime,
+			circle.ValidUntil,
+			*circleUpdateRequest.CommitmentDeadline,
+		)
+
+		if err != nil {
+			return nil, err
+		}
+
+		circle.CommitmentDeadline = commitmentDeadline
+	} else if circle.CommitmentDeadline != nil && circle.ValidUntil != nil &&
+		circle.CommitmentDeadline.After(*circle.ValidUntil) {
+		return nil, fmt.Errorf("commitment deadline must not be after valid until time")
+	}
+
 	if circleUpdateRequest.Name != nil {
 		circle.Name = strings.TrimSpace(*circleUpdateRequest.Name)
 	}
@@ -327,7 +419,7 @@ func (c *circleService) UpdateCircle(
 		circle.Description = strings.TrimSpace(*circleUpdateRequest.Description)
 	}
 
-	circle, err = c.storage.UpdateCircle(circle)
+	circle, err = c.storage.UpdateCircle(circle, CreateCircleOutboxEvents(model.EventOperationUpdated, circle))
 
 	if err != nil {
 		return nil, fmt.Errorf("error updating circle: %s", err)
@@ -353,7 +445,11 @@ func (c *circleService) CreateCircle(
 		return nil, err
 	}
 
-	userOption, _ := c.userOptionService.UserOption(ctx)
+	userOption, err := c.userOptionService.UserOptionOfUser(authClaims.Subject)
+
+	if err != nil {
+		return nil, err
+	}
 
 	if circlesCount >= int64(userOption.MaxCircles) {
 		err = fmt.Errorf("user has more than %d allowed circles", userOption.MaxCircles)
@@ -369,6 +465,26 @@ func (c *circleService) CreateCircle(
 		newCircle.Private = *circleCreateRequest.Private
 	}
 
+	newCircle.VotingMode = model.VotingModeSingle
+
+	if circleCreateRequest.VotingMode != nil {
+		newCircle.VotingMode = *circleCreateRequest.VotingMode
+	}
+
+	newCircle.RankingOrder = model.RankingOrderTotal
+
+	if circleCreateRequest.RankingOrder != nil {
+		newCircle.RankingOrder = *circleCreateRequest.RankingOrder
+	}
+
+	if circleCreateRequest.QuorumTurnout != nil {
+		newCircle.QuorumTurnout = *circleCreateRequest.QuorumTurnout
+	}
+
+	if circleCreateRequest.QuorumWinnerVotes != nil {
+		newCircle.QuorumWinnerVotes = *circleCreateRequest.QuorumWinnerVotes
+	}
+
 	if newCircle.Private && len(circleCreateRequest.Voters) <= 0 {
 		err = fmt.Errorf("circle must contain at least one voter if private")
 		return nil, err
@@ -417,6 +533,40 @@ func (c *circleService) CreateCircle(
 		newCircle.Candidates = circleCandidates
 	}
 
+	if circleCreateRequest.Delegation != nil && *circleCreateRequest.Delegation {
+		if !newCircle.Private {
+			err = fmt.Errorf("circle must be private to allow delegation")
+			return nil, err
+		}
+
+		newCircle.Delegation = true
+	}
+
+	if circleCreateRequest.Nomination != nil && *circleCreateRequest.Nomination {
+		newCircle.Nomination = true
+	}
+
+	newCircle.NominationsPerVoter = 1
+
+	if circleCreateRequest.NominationsPerVoter != nil {
+		newCircle.NominationsPerVoter = *circleCreateRequest.NominationsPerVoter
+	}
+
+	if circleCreateRequest.NominationSeconds != nil {
+		newCircle.NominationSeconds = *circleCreateRequest.NominationSeconds
+	}
+
+	if circleCreateRequest.NoneOfTheAbove != nil && *circleCreateRequest.NoneOfTheAbove {
+		newCircle.NoneOfTheAbove = true
+		// the pseudo candidate is committed, as no user can commit for it
+		newCircle.Candidates = append(
+			newCircle.Candidates, &model.CircleCandidate{
+				Candidate:  model.NoneOfTheAboveCandidate,
+				Commitment: model.CommitmentCommitted,
+			},
+		)
+	}
+
 	if circleCreateRequest.Description != nil {
 		newCircle.Description = strings.TrimSpace(*circleCreateRequest.Description)
 	}
@@ -447,6 +597,33 @@ func (c *circleService) CreateCircle(
 		newCircle.ValidUntil = validUntil
 	}
 
+	// check if the commitment deadline is given and is before the circle closes
+	if circleCreateRequest.CommitmentDeadline != nil {
+		commitmentDeadline, err := extractCommitmentDeadline(
+			currentTime,
+			newCircle.ValidUntil,
+			*circleCreateRequest.CommitmentDeadline,
+		)
+
+		if err != nil {
+			return nil, err
+		}
+
+		newCircle.CommitmentDeadline = commitmentDeadline
+	}
+
+	newCircle.Round = 1
+
+	if len(circleCreateRequest.Rounds) > 0 {
+		rounds, err := createCircleRoundList(newCircle, circleCreateRequest.Rounds)
+
+		if err != nil {
+			return nil, err
+		}
+
+		newCircle.Rounds = rounds
+	}
+
 	circle, err := c.storage.CreateNewCircle(newCircle)
 
 	if err != nil {
@@ -567,7 +744,7 @@ func (c *circleService) AddToGlobalCircle(
 		CircleRefer: &circle.ID,
 		Commitment:  model.CommitmentCommitted,
 	}
-	_, err = c.storage.CreateNewCircleVoter(circleVoter)
+	_, err = c.storage.CreateNewCircleVoter(circleVoter, nil)
 
 	if err != nil {
 		c.log.Errorf("error adding voter to global circle: %s", err)
@@ -610,7 +787,7 @@ func (c *circleService) inactivateCircle(
 	circle *model.Circle,
 ) error {
 	circle.Active = false
-	circle, err := c.storage.UpdateCircle(circle)
+	circle, err := c.storage.UpdateCircle(circle, CreateCircleOutboxEvents(model.EventOperationDeleted, circle))
 
 	if err != nil {
 		return err
@@ -662,6 +839,11 @@ func (c *circleService) createCircleCandidateList(
 	var circleCandidates []*model.CircleCandidate
 	// add the given voters to the circle voters
 	for _, candidate := range candidateIdList {
+		// the none of the above candidate is only added by the option of the circle
+		if candidate == model.NoneOfTheAboveCandidate {
+			continue
+		}
+
 		circleCandidate := &model.CircleCandidate{
 			Candidate: candidate,
 		}
@@ -671,6 +853,47 @@ func (c *circleService) createCircleCandidateList(
 	return circleCandidates
 }
 
+// createCircleRoundList of the further rounds of the circle, the circle itself
+// is the first round. Each round must not start before the previous round closed.
+func createCircleRoundList(
+	circle *model.Circle,
+	roundRequests []*model.CircleRoundRequest,
+) ([]*model.CircleRound, error) {
+	if circle.ValidUntil == nil {
+		return nil, fmt.Errorf("circle must have a valid until time to add rounds")
+	}
+
+	previousValidUntil := *circle.ValidUntil
+	rounds := make([]*model.CircleRound, 0, len(roundRequests))
+
+	for index, roundRequest := range roundRequests {
+		number := index + 2
+		validFrom := roundRequest.ValidFrom.UTC().Truncate(60 * time.Second)
+		validUntil := roundRequest.ValidUntil.UTC().Truncate(60 * time.Second)
+
+		if validFrom.Before(previousValidUntil) {
+			return nil, fmt.Errorf("round %d must not start before the previous round closed", number)
+		}
+
+		if err := isValidUntilAfterValidFrom(validFrom, validUntil); err != nil {
+			return nil, err
+		}
+
+		rounds = append(
+			rounds, &model.CircleRound{
+				ValidFrom:  validFrom,
+				ValidUntil: validUntil,
+				Number:     number,
+				Candidates: roundRequest.Candidates,
+			},
+		)
+
+		previousValidUntil = validUntil
+	}
+
+	return rounds, nil
+}
+
 // Gets the current time truncated without seconds and 10 minutes
 // past the current time.
 func currentTruncatedTime() time.Time {
@@ -724,3 +947,21 @@ func extractValidUntil(
 
 	return &validUntilTime, nil
 }
+
+// Function to handle commitment deadline time validation
+func extractCommitmentDeadline(
+	currentTime time.Time,
+	validUntil *time.Time,
+	commitmentDeadline time.Time,
+) (*time.Time, error) {
+	commitmentDeadlineTime := commitmentDeadline.UTC().Truncate(60 * time.Second)
+	if err := isTimeInFuture(currentTime, commitmentDeadlineTime); err != nil {
+		return nil, err
+	}
+
+	if validUntil != nil && commitmentDeadlineTime.After(*validUntil) {
+		return nil, fmt.Errorf("commitment deadline must not be after valid until time")
+	}
+
+	return &commitmentDeadlineTime, nil
+}
@@ -0,0 +1,85 @@
+package api
+
+import (
+	"context"
+	"fmt"
+	logger "github.com/VerzCar/vyf-lib-logger"
+	"github.com/VerzCar/vyf-vote-circle/api/model"
+	"github.com/VerzCar/vyf-vote-circle/app/pubsub"
+)
+
+type CircleSubscriptionService interface {
+	CircleChangedEvent(
+		ctx context.Context,
+		circleId int64,
+		event *model.CircleChangedEvent,
+	) error
+}
+
+type circleSubscriptionService struct {
+	pubSubService pubsub.Client
+	eventLog      EventLogService
+	log           logger.Logger
+}
+
+func NewCircleSubscriptionService(
+	pubSubService pubsub.Client,
+	eventLog EventLogService,
+	log logger.Logger,
+) CircleSubscriptionService {
+	return &circleSubscriptionService{
+		pubSubService: pubSubService,
+		eventLog:      eventLog,
+		log:           log,
+	}
+}
+
+// CircleChangedEvent notifies all clients of the circle about the
+// changed metadata, the deactivation or the stage change of the circle.
+func (s *circleSubscriptionService) CircleChangedEvent(
+	ctx context.Context,
+	circleId int64,
+	event *model.CircleChangedEvent,
+) error {
+	channelName := circleChannelName(circleId)
+	msgName := model.EventNameCircleChanged
+
+	// the event is published even if it cannot be logged,
+	// clients will then detect the gap in the sequence
+	_ = s.eventLog.Append(ctx, circleId, model.EventKindCircle, event)
+
+	err := s.pubSubService.Publish(ctx, channelName, &pubsub.Message{Name: msgName, Data: event})
+
+	if err != nil {
+		s.log.Errorf(
+			"could not publish message to channel: %s with message name: %s cause: %s",
+			channelName,
+			msgName,
+			err,
+		)
+		return err
+	}
+
+	return nil
+}
+
+// CreateCircleOutboxEvents creates the callback for the outbox event
+// of the changed circle, that is written in the same transaction as the change.
+func CreateCircleOutboxEvents(
+	operation model.EventOperation,
+	circle *model.Circle,
+) model.OutboxEventsCallback {
+	return func() ([]*model.OutboxEvent, error) {
+		event, err := model.NewOutboxEvent(circle.ID, model.EventKindCircle, model.NewCircleChangedEvent(operation, circle))
+
+		if err != nil {
+			return nil, err
+		}
+
+		return []*model.OutboxEvent{event}, nil
+	}
+}
+
+func circleChannelName(circleId int64) string {
+	return fmt.Sprintf("circle-%d:circle", circleId)
+}
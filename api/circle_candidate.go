@@ -44,6 +44,15 @@ type CircleCandidateService interface {
 		circleId int64,
 		circleCandidateInput *model.CircleCandidateRequest,
 	) ([]*string, error)
+	CircleCandidateNominations(
+		ctx context.Context,
+		circleId int64,
+	) ([]*model.CircleNominationResponse, error)
+	CircleCandidateNominate(
+		ctx context.Context,
+		circleId int64,
+		circleCandidateInput *model.CircleCandidateRequest,
+	) (*model.CircleNominationResponse, error)
 }
 
 type CircleCandidateRepository interface {
@@ -51,13 +60,22 @@ type CircleCandidateRepository interface {
 		circleId int64,
 		filterBy *model.CircleCandidatesFilterBy,
 	) ([]*model.CircleCandidate, error)
-	CreateNewCircleCandidate(voter *model.CircleCandidate) (*model.CircleCandidate, error)
+	CreateNewCircleCandidate(
+		candidate *model.CircleCandidate,
+		outboxEvents model.OutboxEventsCallback,
+	) (*model.CircleCandidate, error)
 	CircleCandidateByCircleId(circleId int64, userIdentityId string) (*model.CircleCandidate, error)
 	CircleCandidateCountByCircleId(
 		circleId int64,
 	) (int64, error)
-	UpdateCircleCandidate(voter *model.CircleCandidate) (*model.CircleCandidate, error)
-	DeleteCircleCandidate(candidateId int64) error
+	UpdateCircleCandidate(
+		candidate *model.CircleCandidate,
+		outboxEvents model.OutboxEventsCallback,
+	) (*model.CircleCandidate, error)
+	DeleteCircleCandidate(
+		candidateId int64,
+		outboxEvents model.OutboxEventsCallback,
+	) error
 	IsVoterInCircle(userIdentityId string, circleId int64) (bool, error)
 	IsCandidateInCircle(userIdentityId string, circleId int64) (bool, error)
 	CircleById(id int64) (*model.Circle, error)
@@ -65,25 +83,32 @@ type CircleCandidateRepository interface {
 		circleId int64,
 		candidateId int64,
 	) ([]*model.Vote, error)
-}
-
-type CircleCandidateSubscription interface {
-	CircleCandidateChangedEvent(
-		ctx context.Context,
+	CircleNominations(circleId int64) ([]*model.CircleNomination, error)
+	CircleNominationsOfNominee(
 		circleId int64,
-		event *model.CircleCandidateChangedEvent,
-	) error
+		nominee string,
+	) ([]*model.CircleNomination, error)
+	CountCircleNominationsOfVoter(
+		circleId int64,
+		voter string,
+	) (int64, error)
+	CreateNewCircleNomination(
+		nomination *model.CircleNomination,
+		maxNominationsOfVoter int,
+		secondsRequired int,
+		candidate *model.CircleCandidate,
+		outboxEvents model.OutboxEventsCallback,
+	) ([]*model.CircleNomination, error)
 }
 
 type CircleCandidateOptionService interface {
-	UserOption(
-		ctx context.Context,
+	UserOptionOfUser(
+		userIdentityId string,
 	) (*model.UserOptionResponse, error)
 }
 
 type circleCandidateService struct {
 	storage           CircleCandidateRepository
-	subscription      CircleCandidateSubscription
 	userOptionService CircleCandidateOptionService
 	config            *config.Config
 	log               logger.Logger
@@ -91,14 +116,12 @@ type circleCandidateService struct {
 
 func NewCircleCandidateService(
 	circleCandidateRepo CircleCandidateRepository,
-	subscription CircleCandidateSubscription,
 	userOptionService CircleCandidateOptionService,
 	config *config.Config,
 	log logger.Logger,
 ) CircleCandidateService {
 	return &circleCandidateService{
 		storage:           circleCandidateRepo,
-		subscription:      subscription,
 		userOptionService: userOptionService,
 		config:            config,
 		log:               log,
@@ -169,6 +192,10 @@ func (c *circleCandidateService) CircleCandidateCommitment(
 		return nil, fmt.Errorf("circle is not editable")
 	}
 
+	if circle.IsCommitmentDeadlinePassed() {
+		return nil, fmt.Errorf("commitment deadline of circle has passed")
+	}
+
 	candidate, err := c.storage.CircleCandidateByCircleId(circleId, authClaims.Subject)
 
 	if err != nil {
@@ -177,15 +204,15 @@ func (c *circleCandidateService) CircleCandidateCommitment(
 	}
 
 	candidate.Commitment = commitment
-	_, err = c.storage.UpdateCircleCandidate(candidate)
+	_, err = c.storage.UpdateCircleCandidate(
+		candidate,
+		CreateCandidateOutboxEvents(circleId, model.EventOperationUpdated, candidate),
+	)
 
 	if err != nil {
 		return nil, err
 	}
 
-	candidateEvent := CreateCandidateChangedEvent(model.EventOperationUpdated, candidate)
-	_ = c.subscription.CircleCandidateChangedEvent(ctx, circleId, candidateEvent)
-
 	return &candidate.Commitment, nil
 }
 
@@ -226,10 +253,16 @@ func (c *circleCandidateService) CircleCandidateJoinCircle(
 		return nil, err
 	}
 
-	userOption, _ := c.userOptionService.UserOption(ctx)
+	userOption, err := c.userOptionService.UserOptionOfUser(circle.CreatedFrom)
 
-	if candidatesCount >= int64(userOption.MaxCandidates) {
-		err = fmt.Errorf("circle has more than %d allowed candidates", userOption.MaxCandidates)
+	if err != nil {
+		return nil, err
+	}
+
+	maxCandidates := userOption.CircleMaxCandidates(circle.Private)
+
+	if candidatesCount >= int64(maxCandidates) {
+		err = fmt.Errorf("circle has more than %d allowed candidates", maxCandidates)
 		return nil, err
 	}
 
@@ -251,16 +284,16 @@ func (c *circleCandidateService) CircleCandidateJoinCircle(
 		Commitment:  model.CommitmentCommitted,
 	}
 
-	candidate, err := c.storage.CreateNewCircleCandidate(circleCandidate)
+	candidate, err := c.storage.CreateNewCircleCandidate(
+		circleCandidate,
+		CreateCandidateOutboxEvents(circleId, model.EventOperationCreated, circleCandidate),
+	)
 
 	if err != nil {
 		c.log.Errorf("error adding candidate to circle id %d: %s", circleId, err)
 		return nil, err
 	}
 
-	candidateEvent := CreateCandidateChangedEvent(model.EventOperationCreated, candidate)
-	_ = c.subscription.CircleCandidateChangedEvent(ctx, circleId, candidateEvent)
-
 	return candidate, nil
 }
 
@@ -306,7 +339,10 @@ func (c *circleCandidateService) CircleCandidateLeaveCircle(
 		return fmt.Errorf("candidate contain votes")
 	}
 
-	err = c.storage.DeleteCircleCandidate(candidate.ID)
+	err = c.storage.DeleteCircleCandidate(
+		candidate.ID,
+		CreateCandidateOutboxEvents(circleId, model.EventOperationDeleted, candidate),
+	)
 
 	if err != nil {
 		c.log.Errorf(
@@ -318,9 +354,6 @@ func (c *circleCandidateService) CircleCandidateLeaveCircle(
 		return fmt.Errorf("leaving as candidate from cirlce failed")
 	}
 
-	candidateEvent := CreateCandidateChangedEvent(model.EventOperationDeleted, candidate)
-	_ = c.subscription.CircleCandidateChangedEvent(ctx, circleId, candidateEvent)
-
 	return nil
 }
 
@@ -373,9 +406,6 @@ func (c *circleCandidateService) CircleCandidatesAddToCircle(
 		}
 
 		updatedCandidates = append(updatedCandidates, newCandidate)
-
-		candidateEvent := CreateCandidateChangedEvent(model.EventOperationCreated, newCandidate)
-		_ = c.subscription.CircleCandidateChangedEvent(ctx, circleId, candidateEvent)
 	}
 
 	return updatedCandidates, nil
@@ -419,6 +449,10 @@ func (c *circleCandidateService) CircleCandidateRemoveFromCircle(
 		return fmt.Errorf("circle is not editable")
 	}
 
+	if circleCandidateInput.Candidate == model.NoneOfTheAboveCandidate {
+		return fmt.Errorf("none of the above cannot be removed as candidate")
+	}
+
 	candidate, err := c.storage.CircleCandidateByCircleId(circleId, circleCandidateInput.Candidate)
 
 	if err != nil && !database.RecordNotFound(err) {
@@ -445,7 +479,10 @@ func (c *circleCandidateService) CircleCandidateRemoveFromCircle(
 		return fmt.Errorf("candidate contain votes")
 	}
 
-	err = c.storage.DeleteCircleCandidate(candidate.ID)
+	err = c.storage.DeleteCircleCandidate(
+		candidate.ID,
+		CreateCandidateOutboxEvents(circleId, model.EventOperationDeleted, candidate),
+	)
 
 	if err != nil {
 		c.log.Errorf(
@@ -457,9 +494,6 @@ func (c *circleCandidateService) CircleCandidateRemoveFromCircle(
 		return fmt.Errorf("removing candidate from cirlce failed")
 	}
 
-	candidateEvent := CreateCandidateChangedEvent(model.EventOperationDeleted, candidate)
-	_ = c.subscription.CircleCandidateChangedEvent(ctx, circleId, candidateEvent)
-
 	return nil
 }
 
@@ -523,6 +557,183 @@ func (c *circleCandidateService) CircleCandidateVotedBy(
 	return userIds, nil
 }
 
+// CircleCandidateNominations of the circle, merged by the nominees in the
+// order they have been nominated.
+func (c *circleCandidateService) CircleCandidateNominations(
+	ctx context.Context,
+	circleId int64,
+) ([]*model.CircleNominationResponse, error) {
+	authClaims, err := routerContext.ContextToAuthClaims(ctx)
+
+	if err != nil {
+		c.log.Errorf("error getting auth claims: %s", err)
+		return nil, err
+	}
+
+	circle, err := c.storage.CircleById(circleId)
+
+	if err != nil {
+		return nil, err
+	}
+
+	eligibleToBeInCircle, err := c.eligibleToBeInCircle(authClaims.Subject, circle)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if !eligibleToBeInCircle {
+		c.log.Infof(
+			"user is not eligible to interact with circle: user %s, circle ID %d",
+			authClaims.Subject,
+			circle.ID,
+		)
+		err = fmt.Errorf("user is not eligible to interact with circle")
+		return nil, err
+	}
+
+	nominations, err := c.storage.CircleNominations(circle.ID)
+
+	if err != nil && !database.RecordNotFound(err) {
+		return nil, err
+	}
+
+	return model.NewCircleNominationResponses(nominations, circle.NominationSeconds), nil
+}
+
+// CircleCandidateNominate nominates the given candidate in the circle for
+// the authenticated voter. If the candidate is already nominated, the
+// nomination is seconded instead. Once the nomination has the required
+// seconds, the nominee is added as candidate with an open commitment.
+func (c *circleCandidateService) CircleCandidateNominate(
+	ctx context.Context,
+	circleId int64,
+	circleCandidateInput *model.CircleCandidateRequest,
+) (*model.CircleNominationResponse, error) {
+	authClaims, err := routerContext.ContextToAuthClaims(ctx)
+
+	if err != nil {
+		c.log.Errorf("error getting auth claims: %s", err)
+		return nil, err
+	}
+
+	circle, err := c.storage.CircleById(circleId)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if !circle.IsEditable() {
+		err = fmt.Errorf("circle is not editable")
+		return nil, err
+	}
+
+	if !circle.Nomination {
+		err = fmt.Errorf("circle does not allow nominations")
+		return nil, err
+	}
+
+	if circle.Stage != model.CircleStageCold {
+		err = fmt.Errorf("nomination phase of circle is over")
+		return nil, err
+	}
+
+	isVoterInCircle, err := c.storage.IsVoterInCircle(authClaims.Subject, circle.ID)
+
+	if err != nil && !database.RecordNotFound(err) {
+		return nil, err
+	}
+
+	if !isVoterInCircle {
+		err = fmt.Errorf("only voters of the circle can nominate candidates")
+		return nil, err
+	}
+
+	nominee := circleCandidateInput.Candidate
+
+	if nominee == model.NoneOfTheAboveCandidate {
+		err = fmt.Errorf("none of the above cannot be nominated")
+		return nil, err
+	}
+
+	if nominee == authClaims.Subject {
+		err = fmt.Errorf("voter cannot nominate themselves")
+		return nil, err
+	}
+
+	isCandidateInCircle, err := c.storage.IsCandidateInCircle(nominee, circle.ID)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if isCandidateInCircle {
+		err = fmt.Errorf("user is already as candidate in the circle")
+		return nil, err
+	}
+
+	nominations, err := c.storage.CircleNominationsOfNominee(circle.ID, nominee)
+
+	if err != nil && !database.RecordNotFound(err) {
+		return nil, err
+	}
+
+	for _, nomination := range nominations {
+		if nomination.Voter == authClaims.Subject {
+			err = fmt.Errorf("voter already nominated or seconded the nominee")
+			return nil, err
+		}
+	}
+
+	seconded := len(nominations) > 0
+
+	if !seconded {
+		count, err := c.storage.CountCircleNominationsOfVoter(circle.ID, authClaims.Subject)
+
+		if err != nil {
+			return nil, err
+		}
+
+		if count >= int64(circle.NominationsPerVoter) {
+			err = fmt.Errorf("voter can nominate at most %d candidates", circle.NominationsPerVoter)
+			return nil, err
+		}
+	}
+
+	// the nomination is checked again in the transaction of the repository,
+	// the nominations read before may be outdated by concurrent nominations
+	if len(nominations)+1 >= circle.NominationSeconds+1 {
+		if err := c.checkCandidatesQuota(circle); err != nil {
+			return nil, err
+		}
+	}
+
+	circleCandidate := &model.CircleCandidate{
+		Candidate:   nominee,
+		Circle:      circle,
+		CircleRefer: &circle.ID,
+	}
+
+	nominations, err = c.storage.CreateNewCircleNomination(
+		&model.CircleNomination{
+			Nominee:  nominee,
+			Voter:    authClaims.Subject,
+			CircleID: circle.ID,
+		},
+		circle.NominationsPerVoter,
+		circle.NominationSeconds,
+		circleCandidate,
+		CreateCandidateOutboxEvents(circle.ID, model.EventOperationCreated, circleCandidate),
+	)
+
+	if err != nil {
+		c.log.Errorf("error nominating nominee %s in circle id %d: %s", nominee, circle.ID, err)
+		return nil, err
+	}
+
+	return model.NewCircleNominationResponses(nominations, circle.NominationSeconds)[0], nil
+}
+
 // determines, when the circle is private, if the user is eligible to
 // interact with the circle
 func (c *circleCandidateService) eligibleToBeInCircle(
@@ -555,6 +766,10 @@ func (c *circleCandidateService) addCandidateToCircle(
 	circle *model.Circle,
 	candidateIdentId string,
 ) (*model.CircleCandidate, error) {
+	if candidateIdentId == model.NoneOfTheAboveCandidate {
+		return nil, fmt.Errorf("none of the above cannot be added as candidate")
+	}
+
 	IsCandidateInCircle, err := c.storage.IsCandidateInCircle(candidateIdentId, circle.ID)
 
 	if err != nil {
@@ -566,16 +781,7 @@ func (c *circleCandidateService) addCandidateToCircle(
 		return nil, err
 	}
 
-	candidatesCount, err := c.storage.CircleCandidateCountByCircleId(circle.ID)
-
-	if err != nil {
-		return nil, fmt.Errorf("count of candidate failure")
-	}
-
-	userOption, _ := c.userOptionService.UserOption(ctx)
-
-	if candidatesCount >= int64(userOption.MaxCandidates) {
-		err = fmt.Errorf("circle has more than %d allowed candidates", userOption.MaxCandidates)
+	if err := c.checkCandidatesQuota(circle); err != nil {
 		return nil, err
 	}
 
@@ -585,7 +791,10 @@ func (c *circleCandidateService) addCandidateToCircle(
 		CircleRefer: &circle.ID,
 	}
 
-	newCandidate, err := c.storage.CreateNewCircleCandidate(circleCandidate)
+	newCandidate, err := c.storage.CreateNewCircleCandidate(
+		circleCandidate,
+		CreateCandidateOutboxEvents(circle.ID, model.EventOperationCreated, circleCandidate),
+	)
 
 	if err != nil {
 		c.log.Errorf("error adding candidate to circle id %d: %s", circle.ID, err)
@@ -594,3 +803,26 @@ func (c *circleCandidateService) addCandidateToCircle(
 
 	return newCandidate, nil
 }
+
+// checkCandidatesQuota of the plan of the circle owner
+func (c *circleCandidateService) checkCandidatesQuota(circle *model.Circle) error {
+	candidatesCount, err := c.storage.CircleCandidateCountByCircleId(circle.ID)
+
+	if err != nil {
+		return fmt.Errorf("count of candidate failure")
+	}
+
+	userOption, err := c.userOptionService.UserOptionOfUser(circle.CreatedFrom)
+
+	if err != nil {
+		return err
+	}
+
+	maxCandidates := userOption.CircleMaxCandidates(circle.Private)
+
+	if candidatesCount >= int64(maxCandidates) {
+		return fmt.Errorf("circle has more than %d allowed candidates", maxCandidates)
+	}
+
+	return nil
+}
@@ -5,7 +5,7 @@ import (
 	"fmt"
 	logger "github.com/VerzCar/vyf-lib-logger"
 	"github.com/VerzCar/vyf-vote-circle/api/model"
-	"github.com/ably/ably-go/ably"
+	"github.com/VerzCar/vyf-vote-circle/app/pubsub"
 )
 
 type CircleCandidateSubscriptionService interface {
@@ -17,16 +17,19 @@ type CircleCandidateSubscriptionService interface {
 }
 
 type circleCandidateSubscriptionService struct {
-	pubSubService *ably.Realtime
+	pubSubService pubsub.Client
+	eventLog      EventLogService
 	log           logger.Logger
 }
 
 func NewCircleCandidateSubscriptionService(
-	pubSubService *ably.Realtime,
+	pubSubService pubsub.Client,
+	eventLog EventLogService,
 	log logger.Logger,
 ) CircleCandidateSubscriptionService {
 	return &circleCandidateSubscriptionService{
 		pubSubService: pubSubService,
+		eventLog:      eventLog,
 		log:           log,
 	}
 }
@@ -36,12 +39,14 @@ func (s *circleCandidateSubscriptionService) CircleCandidateChangedEvent(
 	circleId int64,
 	event *model.CircleCandidateChangedEvent,
 ) error {
-	channelName := fmt.Sprintf("circle-%d:candidate", circleId)
-	msgName := "circle-candidate-changed"
+	channelName := circleCandidateChannelName(circleId)
+	msgName := model.EventNameCircleCandidateChanged
 
-	channel := s.pubSubService.Channels.Get(channelName)
+	// the event is published even if it cannot be logged,
+	// clients will then detect the gap in the sequence
+	_ = s.eventLog.Append(ctx, circleId, model.EventKindCircleCandidate, event)
 
-	err := channel.Publish(ctx, msgName, event)
+	err := s.pubSubService.Publish(ctx, channelName, &pubsub.Message{Name: msgName, Data: event})
 
 	if err != nil {
 		s.log.Errorf(
@@ -71,3 +76,25 @@ func CreateCandidateChangedEvent(
 		},
 	}
 }
+
+// CreateCandidateOutboxEvents creates the callback for the outbox event
+// of the changed candidate, that is written in the same transaction as the change.
+func CreateCandidateOutboxEvents(
+	circleId int64,
+	operation model.EventOperation,
+	candidate *model.CircleCandidate,
+) model.OutboxEventsCallback {
+	return func() ([]*model.OutboxEvent, error) {
+		event, err := model.NewOutboxEvent(circleId, model.EventKindCircleCandidate, CreateCandidateChangedEvent(operation, candidate))
+
+		if err != nil {
+			return nil, err
+		}
+
+		return []*model.OutboxEvent{event}, nil
+	}
+}
+
+func circleCandidateChannelName(circleId int64) string {
+	return fmt.Sprintf("circle-%d:candidate", circleId)
+}
@@ -0,0 +1,220 @@
+package api
+
+import (
+	"context"
+	logger "github.com/VerzCar/vyf-lib-logger"
+	"github.com/VerzCar/vyf-vote-circle/api/model"
+	"github.com/VerzCar/vyf-vote-circle/app/config"
+	"github.com/VerzCar/vyf-vote-circle/app/pubsub"
+	routerContext "github.com/VerzCar/vyf-vote-circle/app/router/ctx"
+	"time"
+)
+
+// defaultPresenceTTL is used if no presence ttl is configured.
+const defaultPresenceTTL = 30 * time.Second
+
+// CirclePresenceService keeps track of the users currently viewing a circle.
+// The clients send a heartbeat while viewing the circle, a viewer without a
+// heartbeat within the configured ttl is not present anymore.
+type CirclePresenceService interface {
+	Heartbeat(
+		ctx context.Context,
+		circleId int64,
+	) (*model.CirclePresenceResponse, error)
+	Leave(
+		ctx context.Context,
+		circleId int64,
+	) error
+	Presence(
+		ctx context.Context,
+		circleId int64,
+	) (*model.CirclePresenceResponse, error)
+}
+
+type CirclePresenceCircleService interface {
+	Circle(
+		ctx context.Context,
+		circleId int64,
+	) (*model.Circle, error)
+}
+
+type CirclePresenceCache interface {
+	TouchCirclePresence(
+		ctx context.Context,
+		circleId int64,
+		userIdentityId string,
+		expiresAt time.Time,
+	) (int64, bool, error)
+	RemoveCirclePresence(
+		ctx context.Context,
+		circleId int64,
+		userIdentityId string,
+	) (int64, bool, error)
+	CirclePresence(
+		ctx context.Context,
+		circleId int64,
+	) ([]string, error)
+}
+
+type CirclePresencePubSub interface {
+	Publish(
+		ctx context.Context,
+		channel string,
+		messages ...*pubsub.Message,
+	) error
+}
+
+type circlePresenceService struct {
+	circleService CirclePresenceCircleService
+	cache         CirclePresenceCache
+	pubSub        CirclePresencePubSub
+	config        *config.Config
+	log           logger.Logger
+}
+
+func NewCirclePresenceService(
+	circleService CirclePresenceCircleService,
+	cache CirclePresenceCache,
+	pubSub CirclePresencePubSub,
+	config *config.Config,
+	log logger.Logger,
+) CirclePresenceService {
+	return &circlePresenceService{
+		circleService: circleService,
+		cache:         cache,
+		pubSub:        pubSub,
+		config:        config,
+		log:           log,
+	}
+}
+
+// Heartbeat of the user viewing the circle, if the user is eligible
+// to see the circle. If the count of the viewers changed, it will be
+// published on the channel of the circle.
+func (c *circlePresenceService) Heartbeat(
+	ctx context.Context,
+	circleId int64,
+) (*model.CirclePresenceResponse, error) {
+	authClaims, err := routerContext.ContextToAuthClaims(ctx)
+
+	if err != nil {
+		c.log.Errorf("error getting auth claims: %s", err)
+		return nil, err
+	}
+
+	_, err = c.circleService.Circle(ctx, circleId)
+
+	if err != nil {
+		return nil, err
+	}
+
+	count, changed, err := c.cache.TouchCirclePresence(ctx, circleId, authClaims.Subject, time.Now().Add(c.ttl()))
+
+	if err != nil {
+		return nil, err
+	}
+
+	if changed {
+		c.publishPresence(ctx, circleId, count)
+	}
+
+	return &model.CirclePresenceResponse{
+		CircleID: circleId,
+		Count:    count,
+	}, nil
+}
+
+// Leave the circle, the user is not viewing the circle anymore
+func (c *circlePresenceService) Leave(
+	ctx context.Context,
+	circleId int64,
+) error {
+	authClaims, err := routerContext.ContextToAuthClaims(ctx)
+
+	if err != nil {
+		c.log.Errorf("error getting auth claims: %s", err)
+		return err
+	}
+
+	count, changed, err := c.cache.RemoveCirclePresence(ctx, circleId, authClaims.Subject)
+
+	if err != nil {
+		return err
+	}
+
+	if changed {
+		c.publishPresence(ctx, circleId, count)
+	}
+
+	return nil
+}
+
+// Presence of the circle with the current viewers, if the user is eligible
+// to see the circle. The identities of the viewers are only returned
+// for public circles.
+func (c *circlePresenceService) Presence(
+	ctx context.Context,
+	circleId int64,
+) (*model.CirclePresenceResponse, error) {
+	circle, err := c.circleService.Circle(ctx, circleId)
+
+	if err != nil {
+		return nil, err
+	}
+
+	viewers, err := c.cache.CirclePresence(ctx, circleId)
+
+	if err != nil {
+		return nil, err
+	}
+
+	response := &model.CirclePresenceResponse{
+		CircleID: circleId,
+		Count:    int64(len(viewers)),
+	}
+
+	if !circle.Private {
+		response.Viewers = viewers
+	}
+
+	return response, nil
+}
+
+// publishPresence of the circle with the count of the viewers.
+// The presence is not essential, therefore an error is only logged.
+func (c *circlePresenceService) publishPresence(
+	ctx context.Context,
+	circleId int64,
+	count int64,
+) {
+	channelName := circleChannelName(circleId)
+	msgName := model.EventNameCirclePresenceChanged
+
+	err := c.pubSub.Publish(
+		ctx,
+		channelName,
+		&pubsub.Message{
+			Name: msgName,
+			Data: &model.CirclePresenceChangedEvent{CircleID: circleId, Count: count},
+		},
+	)
+
+	if err != nil {
+		c.log.Errorf(
+			"could not publish message to channel: %s with message name: %s cause: %s",
+			channelName,
+			msgName,
+			err,
+		)
+	}
+}
+
+func (c *circlePresenceService) ttl() time.Duration {
+	ttl := time.Duration(c.config.Presence.TTL) * time.Second
+
+	if ttl <= 0 {
+		return defaultPresenceTTL
+	}
+
+	return ttl
+}
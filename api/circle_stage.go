@@ -0,0 +1,293 @@
+package api
+
+import (
+	"context"
+	logger "github.com/VerzCar/vyf-lib-logger"
+	"github.com/VerzCar/vyf-vote-circle/api/model"
+	"github.com/VerzCar/vyf-vote-circle/app/config"
+	"github.com/VerzCar/vyf-vote-circle/app/database"
+	"time"
+)
+
+const (
+	// defaultCircleStageInterval is used if no stage interval is configured.
+	defaultCircleStageInterval = 30 * time.Second
+	// circleStageBatchSize of the circles refreshed at once
+	circleStageBatchSize = 100
+)
+
+// CircleStageService refreshes the stages of the circles, that have moved
+// from COLD to HOT or to CLOSED in the meantime. The stage change is published
+// through the outbox, even if no client has requested the circle.
+// The result of a closed circle is evaluated by its quorum afterwards and
+// the next round of a closed multi round circle is created.
+// The open commitments of the circles, whose commitment deadline passed,
+// are rejected.
+type CircleStageService interface {
+	Run(ctx context.Context)
+}
+
+type CircleStageRepository interface {
+	RefreshCircleStages(limit int) ([]*model.Circle, error)
+	ClosedCirclesWithoutResult(limit int) ([]*model.Circle, error)
+	CirclesPastCommitmentDeadline(limit int) ([]*model.Circle, error)
+	RejectOpenCommitments(
+		circleId int64,
+		outboxEvents model.RejectedCommitmentsOutboxEventsCallback,
+	) ([]*model.CircleCandidate, []*model.CircleVoter, error)
+	CircleTurnout(circleId int64) (*model.CircleTurnout, error)
+	RankingsByCircleId(circleId int64) ([]*model.Ranking, error)
+	UpdateCircleResult(
+		circle *model.Circle,
+		result model.CircleResult,
+		outboxEvents model.OutboxEventsCallback,
+	) (*model.Circle, error)
+	CircleById(id int64) (*model.Circle, error)
+	PendingCircleRounds(limit int) ([]*model.CircleRound, error)
+	CreateCircleRound(
+		round *model.CircleRound,
+		circle *model.Circle,
+	) (*model.Circle, error)
+}
+
+type circleStageService struct {
+	storage CircleStageRepository
+	config  *config.Config
+	log     logger.Logger
+}
+
+func NewCircleStageService(
+	circleStageRepo CircleStageRepository,
+	config *config.Config,
+	log logger.Logger,
+) CircleStageService {
+	return &circleStageService{
+		storage: circleStageRepo,
+		config:  config,
+		log:     log,
+	}
+}
+
+// Run refreshes the outdated stages in the configured interval.
+// Blocks until the context is done.
+func (c *circleStageService) Run(ctx context.Context) {
+	interval := time.Duration(c.config.Circle.StageInterval) * time.Second
+
+	if interval <= 0 {
+		interval = defaultCircleStageInterval
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			circles, err := c.storage.RefreshCircleStages(circleStageBatchSize)
+
+			if err == nil && len(circles) > 0 {
+				c.log.Infof("refreshed the stage of %d circles", len(circles))
+			}
+
+			c.rejectStaleCommitments()
+			c.evaluateResults()
+			c.createPendingRounds()
+		}
+	}
+}
+
+// rejectStaleCommitments of the candidates and voters, that have not answered
+// their commitment until the commitment deadline of the circle.
+// The rejections are published as updated candidates and voters.
+func (c *circleStageService) rejectStaleCommitments() {
+	circles, err := c.storage.CirclesPastCommitmentDeadline(circleStageBatchSize)
+
+	if err != nil {
+		return
+	}
+
+	for _, circle := range circles {
+		candidates, voters, err := c.storage.RejectOpenCommitments(
+			circle.ID,
+			CreateRejectedCommitmentsOutboxEvents(circle.ID),
+		)
+
+		if err == nil {
+			c.log.Infof(
+				"rejected open commitments of %d candidates and %d voters of circle id %d",
+				len(candidates),
+				len(voters),
+				circle.ID,
+			)
+		}
+	}
+}
+
+// CreateRejectedCommitmentsOutboxEvents creates the callback for the outbox
+// events of the candidates and voters, whose open commitments have been rejected.
+func CreateRejectedCommitmentsOutboxEvents(circleId int64) model.RejectedCommitmentsOutboxEventsCallback {
+	return func(
+		candidates []*model.CircleCandidate,
+		voters []*model.CircleVoter,
+	) ([]*model.OutboxEvent, error) {
+		events := make([]*model.OutboxEvent, 0, len(candidates)+len(voters))
+
+		for _, candidate := range candidates {
+			event, err := model.NewOutboxEvent(
+				circleId,
+				model.EventKindCircleCandidate,
+				CreateCandidateChangedEvent(model.EventOperationUpdated, candidate),
+			)
+
+			if err != nil {
+				return nil, err
+			}
+
+			events = append(events, event)
+		}
+
+		for _, voter := range voters {
+			event, err := model.NewOutboxEvent(
+				circleId,
+				model.EventKindCircleVoter,
+				CreateVoterChangedEvent(model.EventOperationUpdated, voter),
+			)
+
+			if err != nil {
+				return nil, err
+			}
+
+			events = append(events, event)
+		}
+
+		return events, nil
+	}
+}
+
+// evaluateResults of the closed circles by their quorum. The result is
+// published as updated circle.
+func (c *circleStageService) evaluateResults() {
+	circles, err := c.storage.ClosedCirclesWithoutResult(circleStageBatchSize)
+
+	if err != nil {
+		return
+	}
+
+	for _, circle := range circles {
+		turnout, err := c.storage.CircleTurnout(circle.ID)
+
+		if err != nil {
+			continue
+		}
+
+		rankings, err := c.storage.RankingsByCircleId(circle.ID)
+
+		if err != nil && !database.RecordNotFound(err) {
+			continue
+		}
+
+		quorum := model.NewCircleQuorum(circle.QuorumTurnout, circle.QuorumWinnerVotes, turnout, rankings)
+		circle.Result = &quorum.Result
+
+		_, err = c.storage.UpdateCircleResult(
+			circle,
+			quorum.Result,
+			CreateCircleOutboxEvents(model.EventOperationUpdated, circle),
+		)
+
+		if err == nil {
+			c.log.Infof("evaluated result %s of circle id %d", quorum.Result, circle.ID)
+		}
+	}
+}
+
+// createPendingRounds of the multi round circles, whose previous round closed.
+// The top candidates of the rankings of the previous round advance into the
+// round, the voters of the previous round are carried over.
+func (c *circleStageService) createPendingRounds() {
+	rounds, err := c.storage.PendingCircleRounds(circleStageBatchSize)
+
+	if err != nil {
+		return
+	}
+
+	for _, round := range rounds {
+		previous, err := c.storage.CircleById(round.PreviousCircleID)
+
+		if err != nil {
+			continue
+		}
+
+		rankings, err := c.storage.RankingsByCircleId(previous.ID)
+
+		if err != nil && !database.RecordNotFound(err) {
+			continue
+		}
+
+		circle, err := c.storage.CreateCircleRound(round, nextRoundCircle(round, previous, rankings))
+
+		if err == nil {
+			c.log.Infof("created round %d of circle id %d as circle id %d", round.Number, round.CircleID, circle.ID)
+		}
+	}
+}
+
+// nextRoundCircle of the round with the settings of the previous round and the
+// top candidates of its rankings, that must be ordered by the score.
+// Candidates with the same score as the last advancing candidate are cut
+// by the order of the rankings.
+func nextRoundCircle(
+	round *model.CircleRound,
+	previous *model.Circle,
+	rankings []*model.Ranking,
+) *model.Circle {
+	validUntil := round.ValidUntil
+	circle := &model.Circle{
+		Name:              previous.Name,
+		Description:       previous.Description,
+		ImageSrc:          previous.ImageSrc,
+		CreatedFrom:       previous.CreatedFrom,
+		Private:           previous.Private,
+		VotingMode:        previous.VotingMode,
+		RankingOrder:      previous.RankingOrder,
+		NoneOfTheAbove:    previous.NoneOfTheAbove,
+		Delegation:        previous.Delegation,
+		QuorumTurnout:     previous.QuorumTurnout,
+		QuorumWinnerVotes: previous.QuorumWinnerVotes,
+		ValidFrom:         round.ValidFrom,
+		ValidUntil:        &validUntil,
+		Round:             round.Number,
+		FirstRoundID:      &round.CircleID,
+		Active:            true,
+	}
+
+	for _, ranking := range rankings {
+		if len(circle.Candidates) == round.Candidates {
+			break
+		}
+
+		if ranking.IdentityID == model.NoneOfTheAboveCandidate {
+			continue
+		}
+
+		circle.Candidates = append(
+			circle.Candidates, &model.CircleCandidate{
+				Candidate:  ranking.IdentityID,
+				Commitment: model.CommitmentCommitted,
+			},
+		)
+	}
+
+	if circle.NoneOfTheAbove {
+		circle.Candidates = append(
+			circle.Candidates, &model.CircleCandidate{
+				Candidate:  model.NoneOfTheAboveCandidate,
+				Commitment: model.CommitmentCommitted,
+			},
+		)
+	}
+
+	return circle
+}
@@ -0,0 +1,367 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	logger "github.com/VerzCar/vyf-lib-logger"
+	"github.com/VerzCar/vyf-vote-circle/api/model"
+	"github.com/VerzCar/vyf-vote-circle/app/config"
+	"github.com/VerzCar/vyf-vote-circle/app/pubsub"
+	"sync"
+	"time"
+)
+
+const (
+	// defaultStreamHeartbeatInterval is used if no heartbeat interval is configured.
+	defaultStreamHeartbeatInterval = 15 * time.Second
+	// defaultStreamBufferSize is used if no buffer size is configured.
+	defaultStreamBufferSize = 64
+	// streamSubscribeTimeout of the subscription to the pub sub backend
+	streamSubscribeTimeout = 10 * time.Second
+)
+
+// CircleStreamService streams the events of a circle to the clients.
+// All clients of a circle share one subscription to the pub sub backend.
+type CircleStreamService interface {
+	Subscribe(
+		ctx context.Context,
+		circleId int64,
+		lastSequence int64,
+	) (<-chan *model.CircleStreamEvent, error)
+	HeartbeatInterval() time.Duration
+}
+
+type CircleStreamCircleService interface {
+	Circle(
+		ctx context.Context,
+		circleId int64,
+	) (*model.Circle, error)
+}
+
+type CircleStreamEventLog interface {
+	Changes(
+		ctx context.Context,
+		circleId int64,
+		since int64,
+	) (*model.EventChangesResponse, error)
+}
+
+type CircleStreamPubSub interface {
+	Subscribe(
+		ctx context.Context,
+		channels []string,
+		handler pubsub.MessageHandler,
+	) (func(), error)
+}
+
+// circleTopic of a circle with all the local subscribers of the circle
+type circleTopic struct {
+	subscribers map[chan *model.CircleStreamEvent]struct{}
+	unsubscribe func()
+}
+
+type circleStreamService struct {
+	circleService CircleStreamCircleService
+	eventLog      CircleStreamEventLog
+	pubSub        CircleStreamPubSub
+	mu            sync.Mutex
+	topics        map[int64]*circleTopic
+	config        *config.Config
+	log           logger.Logger
+}
+
+func NewCircleStreamService(
+	circleService CircleStreamCircleService,
+	eventLog CircleStreamEventLog,
+	pubSub CircleStreamPubSub,
+	config *config.Config,
+	log logger.Logger,
+) CircleStreamService {
+	return &circleStreamService{
+		circleService: circleService,
+		eventLog:      eventLog,
+		pubSub:        pubSub,
+		topics:        make(map[int64]*circleTopic),
+		config:        config,
+		log:           log,
+	}
+}
+
+// Subscribe to the events of the circle, if the user is eligible to see the circle.
+// If the last sequence is given, the missed events after this sequence are
+// replayed first. If not all missed events are available anymore, a resync event
+// is sent instead. The returned channel is closed once the context is done or
+// the subscriber could not keep up with the events.
+func (c *circleStreamService) Subscribe(
+	ctx context.Context,
+	circleId int64,
+	lastSequence int64,
+) (<-chan *model.CircleStreamEvent, error) {
+	circle, err := c.circleService.Circle(ctx, circleId)
+
+	if err != nil {
+		return nil, err
+	}
+
+	subscriber := make(chan *model.CircleStreamEvent, c.bufferSize())
+
+	if err := c.join(circle, subscriber); err != nil {
+		return nil, err
+	}
+
+	// the missed events are read after joining the circle, so that
+	// no event is lost in between
+	var replay []*model.CircleStreamEvent
+
+	if lastSequence > 0 {
+		replay, lastSequence, err = c.replay(ctx, circleId, lastSequence)
+
+		if err != nil {
+			c.leave(circleId, subscriber)
+			return nil, err
+		}
+	}
+
+	events := make(chan *model.CircleStreamEvent)
+
+	go func() {
+		defer close(events)
+		defer c.leave(circleId, subscriber)
+
+		send := func(event *model.CircleStreamEvent) bool {
+			select {
+			case events <- event:
+				return true
+			case <-ctx.Done():
+				return false
+			}
+		}
+
+		for _, event := range replay {
+			if !send(event) {
+				return
+			}
+		}
+
+		for {
+			select {
+			case event, ok := <-subscriber:
+				if !ok {
+					return
+				}
+
+				// skip the events, that have already been replayed
+				if event.Sequence > 0 && event.Sequence <= lastSequence {
+					continue
+				}
+
+				if !send(event) {
+					return
+				}
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	return events, nil
+}
+
+// HeartbeatInterval in which the clients should be sent a heartbeat
+func (c *circleStreamService) HeartbeatInterval() time.Duration {
+	interval := time.Duration(c.config.Stream.HeartbeatInterval) * time.Second
+
+	if interval <= 0 {
+		return defaultStreamHeartbeatInterval
+	}
+
+	return interval
+}
+
+// join the topic of the circle with the subscriber.
+// The topic will be created and subscribed to the pub sub backend,
+// if the subscriber is the first one of the circle. The subscription
+// is made without holding the lock, if another subscriber created the
+// topic in the meantime, the subscription is released again.
+func (c *circleStreamService) join(
+	circle *model.Circle,
+	subscriber chan *model.CircleStreamEvent,
+) error {
+	circleId := circle.ID
+
+	if c.joinTopic(circleId, subscriber) {
+		return nil
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), streamSubscribeTimeout)
+	defer cancel()
+
+	unsubscribe, err := c.pubSub.Subscribe(
+		ctx,
+		circleChannelNames(circleId),
+		func(channel string, message *pubsub.ReceivedMessage) {
+			c.broadcast(circleId, streamEvent(message))
+		},
+	)
+
+	if err != nil {
+		c.log.Errorf("could not subscribe to events of circle id %d: %s", circleId, err)
+		return err
+	}
+
+	c.mu.Lock()
+
+	if topic, ok := c.topics[circleId]; ok {
+		topic.subscribers[subscriber] = struct{}{}
+		c.mu.Unlock()
+		unsubscribe()
+		return nil
+	}
+
+	c.topics[circleId] = &circleTopic{
+		subscribers: map[chan *model.CircleStreamEvent]struct{}{subscriber: {}},
+		unsubscribe: unsubscribe,
+	}
+	c.mu.Unlock()
+
+	return nil
+}
+
+// joinTopic of the circle with the subscriber, if the topic exists already
+func (c *circleStreamService) joinTopic(
+	circleId int64,
+	subscriber chan *model.CircleStreamEvent,
+) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	topic, ok := c.topics[circleId]
+
+	if ok {
+		topic.subscribers[subscriber] = struct{}{}
+	}
+
+	return ok
+}
+
+// leave the topic of the circle. The topic will be unsubscribed
+// from the pub sub backend, if it was the last subscriber of the circle.
+func (c *circleStreamService) leave(
+	circleId int64,
+	subscriber chan *model.CircleStreamEvent,
+) {
+	c.mu.Lock()
+
+	topic, ok := c.topics[circleId]
+
+	if !ok {
+		c.mu.Unlock()
+		return
+	}
+
+	if _, ok := topic.subscribers[subscriber]; ok {
+		delete(topic.subscribers, subscriber)
+		close(subscriber)
+	}
+
+	if len(topic.subscribers) > 0 {
+		c.mu.Unlock()
+		return
+	}
+
+	delete(c.topics, circleId)
+	c.mu.Unlock()
+
+	topic.unsubscribe()
+}
+
+// broadcast the event to all subscribers of the circle.
+// Subscribers that cannot keep up are dropped, they will
+// resume with the last received event on reconnect.
+func (c *circleStreamService) broadcast(circleId int64, event *model.CircleStreamEvent) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	topic, ok := c.topics[circleId]
+
+	if !ok {
+		return
+	}
+
+	for subscriber := range topic.subscribers {
+		select {
+		case subscriber <- event:
+		default:
+			c.log.Warnf("drop slow subscriber of circle id %d", circleId)
+			delete(topic.subscribers, subscriber)
+			close(subscriber)
+		}
+	}
+}
+
+// replay the events of the circle after the last sequence.
+// Returns the events and the sequence of the last replayed event.
+func (c *circleStreamService) replay(
+	ctx context.Context,
+	circleId int64,
+	lastSequence int64,
+) ([]*model.CircleStreamEvent, int64, error) {
+	changes, err := c.eventLog.Changes(ctx, circleId, lastSequence)
+
+	if err != nil {
+		return nil, 0, err
+	}
+
+	if !changes.Complete {
+		data, err := json.Marshal(&model.CircleResyncEvent{Sequence: changes.Sequence})
+
+		if err != nil {
+			return nil, 0, err
+		}
+
+		resync := &model.CircleStreamEvent{
+			Name:     model.EventNameResync,
+			Data:     data,
+			Sequence: changes.Sequence,
+		}
+
+		return []*model.CircleStreamEvent{resync}, changes.Sequence, nil
+	}
+
+	events := make([]*model.CircleStreamEvent, 0, len(changes.Events))
+
+	for _, entry := range changes.Events {
+		lastSequence = entry.Sequence
+		events = append(
+			events, &model.CircleStreamEvent{
+				Name:     entry.Kind.EventName(),
+				Data:     entry.Event,
+				Sequence: entry.Sequence,
+			},
+		)
+	}
+
+	return events, lastSequence, nil
+}
+
+func (c *circleStreamService) bufferSize() int {
+	if c.config.Stream.BufferSize > 0 {
+		return c.config.Stream.BufferSize
+	}
+	return defaultStreamBufferSize
+}
+
+// streamEvent of the received message with the sequence of the event
+func streamEvent(message *pubsub.ReceivedMessage) *model.CircleStreamEvent {
+	sequenced := &struct {
+		Sequence int64 `json:"sequence"`
+	}{}
+
+	_ = json.Unmarshal(message.Data, sequenced)
+
+	return &model.CircleStreamEvent{
+		Name:     message.Name,
+		Data:     message.Data,
+		Sequence: sequenced.Sequence,
+	}
+}
@@ -17,6 +17,14 @@ import (
 	"mime/multipart"
 	"net/http"
 	"os"
+	"strings"
+)
+
+const (
+	// circleImagePath of the uploaded image of a circle in the external storage
+	circleImagePath = "circle/image/%d/main.png"
+	// circleImageSrcPattern matches the sources of the uploaded images of the circles
+	circleImageSrcPattern = "%/circle/image/%/main.png"
 )
 
 type CircleUploadService interface {
@@ -31,21 +39,41 @@ type CircleUploadService interface {
 	) (string, error)
 }
 
+type CircleUploadRepository interface {
+	CircleById(id int64) (*model.Circle, error)
+	CountUploadedCircleImagesOfUser(
+		userIdentityId string,
+		imageSrcPattern string,
+	) (int64, error)
+}
+
+type CircleUploadOptionService interface {
+	UserOptionOfUser(
+		userIdentityId string,
+	) (*model.UserOptionResponse, error)
+}
+
 type circleUploadService struct {
+	storage           CircleUploadRepository
 	circleService     CircleService
+	userOptionService CircleUploadOptionService
 	extStorageService awsx.S3Service
 	config            *config.Config
 	log               logger.Logger
 }
 
 func NewCircleUploadService(
+	circleUploadRepo CircleUploadRepository,
 	circleService CircleService,
+	userOptionService CircleUploadOptionService,
 	extStorageService awsx.S3Service,
 	config *config.Config,
 	log logger.Logger,
 ) CircleUploadService {
 	return &circleUploadService{
+		storage:           circleUploadRepo,
 		circleService:     circleService,
+		userOptionService: userOptionService,
 		extStorageService: extStorageService,
 		config:            config,
 		log:               log,
@@ -57,6 +85,9 @@ func (c *circleUploadService) UploadImage(
 	multiPartFile *multipart.FileHeader,
 	circleId int64,
 ) (string, error) {
+	if err := c.checkImageUploadQuota(circleId); err != nil {
+		return "", err
+	}
 
 	// Open and validate file
 	contentFile, err := c.openAndValidateFile(multiPartFile)
@@ -103,6 +134,40 @@ func (c *circleUploadService) DeleteImage(
 	return imageSrc, nil
 }
 
+// checkImageUploadQuota of the plan of the owner of the circle.
+// Replacing the uploaded image of a circle does not count against the quota.
+func (c *circleUploadService) checkImageUploadQuota(circleId int64) error {
+	circle, err := c.storage.CircleById(circleId)
+
+	if err != nil {
+		return err
+	}
+
+	imagePath := fmt.Sprintf(circleImagePath, circle.ID)
+
+	if strings.HasSuffix(circle.ImageSrc, "/"+imagePath) {
+		return nil
+	}
+
+	imagesCount, err := c.storage.CountUploadedCircleImagesOfUser(circle.CreatedFrom, circleImageSrcPattern)
+
+	if err != nil {
+		return err
+	}
+
+	userOption, err := c.userOptionService.UserOptionOfUser(circle.CreatedFrom)
+
+	if err != nil {
+		return err
+	}
+
+	if imagesCount >= int64(userOption.MaxImageUploads) {
+		return fmt.Errorf("user has more than %d allowed image uploads", userOption.MaxImageUploads)
+	}
+
+	return nil
+}
+
 func (c *circleUploadService) openAndValidateFile(multiPartFile *multipart.FileHeader) (
 	contentFile multipart.File,
 	err error,
@@ -153,7 +218,7 @@ func (c *circleUploadService) uploadFile(
 	circleId int64,
 	tempImageFile *os.File,
 ) (string, error) {
-	filePath := fmt.Sprintf("circle/image/%d/%s", circleId, "main.png")
+	filePath := fmt.Sprintf(circleImagePath, circleId)
 	_, err := c.extStorageService.Upload(ctx, filePath, tempImageFile)
 	if err != nil {
 		c.log.Errorf("error uploading file to external storage service: %s", err)
@@ -41,53 +41,58 @@ type CircleVoterRepository interface {
 		circleId int64,
 		filterBy *model.CircleVotersFilterBy,
 	) ([]*model.CircleVoter, error)
-	CreateNewCircleVoter(voter *model.CircleVoter) (*model.CircleVoter, error)
+	CreateNewCircleVoter(
+		voter *model.CircleVoter,
+		outboxEvents model.OutboxEventsCallback,
+	) (*model.CircleVoter, error)
 	CircleVoterByCircleId(circleId int64, userIdentityId string) (*model.CircleVoter, error)
 	CircleVoterCountByCircleId(
 		circleId int64,
 	) (int64, error)
 	IsVoterInCircle(userIdentityId string, circleId int64) (bool, error)
 	CircleById(id int64) (*model.Circle, error)
-	DeleteCircleVoter(voterId int64) error
 	HasVoterVotedForCircle(
 		circleId int64,
 		voterId int64,
 	) (bool, error)
 }
 
-type CircleVoterSubscription interface {
-	CircleVoterChangedEvent(
-		ctx context.Context,
-		circleId int64,
-		event *model.CircleVoterChangedEvent,
-	) error
+type CircleVoterOptionService interface {
+	UserOptionOfUser(
+		userIdentityId string,
+	) (*model.UserOptionResponse, error)
 }
 
-type CircleVoterOptionService interface {
-	UserOption(
+// CircleVoterVoteService removes the voters, as the delegations of a removed
+// voter change the weights of the votes.
+type CircleVoterVoteService interface {
+	RemoveCircleVoter(
 		ctx context.Context,
-	) (*model.UserOptionResponse, error)
+		circleId int64,
+		voter *model.CircleVoter,
+		outboxEvents model.OutboxEventsCallback,
+	) error
 }
 
 type circleVoterService struct {
 	storage           CircleVoterRepository
-	subscription      CircleVoterSubscription
 	userOptionService CircleCandidateOptionService
+	voteService       CircleVoterVoteService
 	config            *config.Config
 	log               logger.Logger
 }
 
 func NewCircleVoterService(
 	circleVoterRepo CircleVoterRepository,
-	subscription CircleVoterSubscription,
 	userOptionService CircleCandidateOptionService,
+	voteService CircleVoterVoteService,
 	config *config.Config,
 	log logger.Logger,
 ) CircleVoterService {
 	return &circleVoterService{
 		storage:           circleVoterRepo,
-		subscription:      subscription,
 		userOptionService: userOptionService,
+		voteService:       voteService,
 		config:            config,
 		log:               log,
 	}
@@ -161,10 +166,16 @@ func (c *circleVoterService) CircleVoterJoinCircle(
 		return nil, err
 	}
 
-	userOption, _ := c.userOptionService.UserOption(ctx)
+	userOption, err := c.userOptionService.UserOptionOfUser(circle.CreatedFrom)
+
+	if err != nil {
+		return nil, err
+	}
+
+	maxVoters := userOption.CircleMaxVoters(circle.Private)
 
-	if votersCount >= int64(userOption.MaxVoters) {
-		err = fmt.Errorf("circle has more than %d allowed voters", userOption.MaxVoters)
+	if votersCount >= int64(maxVoters) {
+		err = fmt.Errorf("circle has more than %d allowed voters", maxVoters)
 		return nil, err
 	}
 
@@ -185,16 +196,16 @@ func (c *circleVoterService) CircleVoterJoinCircle(
 		CircleRefer: &circle.ID,
 		Commitment:  model.CommitmentCommitted,
 	}
-	voter, err := c.storage.CreateNewCircleVoter(circleVoter)
+	voter, err := c.storage.CreateNewCircleVoter(
+		circleVoter,
+		CreateVoterOutboxEvents(circleId, model.EventOperationCreated, circleVoter),
+	)
 
 	if err != nil {
 		c.log.Errorf("error adding voter to circle id %d: %s", circleId, err)
 		return nil, err
 	}
 
-	voterEvent := CreateVoterChangedEvent(model.EventOperationCreated, voter)
-	_ = c.subscription.CircleVoterChangedEvent(ctx, circleId, voterEvent)
-
 	return voter, nil
 }
 
@@ -239,7 +250,12 @@ func (c *circleVoterService) CircleVoterLeaveCircle(
 		return fmt.Errorf("voter has voted")
 	}
 
-	err = c.storage.DeleteCircleVoter(voter.ID)
+	err = c.voteService.RemoveCircleVoter(
+		ctx,
+		circleId,
+		voter,
+		CreateVoterOutboxEvents(circleId, model.EventOperationDeleted, voter),
+	)
 
 	if err != nil {
 		c.log.Errorf(
@@ -251,9 +267,6 @@ func (c *circleVoterService) CircleVoterLeaveCircle(
 		return fmt.Errorf("leaving as voter from cirlce failed")
 	}
 
-	voterEvent := CreateVoterChangedEvent(model.EventOperationDeleted, voter)
-	_ = c.subscription.CircleVoterChangedEvent(ctx, circleId, voterEvent)
-
 	return nil
 }
 
@@ -305,9 +318,6 @@ func (c *circleVoterService) CircleVotersAddToCircle(
 		}
 
 		updatedVoters = append(updatedVoters, newVoter)
-
-		voterEvent := CreateVoterChangedEvent(model.EventOperationCreated, newVoter)
-		_ = c.subscription.CircleVoterChangedEvent(ctx, circleId, voterEvent)
 	}
 
 	return updatedVoters, nil
@@ -366,7 +376,12 @@ func (c *circleVoterService) CircleVoterRemoveFromCircle(
 		return fmt.Errorf("voter has voted")
 	}
 
-	err = c.storage.DeleteCircleVoter(voter.ID)
+	err = c.voteService.RemoveCircleVoter(
+		ctx,
+		circleId,
+		voter,
+		CreateVoterOutboxEvents(circleId, model.EventOperationDeleted, voter),
+	)
 
 	if err != nil {
 		c.log.Errorf(
@@ -378,9 +393,6 @@ func (c *circleVoterService) CircleVoterRemoveFromCircle(
 		return fmt.Errorf("removing voter from cirlce failed")
 	}
 
-	voterEvent := CreateVoterChangedEvent(model.EventOperationDeleted, voter)
-	_ = c.subscription.CircleVoterChangedEvent(ctx, circleId, voterEvent)
-
 	return nil
 }
 
@@ -400,16 +412,22 @@ func (c *circleVoterService) addVoterToCircle(
 		return nil, err
 	}
 
-	candidatesCount, err := c.storage.CircleVoterCountByCircleId(circle.ID)
+	votersCount, err := c.storage.CircleVoterCountByCircleId(circle.ID)
 
 	if err != nil {
 		return nil, fmt.Errorf("count of voter failure")
 	}
 
-	userOption, _ := c.userOptionService.UserOption(ctx)
+	userOption, err := c.userOptionService.UserOptionOfUser(circle.CreatedFrom)
+
+	if err != nil {
+		return nil, err
+	}
+
+	maxVoters := userOption.CircleMaxVoters(circle.Private)
 
-	if candidatesCount >= int64(userOption.MaxCandidates) {
-		err = fmt.Errorf("circle has more than %d allowed voters", userOption.MaxVoters)
+	if votersCount >= int64(maxVoters) {
+		err = fmt.Errorf("circle has more than %d allowed voters", maxVoters)
 		return nil, err
 	}
 
@@ -419,7 +437,10 @@ func (c *circleVoterService) addVoterToCircle(
 		CircleRefer: &circle.ID,
 	}
 
-	newVoter, err := c.storage.CreateNewCircleVoter(circleVoter)
+	newVoter, err := c.storage.CreateNewCircleVoter(
+		circleVoter,
+		CreateVoterOutboxEvents(circle.ID, model.EventOperationCreated, circleVoter),
+	)
 
 	if err != nil {
 		c.log.Errorf("error adding voter to circle id %d: %s", circle.ID, err)
@@ -5,7 +5,7 @@ import (
 	"fmt"
 	logger "github.com/VerzCar/vyf-lib-logger"
 	"github.com/VerzCar/vyf-vote-circle/api/model"
-	"github.com/ably/ably-go/ably"
+	"github.com/VerzCar/vyf-vote-circle/app/pubsub"
 )
 
 type CircleVoterSubscriptionService interface {
@@ -17,16 +17,19 @@ type CircleVoterSubscriptionService interface {
 }
 
 type circleVoterSubscriptionService struct {
-	pubSubService *ably.Realtime
+	pubSubService pubsub.Client
+	eventLog      EventLogService
 	log           logger.Logger
 }
 
 func NewCircleVoterSubscriptionService(
-	pubSubService *ably.Realtime,
+	pubSubService pubsub.Client,
+	eventLog EventLogService,
 	log logger.Logger,
 ) CircleVoterSubscriptionService {
 	return &circleVoterSubscriptionService{
 		pubSubService: pubSubService,
+		eventLog:      eventLog,
 		log:           log,
 	}
 }
@@ -36,12 +39,14 @@ func (s *circleVoterSubscriptionService) CircleVoterChangedEvent(
 	circleId int64,
 	event *model.CircleVoterChangedEvent,
 ) error {
-	channelName := fmt.Sprintf("circle-%d:voter", circleId)
-	msgName := "circle-voter-changed"
+	channelName := circleVoterChannelName(circleId)
+	msgName := model.EventNameCircleVoterChanged
 
-	channel := s.pubSubService.Channels.Get(channelName)
+	// the event is published even if it cannot be logged,
+	// clients will then detect the gap in the sequence
+	_ = s.eventLog.Append(ctx, circleId, model.EventKindCircleVoter, event)
 
-	err := channel.Publish(ctx, msgName, event)
+	err := s.pubSubService.Publish(ctx, channelName, &pubsub.Message{Name: msgName, Data: event})
 
 	if err != nil {
 		s.log.Errorf(
@@ -66,9 +71,32 @@ func CreateVoterChangedEvent(
 			ID:         voter.ID,
 			Voter:      voter.Voter,
 			VotedFor:   voter.VotedFor,
+			Abstained:  voter.Abstained,
 			Commitment: voter.Commitment,
 			CreatedAt:  voter.CreatedAt,
 			UpdatedAt:  voter.UpdatedAt,
 		},
 	}
 }
+
+// CreateVoterOutboxEvents creates the callback for the outbox event
+// of the changed voter, that is written in the same transaction as the change.
+func CreateVoterOutboxEvents(
+	circleId int64,
+	operation model.EventOperation,
+	voter *model.CircleVoter,
+) model.OutboxEventsCallback {
+	return func() ([]*model.OutboxEvent, error) {
+		event, err := model.NewOutboxEvent(circleId, model.EventKindCircleVoter, CreateVoterChangedEvent(operation, voter))
+
+		if err != nil {
+			return nil, err
+		}
+
+		return []*model.OutboxEvent{event}, nil
+	}
+}
+
+func circleVoterChannelName(circleId int64) string {
+	return fmt.Sprintf("circle-%d:voter", circleId)
+}
@@ -0,0 +1,145 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	logger "github.com/VerzCar/vyf-lib-logger"
+	"github.com/VerzCar/vyf-vote-circle/api/model"
+	"github.com/VerzCar/vyf-vote-circle/app/config"
+)
+
+// EventLogService assigns the per circle sequence numbers to the events
+// before they are published and keeps a bounded log of the published
+// events, so that clients can replay missed events.
+type EventLogService interface {
+	Append(
+		ctx context.Context,
+		circleId int64,
+		kind model.EventKind,
+		events ...model.SequencedEvent,
+	) error
+	Changes(
+		ctx context.Context,
+		circleId int64,
+		since int64,
+	) (*model.EventChangesResponse, error)
+}
+
+type EventLogCache interface {
+	ReserveEventSequences(
+		ctx context.Context,
+		circleId int64,
+		count int64,
+	) (int64, error)
+	AppendEventLog(
+		ctx context.Context,
+		circleId int64,
+		entries []*model.EventLogEntry,
+	) error
+	EventLog(
+		ctx context.Context,
+		circleId int64,
+		since int64,
+	) ([]*model.EventLogEntry, int64, error)
+}
+
+type eventLogService struct {
+	cache  EventLogCache
+	config *config.Config
+	log    logger.Logger
+}
+
+func NewEventLogService(
+	cache EventLogCache,
+	config *config.Config,
+	log logger.Logger,
+) EventLogService {
+	return &eventLogService{
+		cache:  cache,
+		config: config,
+		log:    log,
+	}
+}
+
+// Append the events of the given kind to the event log of the circle.
+// Each event gets the next sequence number of the circle assigned,
+// in the order the events are given.
+// Events that already have a sequence number assigned, have been
+// appended before and will not be appended again.
+func (c *eventLogService) Append(
+	ctx context.Context,
+	circleId int64,
+	kind model.EventKind,
+	events ...model.SequencedEvent,
+) error {
+	if len(events) == 0 || events[0].GetSequence() > 0 {
+		return nil
+	}
+
+	lastSequence, err := c.cache.ReserveEventSequences(ctx, circleId, int64(len(events)))
+
+	if err != nil {
+		return err
+	}
+
+	entries := make([]*model.EventLogEntry, 0, len(events))
+	sequence := lastSequence - int64(len(events))
+
+	for _, event := range events {
+		sequence++
+		event.SetSequence(sequence)
+
+		encodedEvent, err := json.Marshal(event)
+
+		if err != nil {
+			c.log.Errorf("error encoding event for circle id %d: %s", circleId, err)
+			return err
+		}
+
+		entries = append(
+			entries, &model.EventLogEntry{
+				Event:    encodedEvent,
+				Kind:     kind,
+				Sequence: sequence,
+			},
+		)
+	}
+
+	return c.cache.AppendEventLog(ctx, circleId, entries)
+}
+
+// Changes of the circle after the given sequence number.
+// The changes are complete, if all events after the sequence number
+// up to the latest sequence number are contained in the event log.
+func (c *eventLogService) Changes(
+	ctx context.Context,
+	circleId int64,
+	since int64,
+) (*model.EventChangesResponse, error) {
+	entries, latestSequence, err := c.cache.EventLog(ctx, circleId, since)
+
+	if err != nil {
+		return nil, err
+	}
+
+	complete := since <= latestSequence
+	expectedSequence := since + 1
+
+	for _, entry := range entries {
+		if entry.Sequence != expectedSequence {
+			complete = false
+			break
+		}
+		expectedSequence++
+	}
+
+	if expectedSequence <= latestSequence {
+		complete = false
+	}
+
+	return &model.EventChangesResponse{
+		Events:   entries,
+		Sequence: latestSequence,
+		Complete: complete,
+	}, nil
+}
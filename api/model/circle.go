@@ -8,21 +8,47 @@ import (
 )
 
 type Circle struct {
-	UpdatedAt   time.Time          `json:"updatedAt" gorm:"autoUpdateTime;"`
-	CreatedAt   time.Time          `json:"createdAt" gorm:"autoCreateTime;"`
-	ValidFrom   time.Time          `json:"validFrom"`
-	ValidUntil  *time.Time         `json:"validUntil"`
-	CreatedFrom string             `json:"createdFrom" gorm:"type:varchar(50);not null"`
-	ImageSrc    string             `json:"imageSrc" gorm:"type:text;not null;"`
-	Description string             `json:"description" gorm:"type:varchar(1200);not null;"`
-	Name        string             `json:"name" gorm:"type:varchar(40);not null;"`
-	Votes       []*Vote            `json:"votes" gorm:"foreignKey:CircleRefer;constraint:OnDelete:CASCADE;"`
-	Voters      []*CircleVoter     `json:"voters" gorm:"foreignKey:CircleRefer;constraint:OnDelete:CASCADE;"`
-	Candidates  []*CircleCandidate `json:"candidate" gorm:"foreignKey:CircleRefer;constraint:OnDelete:CASCADE;"`
-	Stage       CircleStage        `json:"stage" gorm:"type:circleStage;not null;default:COLD"`
-	ID          int64              `json:"id" gorm:"primary_key;index;"`
-	Private     bool               `json:"private" gorm:"not null;default:false;"`
-	Active      bool               `json:"active" gorm:"not null;default:true;"`
+	UpdatedAt    time.Time          `json:"updatedAt" gorm:"autoUpdateTime;"`
+	CreatedAt    time.Time          `json:"createdAt" gorm:"autoCreateTime;"`
+	ValidFrom    time.Time          `json:"validFrom"`
+	ValidUntil   *time.Time         `json:"validUntil"`
+	CreatedFrom  string             `json:"createdFrom" gorm:"type:varchar(50);not null"`
+	ImageSrc     string             `json:"imageSrc" gorm:"type:text;not null;"`
+	Description  string             `json:"description" gorm:"type:varchar(1200);not null;"`
+	Name         string             `json:"name" gorm:"type:varchar(40);not null;"`
+	Votes        []*Vote            `json:"votes" gorm:"foreignKey:CircleRefer;constraint:OnDelete:CASCADE;"`
+	Voters       []*CircleVoter     `json:"voters" gorm:"foreignKey:CircleRefer;constraint:OnDelete:CASCADE;"`
+	Candidates   []*CircleCandidate `json:"candidate" gorm:"foreignKey:CircleRefer;constraint:OnDelete:CASCADE;"`
+	Rounds       []*CircleRound     `json:"rounds" gorm:"foreignKey:CircleID;constraint:OnDelete:CASCADE;"`
+	Stage        CircleStage        `json:"stage" gorm:"type:circleStage;not null;default:COLD"`
+	VotingMode   VotingMode         `json:"votingMode" gorm:"type:votingMode;not null;default:SINGLE"`
+	RankingOrder RankingOrder       `json:"rankingOrder" gorm:"type:rankingOrder;not null;default:TOTAL"`
+	ID           int64              `json:"id" gorm:"primary_key;index;"`
+	Private      bool               `json:"private" gorm:"not null;default:false;"`
+	Active       bool               `json:"active" gorm:"not null;default:true;"`
+	// NoneOfTheAbove adds the pseudo candidate none of the above to the circle
+	NoneOfTheAbove bool `json:"noneOfTheAbove" gorm:"not null;default:false;"`
+	// Delegation allows the voters to delegate their vote to another voter of the circle
+	Delegation bool `json:"delegation" gorm:"not null;default:false;"`
+	// QuorumTurnout is the minimum share of the voters, that must vote or abstain
+	QuorumTurnout float64 `json:"quorumTurnout" gorm:"not null;default:0;"`
+	// QuorumWinnerVotes is the minimum number of ballots of the winner
+	QuorumWinnerVotes int64 `json:"quorumWinnerVotes" gorm:"not null;default:0;"`
+	// Result of the circle evaluated by the quorum after the circle closed
+	Result *CircleResult `json:"result" gorm:"type:circleResult;"`
+	// Round of the circle in a multi round circle, the first round is the circle itself
+	Round int `json:"round" gorm:"not null;default:1;"`
+	// FirstRoundID is the circle of the first round, if the circle is a further round
+	FirstRoundID *int64 `json:"firstRoundId"`
+	// Nomination allows the voters to nominate candidates while the circle is cold
+	Nomination bool `json:"nomination" gorm:"not null;default:false;"`
+	// NominationsPerVoter is the maximum number of nominees each voter may nominate
+	NominationsPerVoter int `json:"nominationsPerVoter" gorm:"not null;default:1;"`
+	// NominationSeconds is the number of seconds a nomination requires
+	NominationSeconds int `json:"nominationSeconds" gorm:"not null;default:0;"`
+	// CommitmentDeadline until the candidates and voters must answer their
+	// commitment, the open commitments are rejected afterwards
+	CommitmentDeadline *time.Time `json:"commitmentDeadline"`
 }
 
 type CircleUriRequest struct {
@@ -34,37 +60,62 @@ type CircleByUriRequest struct {
 }
 
 type CircleResponse struct {
-	CreatedAt   time.Time   `json:"createdAt"`
-	UpdatedAt   time.Time   `json:"updatedAt"`
-	ValidFrom   time.Time   `json:"validFrom"`
-	ValidUntil  *time.Time  `json:"validUntil"`
-	Name        string      `json:"name"`
-	Description string      `json:"description"`
-	ImageSrc    string      `json:"imageSrc"`
-	CreatedFrom string      `json:"createdFrom"`
-	Stage       CircleStage `json:"stage"`
-	ID          int64       `json:"id"`
-	Private     bool        `json:"private"`
-	Active      bool        `json:"active"`
+	CreatedAt           time.Time     `json:"createdAt"`
+	UpdatedAt           time.Time     `json:"updatedAt"`
+	ValidFrom           time.Time     `json:"validFrom"`
+	ValidUntil          *time.Time    `json:"validUntil"`
+	Name                string        `json:"name"`
+	Description         string        `json:"description"`
+	ImageSrc            string        `json:"imageSrc"`
+	CreatedFrom         string        `json:"createdFrom"`
+	Stage               CircleStage   `json:"stage"`
+	VotingMode          VotingMode    `json:"votingMode"`
+	RankingOrder        RankingOrder  `json:"rankingOrder"`
+	ID                  int64         `json:"id"`
+	Private             bool          `json:"private"`
+	Active              bool          `json:"active"`
+	NoneOfTheAbove      bool          `json:"noneOfTheAbove"`
+	Delegation          bool          `json:"delegation"`
+	QuorumTurnout       float64       `json:"quorumTurnout"`
+	QuorumWinnerVotes   int64         `json:"quorumWinnerVotes"`
+	Result              *CircleResult `json:"result"`
+	FirstRoundID        *int64        `json:"firstRoundId"`
+	Round               int           `json:"round"`
+	Nomination          bool          `json:"nomination"`
+	NominationsPerVoter int           `json:"nominationsPerVoter"`
+	NominationSeconds   int           `json:"nominationSeconds"`
+	CommitmentDeadline  *time.Time    `json:"commitmentDeadline"`
 }
 
 type CircleUpdateRequest struct {
-	Name        *string    `json:"name,omitempty" validate:"omitempty,gt=0,lte=40"`
-	Description *string    `json:"description,omitempty" validate:"omitempty,lte=1200"`
-	ImageSrc    *string    `json:"imageSrc,omitempty" validate:"omitempty,url"`
-	ValidUntil  *time.Time `json:"validUntil,omitempty" validate:"omitempty"`
-	ValidFrom   *time.Time `json:"ValidFrom,omitempty" validate:"omitempty"`
+	Name               *string    `json:"name,omitempty" validate:"omitempty,gt=0,lte=40"`
+	Description        *string    `json:"description,omitempty" validate:"omitempty,lte=1200"`
+	ImageSrc           *string    `json:"imageSrc,omitempty" validate:"omitempty,url"`
+	ValidUntil         *time.Time `json:"validUntil,omitempty" validate:"omitempty"`
+	ValidFrom          *time.Time `json:"ValidFrom,omitempty" validate:"omitempty"`
+	CommitmentDeadline *time.Time `json:"commitmentDeadline,omitempty" validate:"omitempty"`
 }
 
 type CircleCreateRequest struct {
-	Description *string                   `json:"description,omitempty" validate:"omitempty,gt=0,lte=1200"`
-	ImageSrc    *string                   `json:"imageSrc,omitempty" validate:"omitempty,url"`
-	Private     *bool                     `json:"private,omitempty" validate:"omitempty"`
-	ValidUntil  *time.Time                `json:"validUntil,omitempty" validate:"omitempty"`
-	ValidFrom   *time.Time                `json:"ValidFrom,omitempty" validate:"omitempty"`
-	Name        string                    `json:"name" validate:"gt=0,lte=40"`
-	Voters      []*CircleVoterRequest     `json:"voters,omitempty"`
-	Candidates  []*CircleCandidateRequest `json:"candidates,omitempty"`
+	Description         *string                   `json:"description,omitempty" validate:"omitempty,gt=0,lte=1200"`
+	ImageSrc            *string                   `json:"imageSrc,omitempty" validate:"omitempty,url"`
+	Private             *bool                     `json:"private,omitempty" validate:"omitempty"`
+	ValidUntil          *time.Time                `json:"validUntil,omitempty" validate:"omitempty"`
+	ValidFrom           *time.Time                `json:"ValidFrom,omitempty" validate:"omitempty"`
+	VotingMode          *VotingMode               `json:"votingMode,omitempty" validate:"omitempty,oneof=SINGLE APPROVAL SCORE"`
+	RankingOrder        *RankingOrder             `json:"rankingOrder,omitempty" validate:"omitempty,oneof=TOTAL AVERAGE"`
+	NoneOfTheAbove      *bool                     `json:"noneOfTheAbove,omitempty" validate:"omitempty"`
+	Delegation          *bool                     `json:"delegation,omitempty" validate:"omitempty"`
+	QuorumTurnout       *float64                  `json:"quorumTurnout,omitempty" validate:"omitempty,gte=0,lte=1"`
+	QuorumWinnerVotes   *int64                    `json:"quorumWinnerVotes,omitempty" validate:"omitempty,gte=0"`
+	Name                string                    `json:"name" validate:"gt=0,lte=40"`
+	Voters              []*CircleVoterRequest     `json:"voters,omitempty"`
+	Candidates          []*CircleCandidateRequest `json:"candidates,omitempty"`
+	Rounds              []*CircleRoundRequest     `json:"rounds,omitempty" validate:"omitempty,lte=5,dive"`
+	Nomination          *bool                     `json:"nomination,omitempty" validate:"omitempty"`
+	NominationsPerVoter *int                      `json:"nominationsPerVoter,omitempty" validate:"omitempty,gte=1,lte=10"`
+	NominationSeconds   *int                      `json:"nominationSeconds,omitempty" validate:"omitempty,gte=0,lte=50"`
+	CommitmentDeadline  *time.Time                `json:"commitmentDeadline,omitempty" validate:"omitempty"`
 }
 
 type CirclePaginated struct {
@@ -91,6 +142,121 @@ type CirclePaginatedResponse struct {
 	Active          bool        `json:"active"`
 }
 
+// CircleTurnout of the voters of a circle. Abstentions count
+// toward the turnout, but not toward any candidate.
+type CircleTurnout struct {
+	Voters      int64   `json:"voters"`
+	Ballots     int64   `json:"ballots"`
+	Abstentions int64   `json:"abstentions"`
+	Turnout     float64 `json:"turnout"`
+}
+
+// CircleQuorum of a circle with the current progress toward the quorum
+// and the result the circle would have, if it closed now.
+type CircleQuorum struct {
+	Result             CircleResult `json:"result"`
+	Turnout            float64      `json:"turnout"`
+	QuorumTurnout      float64      `json:"quorumTurnout"`
+	WinnerVotes        int64        `json:"winnerVotes"`
+	QuorumWinnerVotes  int64        `json:"quorumWinnerVotes"`
+	TurnoutReached     bool         `json:"turnoutReached"`
+	WinnerVotesReached bool         `json:"winnerVotesReached"`
+}
+
+// CircleQuorumMeta of the circle and rankings responses while the circle is hot
+type CircleQuorumMeta struct {
+	Quorum *CircleQuorum `json:"quorum"`
+}
+
+// NewCircleQuorum evaluates the quorum of the circle with the given turnout
+// and rankings, that must be ordered by the score of the candidates.
+// The result is invalid if the turnout is below the quorum. It is inconclusive
+// if there is no winner, the winner has fewer ballots than required
+// or several candidates share the first place.
+func NewCircleQuorum(
+	quorumTurnout float64,
+	quorumWinnerVotes int64,
+	turnout *CircleTurnout,
+	rankings []*Ranking,
+) *CircleQuorum {
+	quorum := &CircleQuorum{
+		Turnout:           turnout.Turnout,
+		QuorumTurnout:     quorumTurnout,
+		QuorumWinnerVotes: quorumWinnerVotes,
+		TurnoutReached:    turnout.Turnout >= quorumTurnout,
+	}
+
+	if len(rankings) > 0 {
+		quorum.WinnerVotes = rankings[0].Ballots
+	}
+
+	quorum.WinnerVotesReached = len(rankings) > 0 && quorum.WinnerVotes >= quorumWinnerVotes
+
+	switch {
+	case !quorum.TurnoutReached:
+		quorum.Result = CircleResultInvalid
+	case !quorum.WinnerVotesReached:
+		quorum.Result = CircleResultInconclusive
+	case len(rankings) > 1 && rankings[0].Score == rankings[1].Score:
+		quorum.Result = CircleResultInconclusive
+	default:
+		quorum.Result = CircleResultValid
+	}
+
+	return quorum
+}
+
+type CircleChangedEvent struct {
+	Circle    *CircleResponse `json:"circle"`
+	Operation EventOperation  `json:"operation"`
+	Sequence  int64           `json:"sequence"`
+}
+
+func (e *CircleChangedEvent) SetSequence(sequence int64) {
+	e.Sequence = sequence
+}
+
+func (e *CircleChangedEvent) GetSequence() int64 {
+	return e.Sequence
+}
+
+// NewCircleChangedEvent of the circle with the given operation
+func NewCircleChangedEvent(
+	operation EventOperation,
+	circle *Circle,
+) *CircleChangedEvent {
+	return &CircleChangedEvent{
+		Operation: operation,
+		Circle: &CircleResponse{
+			CreatedAt:           circle.CreatedAt,
+			UpdatedAt:           circle.UpdatedAt,
+			ValidFrom:           circle.ValidFrom,
+			ValidUntil:          circle.ValidUntil,
+			Name:                circle.Name,
+			Description:         circle.Description,
+			ImageSrc:            circle.ImageSrc,
+			CreatedFrom:         circle.CreatedFrom,
+			Stage:               circle.Stage,
+			VotingMode:          circle.VotingMode,
+			RankingOrder:        circle.RankingOrder,
+			ID:                  circle.ID,
+			Private:             circle.Private,
+			Active:              circle.Active,
+			NoneOfTheAbove:      circle.NoneOfTheAbove,
+			Delegation:          circle.Delegation,
+			QuorumTurnout:       circle.QuorumTurnout,
+			QuorumWinnerVotes:   circle.QuorumWinnerVotes,
+			Result:              circle.Result,
+			FirstRoundID:        circle.FirstRoundID,
+			Round:               circle.Round,
+			Nomination:          circle.Nomination,
+			NominationsPerVoter: circle.NominationsPerVoter,
+			NominationSeconds:   circle.NominationSeconds,
+			CommitmentDeadline:  circle.CommitmentDeadline,
+		},
+	}
+}
+
 type CircleStage string
 
 const (
@@ -120,6 +286,105 @@ func (e CircleStage) String() string {
 	return string(e)
 }
 
+// VotingMode of a circle. In a single circle each voter votes for one
+// candidate, in an approval circle each voter may approve any number
+// of candidates and in a score circle each voter gives any number of
+// candidates 0 to 5 stars.
+type VotingMode string
+
+const (
+	VotingModeSingle   VotingMode = "SINGLE"
+	VotingModeApproval VotingMode = "APPROVAL"
+	VotingModeScore    VotingMode = "SCORE"
+)
+
+var AllVotingMode = []VotingMode{
+	VotingModeSingle,
+	VotingModeApproval,
+	VotingModeScore,
+}
+
+func (e *VotingMode) Scan(value interface{}) error {
+	*e = VotingMode(value.(string))
+	return nil
+}
+
+func (e VotingMode) Value() (driver.Value, error) {
+	return string(e), nil
+}
+
+func (e VotingMode) IsValid() bool {
+	switch e {
+	case VotingModeSingle, VotingModeApproval, VotingModeScore:
+		return true
+	}
+	return false
+}
+
+func (e VotingMode) String() string {
+	return string(e)
+}
+
+// CircleResult of a closed circle evaluated by the quorum of the circle
+type CircleResult string
+
+const (
+	CircleResultValid        CircleResult = "VALID"
+	CircleResultInvalid      CircleResult = "INVALID"
+	CircleResultInconclusive CircleResult = "INCONCLUSIVE"
+)
+
+func (e *CircleResult) Scan(value interface{}) error {
+	*e = CircleResult(value.(string))
+	return nil
+}
+
+func (e CircleResult) Value() (driver.Value, error) {
+	return string(e), nil
+}
+
+func (e CircleResult) IsValid() bool {
+	switch e {
+	case CircleResultValid, CircleResultInvalid, CircleResultInconclusive:
+		return true
+	}
+	return false
+}
+
+func (e CircleResult) String() string {
+	return string(e)
+}
+
+// RankingOrder of the rankings of a circle, either by the total
+// score of the candidates or by the average score per ballot.
+type RankingOrder string
+
+const (
+	RankingOrderTotal   RankingOrder = "TOTAL"
+	RankingOrderAverage RankingOrder = "AVERAGE"
+)
+
+func (e *RankingOrder) Scan(value interface{}) error {
+	*e = RankingOrder(value.(string))
+	return nil
+}
+
+func (e RankingOrder) Value() (driver.Value, error) {
+	return string(e), nil
+}
+
+func (e RankingOrder) IsValid() bool {
+	switch e {
+	case RankingOrderTotal, RankingOrderAverage:
+		return true
+	}
+	return false
+}
+
+func (e RankingOrder) String() string {
+	return string(e)
+}
+
 // validation functions +++++++++++++++++++++++++++
 
 // Determines if the circle is still active and not in stage closed.
@@ -131,98 +396,71 @@ func (circle *Circle) IsEditable() bool {
 	return false
 }
 
+// IsCommitmentDeadlinePassed determines whether the commitments of the
+// circle can't be answered anymore
+func (circle *Circle) IsCommitmentDeadlinePassed() bool {
+	return circle.CommitmentDeadline != nil && !circle.CommitmentDeadline.After(time.Now())
+}
+
 // db hooks with checks ++++++++++++++++++++++++++++
 
+// AfterFind evaluates the stage of the circle by the current time.
+// Reading a circle does not write the stage, the stage changes are written
+// and published by the refresh of the circle stages.
 func (circle *Circle) AfterFind(tx *gorm.DB) error {
-	if !circle.IsEditable() {
-		return nil
+	if circle.IsEditable() {
+		circle.Stage = circle.CurrentStage()
 	}
 
-	return updateCircleStage(tx, circle)
+	return nil
 }
 
 func (circle *Circle) AfterCreate(tx *gorm.DB) error {
-	return updateCircleStage(tx, circle)
+	stage := circle.CurrentStage()
+
+	if circle.Stage == stage {
+		return nil
+	}
+
+	return tx.Model(circle).Update("stage", stage).Error
 }
 
-func updateCircleStage(
-	tx *gorm.DB,
-	circle *Circle,
-) error {
+// CurrentStage of the circle evaluated by the current time
+// and the valid range of the circle.
+func (circle *Circle) CurrentStage() CircleStage {
 	currentTime := time.Now().UTC().Truncate(60 * time.Second)
 	validFromTruncatedTime := circle.ValidFrom.UTC().Truncate(60 * time.Second)
 
 	// check if current time is between range of circle
-	// if so, set it to hot stage
+	// if so, it is in hot stage
 	if circle.ValidUntil != nil {
 		validUntilTime := *circle.ValidUntil
 		validUntilTruncatedTime := validUntilTime.UTC().Truncate(60 * time.Second)
 
 		if utils.IsTimeBetween(currentTime, validFromTruncatedTime, validUntilTruncatedTime) {
-			if circle.Stage == CircleStageHot {
-				return nil
-			}
-
-			err := tx.Model(circle).Update("stage", CircleStageHot).Error
-
-			if err != nil {
-				return err
-			}
-			return nil
+			return CircleStageHot
 		}
 
 		// check if current time is after valid until of circle
-		// if so, set it to closed stage
+		// if so, it is in closed stage
 		if currentTime.After(validUntilTruncatedTime) {
-			err := tx.Model(circle).Update("stage", CircleStageClosed).Error
-
-			if err != nil {
-				return err
-			}
-			return nil
+			return CircleStageClosed
 		}
 
 		if currentTime.Before(validFromTruncatedTime) {
-			if circle.Stage == CircleStageCold {
-				return nil
-			}
-
-			err := tx.Model(circle).Update("stage", CircleStageCold).Error
-
-			if err != nil {
-				return err
-			}
-			return nil
+			return CircleStageCold
 		}
 	}
 
 	// check if current time is after valid from of circle
-	// if so, set it to hot stage
+	// if so, it is in hot stage
 	if currentTime.Equal(validFromTruncatedTime) || currentTime.After(validFromTruncatedTime) {
-		if circle.Stage == CircleStageHot {
-			return nil
-		}
-
-		err := tx.Model(circle).Update("stage", CircleStageHot).Error
-
-		if err != nil {
-			return err
-		}
-		return nil
+		return CircleStageHot
 	}
 
 	if currentTime.Before(validFromTruncatedTime) {
-		if circle.Stage == CircleStageCold {
-			return nil
-		}
-
-		err := tx.Model(circle).Update("stage", CircleStageCold).Error
-
-		if err != nil {
-			return err
-		}
-		return nil
+		return CircleStageCold
 	}
 
-	return nil
+	return circle.Stage
 }
@@ -16,6 +16,10 @@ type CircleCandidate struct {
 	CircleID    int64      `json:"circleId" gorm:"not null;"`
 }
 
+// NoneOfTheAboveCandidate is the identity of the pseudo candidate, that
+// voters of a circle with the none of the above option can vote for.
+const NoneOfTheAboveCandidate = "none-of-the-above"
+
 type CircleCandidateResponse struct {
 	CreatedAt  time.Time  `json:"createdAt"`
 	UpdatedAt  time.Time  `json:"updatedAt"`
@@ -49,6 +53,15 @@ type CircleCandidatesRequest struct {
 type CircleCandidateChangedEvent struct {
 	Candidate *CircleCandidateResponse `json:"candidate"`
 	Operation EventOperation           `json:"operation"`
+	Sequence  int64                    `json:"sequence"`
+}
+
+func (e *CircleCandidateChangedEvent) SetSequence(sequence int64) {
+	e.Sequence = sequence
+}
+
+func (e *CircleCandidateChangedEvent) GetSequence() int64 {
+	return e.Sequence
 }
 
 type Commitment string
@@ -0,0 +1,66 @@
+package model
+
+import (
+	"time"
+)
+
+// CircleNomination of a nominee by a voter during the nomination phase of a
+// circle. The first voter nominates the nominee, each further voter seconds
+// the nomination. Once the nomination has the required seconds, the nominee
+// is added as candidate with an open commitment.
+type CircleNomination struct {
+	CreatedAt time.Time `json:"createdAt" gorm:"autoCreateTime;"`
+	UpdatedAt time.Time `json:"updatedAt" gorm:"autoUpdateTime;"`
+	Nominee   string    `json:"nominee" gorm:"type:varchar(50);not null;"`
+	Voter     string    `json:"voter" gorm:"type:varchar(50);not null;"`
+	ID        int64     `json:"id" gorm:"primary_key;index;"`
+	CircleID  int64     `json:"circleId" gorm:"not null;index;"`
+	// Seconded is false for the nomination itself and true for its seconds
+	Seconded bool `json:"seconded" gorm:"not null;default:false;"`
+}
+
+type CircleNominationResponse struct {
+	CreatedAt       time.Time `json:"createdAt"`
+	Nominee         string    `json:"nominee"`
+	NominatedBy     string    `json:"nominatedBy"`
+	SecondedBy      []string  `json:"secondedBy"`
+	SecondsRequired int       `json:"secondsRequired"`
+	// Candidate is true, once the nominee has been added as candidate
+	Candidate bool `json:"candidate"`
+}
+
+// NewCircleNominationResponses of the nominations of a circle, that must be
+// ordered by their creation. The nominations of each nominee are merged
+// into one response in the order the nominees have been nominated.
+func NewCircleNominationResponses(
+	nominations []*CircleNomination,
+	secondsRequired int,
+) []*CircleNominationResponse {
+	responses := make([]*CircleNominationResponse, 0)
+	responsesByNominee := make(map[string]*CircleNominationResponse)
+
+	for _, nomination := range nominations {
+		response, ok := responsesByNominee[nomination.Nominee]
+
+		if !ok {
+			response = &CircleNominationResponse{
+				CreatedAt:       nomination.CreatedAt,
+				Nominee:         nomination.Nominee,
+				SecondedBy:      make([]string, 0),
+				SecondsRequired: secondsRequired,
+			}
+			responsesByNominee[nomination.Nominee] = response
+			responses = append(responses, response)
+		}
+
+		if nomination.Seconded {
+			response.SecondedBy = append(response.SecondedBy, nomination.Voter)
+		} else {
+			response.NominatedBy = nomination.Voter
+		}
+
+		response.Candidate = len(response.SecondedBy) >= secondsRequired
+	}
+
+	return responses
+}
@@ -0,0 +1,43 @@
+package model
+
+import (
+	"time"
+)
+
+// CircleRound of a multi round circle. The circle itself is the first round,
+// each further round is created as a circle of its own with the top
+// candidates and the voters of the previous round, once it closed.
+type CircleRound struct {
+	CreatedAt  time.Time `json:"createdAt" gorm:"autoCreateTime;"`
+	UpdatedAt  time.Time `json:"updatedAt" gorm:"autoUpdateTime;"`
+	ValidFrom  time.Time `json:"validFrom" gorm:"not null;"`
+	ValidUntil time.Time `json:"validUntil" gorm:"not null;"`
+	// RoundCircle is the circle of the round, once the round has been created
+	RoundCircle   *Circle `json:"roundCircle" gorm:"foreignKey:RoundCircleID;constraint:OnDelete:RESTRICT;"`
+	RoundCircleID *int64  `json:"roundCircleId"`
+	ID            int64   `json:"id" gorm:"primary_key;index;"`
+	CircleID      int64   `json:"circleId" gorm:"not null;index;"`
+	// PreviousCircleID is the closed circle of the previous round, only read for pending rounds
+	PreviousCircleID int64 `json:"-" gorm:"->"`
+	// Number of the round, starting with 2 as the circle itself is the first round
+	Number int `json:"number" gorm:"not null;"`
+	// Candidates is the number of the top candidates advancing into the round
+	Candidates int `json:"candidates" gorm:"not null;"`
+}
+
+type CircleRoundRequest struct {
+	ValidFrom  time.Time `json:"validFrom" validate:"required"`
+	ValidUntil time.Time `json:"validUntil" validate:"required"`
+	Candidates int       `json:"candidates" validate:"gte=1,lte=100"`
+}
+
+type CircleRoundResponse struct {
+	ValidFrom  time.Time     `json:"validFrom"`
+	ValidUntil *time.Time    `json:"validUntil"`
+	Stage      *CircleStage  `json:"stage"`
+	Result     *CircleResult `json:"result"`
+	CircleID   *int64        `json:"circleId"`
+	Number     int           `json:"number"`
+	// Candidates advancing into the round, 0 for the first round
+	Candidates int `json:"candidates"`
+}
@@ -0,0 +1,105 @@
+package model
+
+import (
+	"testing"
+)
+
+func TestNewCircleQuorum(t *testing.T) {
+	tests := []struct {
+		name               string
+		quorumTurnout      float64
+		quorumWinnerVotes  int64
+		turnout            float64
+		rankings           []*Ranking
+		expectedResult     CircleResult
+		expectedWinner     int64
+		expectedWinnerDone bool
+	}{
+		{
+			name:               "Test NewCircleQuorum is invalid below the turnout",
+			quorumTurnout:      0.5,
+			turnout:            0.4,
+			rankings:           []*Ranking{{Total: 3, Ballots: 3, Score: 3}},
+			expectedResult:     CircleResultInvalid,
+			expectedWinner:     3,
+			quorumWinnerVotes:  1,
+			expectedWinnerDone: true,
+		},
+		{
+			name:           "Test NewCircleQuorum is inconclusive without rankings",
+			turnout:        1,
+			expectedResult: CircleResultInconclusive,
+		},
+		{
+			name:               "Test NewCircleQuorum is inconclusive below the winner votes",
+			quorumWinnerVotes:  4,
+			turnout:            1,
+			rankings:           []*Ranking{{Total: 3, Ballots: 3, Score: 3}},
+			expectedResult:     CircleResultInconclusive,
+			expectedWinner:     3,
+			expectedWinnerDone: false,
+		},
+		{
+			name:               "Test NewCircleQuorum counts the delegated ballots of two votes of the winner",
+			quorumWinnerVotes:  4,
+			turnout:            1,
+			rankings:           []*Ranking{{Total: 4, Ballots: 4, Score: 4}, {Total: 1, Ballots: 1, Score: 1}},
+			expectedResult:     CircleResultValid,
+			expectedWinner:     4,
+			expectedWinnerDone: true,
+		},
+		{
+			name:               "Test NewCircleQuorum does not count the scores of the winner as ballots",
+			quorumWinnerVotes:  10,
+			turnout:            1,
+			rankings:           []*Ranking{{Total: 10, Ballots: 2, Average: 5, Score: 10}},
+			expectedResult:     CircleResultInconclusive,
+			expectedWinner:     2,
+			expectedWinnerDone: false,
+		},
+		{
+			name:               "Test NewCircleQuorum is inconclusive with a shared first place",
+			turnout:            1,
+			rankings:           []*Ranking{{Total: 2, Ballots: 2, Score: 2}, {Total: 2, Ballots: 2, Score: 2}},
+			expectedResult:     CircleResultInconclusive,
+			expectedWinner:     2,
+			expectedWinnerDone: true,
+		},
+		{
+			name:               "Test NewCircleQuorum is valid with a single winner",
+			quorumTurnout:      0.5,
+			quorumWinnerVotes:  2,
+			turnout:            0.5,
+			rankings:           []*Ranking{{Total: 2, Ballots: 2, Score: 2}, {Total: 1, Ballots: 1, Score: 1}},
+			expectedResult:     CircleResultValid,
+			expectedWinner:     2,
+			expectedWinnerDone: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(
+			tt.name, func(t *testing.T) {
+				result := NewCircleQuorum(
+					tt.quorumTurnout,
+					tt.quorumWinnerVotes,
+					&CircleTurnout{Turnout: tt.turnout},
+					tt.rankings,
+				)
+				if result.Result != tt.expectedResult {
+					t.Errorf("Expected result: %v, but got: %v", tt.expectedResult, result.Result)
+				}
+				if result.WinnerVotes != tt.expectedWinner {
+					t.Errorf("Expected winner votes: %v, but got: %v", tt.expectedWinner, result.WinnerVotes)
+				}
+				if result.WinnerVotesReached != tt.expectedWinnerDone {
+					t.Errorf(
+						"Expected winner votes reached: %v, but got: %v",
+						tt.expectedWinnerDone,
+						result.WinnerVotesReached,
+					)
+				}
+			},
+		)
+	}
+}
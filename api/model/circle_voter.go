@@ -14,6 +14,8 @@ type CircleVoter struct {
 	Commitment  Commitment `json:"commitment" gorm:"type:commitment;not null;default:OPEN"`
 	ID          int64      `json:"id" gorm:"primary_key;"`
 	CircleID    int64      `json:"circleId" gorm:"not null;"`
+	// Abstained voters cast a ballot without voting for any candidate
+	Abstained bool `json:"abstained" gorm:"not null;default:false"`
 }
 
 type CircleVoterResponse struct {
@@ -23,6 +25,7 @@ type CircleVoterResponse struct {
 	Voter      string     `json:"voter"`
 	Commitment Commitment `json:"commitment"`
 	ID         int64      `json:"id"`
+	Abstained  bool       `json:"abstained"`
 }
 
 type CircleVotersResponse struct {
@@ -45,4 +48,13 @@ type CircleVotersRequest struct {
 type CircleVoterChangedEvent struct {
 	Voter     *CircleVoterResponse `json:"voter"`
 	Operation EventOperation       `json:"operation"`
+	Sequence  int64                `json:"sequence"`
+}
+
+func (e *CircleVoterChangedEvent) SetSequence(sequence int64) {
+	e.Sequence = sequence
+}
+
+func (e *CircleVoterChangedEvent) GetSequence() int64 {
+	return e.Sequence
 }
@@ -1,5 +1,7 @@
 package model
 
+import "encoding/json"
+
 type EventOperation string
 
 const (
@@ -7,4 +9,68 @@ const (
 	EventOperationUpdated      EventOperation = "UPDATED"
 	EventOperationDeleted      EventOperation = "DELETED"
 	EventOperationRepositioned EventOperation = "REPOSITIONED"
+	EventOperationStageChanged EventOperation = "STAGE_CHANGED"
 )
+
+type EventKind string
+
+const (
+	EventKindRanking         EventKind = "RANKING"
+	EventKindCircleVoter     EventKind = "CIRCLE_VOTER"
+	EventKindCircleCandidate EventKind = "CIRCLE_CANDIDATE"
+	EventKindCircle          EventKind = "CIRCLE"
+)
+
+// Names of the messages the events are published with
+const (
+	EventNameRankingChanged         = "ranking-changed"
+	EventNameRankingsChanged        = "rankings-changed"
+	EventNameCircleVoterChanged     = "circle-voter-changed"
+	EventNameCircleCandidateChanged = "circle-candidate-changed"
+	EventNameCircleChanged          = "circle-changed"
+	EventNameCirclePresenceChanged  = "circle-presence-changed"
+	EventNameResync                 = "resync"
+)
+
+// EventName of the message, that events of this kind are published with
+func (e EventKind) EventName() string {
+	switch e {
+	case EventKindRanking:
+		return EventNameRankingChanged
+	case EventKindCircleVoter:
+		return EventNameCircleVoterChanged
+	case EventKindCircleCandidate:
+		return EventNameCircleCandidateChanged
+	case EventKindCircle:
+		return EventNameCircleChanged
+	}
+	return ""
+}
+
+// SequencedEvent is an event of a circle, that carries
+// the sequence number of the circle it has been published with.
+// An event without a sequence number has the sequence 0.
+type SequencedEvent interface {
+	SetSequence(sequence int64)
+	GetSequence() int64
+}
+
+// EventLogEntry of a published event of a circle
+type EventLogEntry struct {
+	Event    json.RawMessage `json:"event"`
+	Kind     EventKind       `json:"kind"`
+	Sequence int64           `json:"sequence"`
+}
+
+type EventChangesRequest struct {
+	Since int64 `form:"since" validate:"gte=0"`
+}
+
+// EventChangesResponse contains the events of a circle after a sequence number.
+// If Complete is false, not all events since the sequence number are
+// available anymore and the client must fetch the full state again.
+type EventChangesResponse struct {
+	Events   []*EventLogEntry `json:"events"`
+	Sequence int64            `json:"sequence"`
+	Complete bool             `json:"complete"`
+}
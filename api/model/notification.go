@@ -0,0 +1,187 @@
+package model
+
+import (
+	"database/sql/driver"
+	"github.com/lib/pq"
+	"time"
+)
+
+// Notification of a user in the in-app inbox
+type Notification struct {
+	CreatedAt      time.Time        `json:"createdAt" gorm:"autoCreateTime;"`
+	UpdatedAt      time.Time        `json:"updatedAt" gorm:"autoUpdateTime;"`
+	ReadAt         *time.Time       `json:"readAt"`
+	UserIdentityID string           `json:"userIdentityId" gorm:"type:varchar(50);not null;"`
+	Kind           NotificationKind `json:"kind" gorm:"type:notificationKind;not null;"`
+	Title          string           `json:"title" gorm:"type:varchar(200);not null;"`
+	Message        string           `json:"message" gorm:"type:text;not null;"`
+	// DedupeKey of the occasion of the notification, a user is notified only once per key
+	DedupeKey string `json:"-" gorm:"type:varchar(100);not null;"`
+	ID        int64  `json:"id" gorm:"primary_key;index;"`
+	CircleID  int64  `json:"circleId" gorm:"not null;"`
+}
+
+// NotificationPreference of a user, which kinds of notifications the user
+// receives and the channels they are delivered to besides the inbox.
+// The email notifications are only delivered, after the email has been verified
+// with the token, whose SHA-256 is kept as the email verification hash.
+type NotificationPreference struct {
+	CreatedAt                  time.Time      `json:"createdAt" gorm:"autoCreateTime;"`
+	UpdatedAt                  time.Time      `json:"updatedAt" gorm:"autoUpdateTime;"`
+	EmailVerifiedAt            *time.Time     `json:"emailVerifiedAt"`
+	EmailVerificationExpiresAt *time.Time     `json:"-"`
+	UserIdentityID             string         `json:"userIdentityId" gorm:"type:varchar(50);not null;unique;"`
+	Kinds                      pq.StringArray `json:"kinds" gorm:"type:text[];not null;"`
+	Email                      string         `json:"email" gorm:"type:varchar(320);not null;default:''"`
+	EmailVerificationHash      string         `json:"-" gorm:"type:varchar(64);not null;default:''"`
+	WebhookURL                 string         `json:"webhookUrl" gorm:"type:text;not null;default:''"`
+	WebhookSecret              string         `json:"-" gorm:"type:varchar(64);not null;default:''"`
+	ID                         int64          `json:"id" gorm:"primary_key;index;"`
+	EmailEnabled               bool           `json:"emailEnabled" gorm:"not null;default:false"`
+	WebhookEnabled             bool           `json:"webhookEnabled" gorm:"not null;default:false"`
+}
+
+// EmailVerified determines if the current email of the preference has been verified
+func (p *NotificationPreference) EmailVerified() bool {
+	return p.Email != "" && p.EmailVerifiedAt != nil
+}
+
+// NotificationDelivery of a notification to a channel of the user
+type NotificationDelivery struct {
+	CreatedAt      time.Time                  `json:"createdAt" gorm:"autoCreateTime;"`
+	UpdatedAt      time.Time                  `json:"updatedAt" gorm:"autoUpdateTime;"`
+	NextAttemptAt  time.Time                  `json:"nextAttemptAt" gorm:"not null;"`
+	DeliveredAt    *time.Time                 `json:"deliveredAt"`
+	Notification   *Notification              `json:"notification" gorm:"constraint:OnDelete:CASCADE;"`
+	Channel        NotificationChannel        `json:"channel" gorm:"type:varchar(20);not null;"`
+	Status         NotificationDeliveryStatus `json:"status" gorm:"type:notificationDeliveryStatus;not null;default:PENDING"`
+	LastError      string                     `json:"lastError" gorm:"type:text;not null;default:''"`
+	ID             int64                      `json:"id" gorm:"primary_key;index;"`
+	NotificationID int64                      `json:"notificationId" gorm:"not null;"`
+	Attempts       int                        `json:"attempts" gorm:"not null;default:0"`
+}
+
+type NotificationUriRequest struct {
+	NotificationID int64 `uri:"notificationId" validate:"gt=0"`
+}
+
+type NotificationsRequest struct {
+	Unread bool `form:"unread"`
+}
+
+type NotificationPreferenceUpdateRequest struct {
+	Kinds          []NotificationKind `json:"kinds" validate:"lte=5,unique,dive,oneof=COMMITMENT_PENDING CIRCLE_HOT CIRCLE_CLOSING_SOON CIRCLE_RESULTS COMMITMENT_DEADLINE_SOON"`
+	Email          *string            `json:"email,omitempty" validate:"omitempty,email,lte=320"`
+	EmailEnabled   *bool              `json:"emailEnabled,omitempty" validate:"omitempty"`
+	WebhookURL     *string            `json:"webhookUrl,omitempty" validate:"omitempty,http_url,lte=2000"`
+	WebhookEnabled *bool              `json:"webhookEnabled,omitempty" validate:"omitempty"`
+}
+
+type NotificationEmailVerifyRequest struct {
+	Token string `json:"token" validate:"len=64,hexadecimal"`
+}
+
+type NotificationResponse struct {
+	CreatedAt time.Time        `json:"createdAt"`
+	ReadAt    *time.Time       `json:"readAt"`
+	Kind      NotificationKind `json:"kind"`
+	Title     string           `json:"title"`
+	Message   string           `json:"message"`
+	ID        int64            `json:"id"`
+	CircleID  int64            `json:"circleId"`
+}
+
+type NotificationsResponse struct {
+	Notifications []*NotificationResponse `json:"notifications"`
+	Unread        int64                   `json:"unread"`
+}
+
+// NotificationPreferenceResponse of the user. The webhook secret is returned
+// to the user, so that the requests to the webhook can be verified.
+type NotificationPreferenceResponse struct {
+	Kinds          []NotificationKind `json:"kinds"`
+	Email          string             `json:"email"`
+	WebhookURL     string             `json:"webhookUrl"`
+	WebhookSecret  string             `json:"webhookSecret,omitempty"`
+	EmailEnabled   bool               `json:"emailEnabled"`
+	EmailVerified  bool               `json:"emailVerified"`
+	WebhookEnabled bool               `json:"webhookEnabled"`
+}
+
+type NotificationKind string
+
+const (
+	NotificationKindCommitmentPending NotificationKind = "COMMITMENT_PENDING"
+	NotificationKindCircleHot         NotificationKind = "CIRCLE_HOT"
+	NotificationKindCircleClosingSoon NotificationKind = "CIRCLE_CLOSING_SOON"
+	NotificationKindCircleResults     NotificationKind = "CIRCLE_RESULTS"
+	// NotificationKindCommitmentDeadlineSoon warns the creator of a circle,
+	// that open commitments will be rejected at the commitment deadline
+	NotificationKindCommitmentDeadlineSoon NotificationKind = "COMMITMENT_DEADLINE_SOON"
+)
+
+var AllNotificationKind = []NotificationKind{
+	NotificationKindCommitmentPending,
+	NotificationKindCircleHot,
+	NotificationKindCircleClosingSoon,
+	NotificationKindCircleResults,
+	NotificationKindCommitmentDeadlineSoon,
+}
+
+func (e *NotificationKind) Scan(value interface{}) error {
+	*e = NotificationKind(value.(string))
+	return nil
+}
+
+func (e NotificationKind) Value() (driver.Value, error) {
+	return string(e), nil
+}
+
+func (e NotificationKind) IsValid() bool {
+	switch e {
+	case NotificationKindCommitmentPending, NotificationKindCircleHot, NotificationKindCircleClosingSoon, NotificationKindCircleResults,
+		NotificationKindCommitmentDeadlineSoon:
+		return true
+	}
+	return false
+}
+
+func (e NotificationKind) String() string {
+	return string(e)
+}
+
+type NotificationChannel string
+
+const (
+	NotificationChannelEmail   NotificationChannel = "EMAIL"
+	NotificationChannelWebhook NotificationChannel = "WEBHOOK"
+)
+
+type NotificationDeliveryStatus string
+
+const (
+	NotificationDeliveryStatusPending   NotificationDeliveryStatus = "PENDING"
+	NotificationDeliveryStatusDelivered NotificationDeliveryStatus = "DELIVERED"
+	NotificationDeliveryStatusDead      NotificationDeliveryStatus = "DEAD"
+)
+
+func (e *NotificationDeliveryStatus) Scan(value interface{}) error {
+	*e = NotificationDeliveryStatus(value.(string))
+	return nil
+}
+
+func (e NotificationDeliveryStatus) Value() (driver.Value, error) {
+	return string(e), nil
+}
+
+func (e NotificationDeliveryStatus) IsValid() bool {
+	switch e {
+	case NotificationDeliveryStatusPending, NotificationDeliveryStatusDelivered, NotificationDeliveryStatusDead:
+		return true
+	}
+	return false
+}
+
+func (e NotificationDeliveryStatus) String() string {
+	return string(e)
+}
@@ -0,0 +1,132 @@
+package model
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"time"
+)
+
+// OutboxEvent is an event of a circle, that is written in the same
+// transaction as the change it originates from and is published
+// asynchronously by the outbox dispatcher.
+type OutboxEvent struct {
+	CreatedAt     time.Time    `json:"createdAt" gorm:"autoCreateTime;"`
+	UpdatedAt     time.Time    `json:"updatedAt" gorm:"autoUpdateTime;"`
+	NextAttemptAt time.Time    `json:"nextAttemptAt" gorm:"not null;"`
+	Kind          EventKind    `json:"kind" gorm:"type:varchar(40);not null;"`
+	Payload       string       `json:"payload" gorm:"type:jsonb;not null;"`
+	Status        OutboxStatus `json:"status" gorm:"type:outboxStatus;not null;default:PENDING"`
+	LastError     string       `json:"lastError" gorm:"type:text;not null;default:''"`
+	ID            int64        `json:"id" gorm:"primary_key;index;"`
+	CircleID      int64        `json:"circleId" gorm:"not null;index;"`
+	Attempts      int          `json:"attempts" gorm:"not null;default:0"`
+	// Published to the subscribers of the circle, a retry of the event
+	// only creates the pending webhook deliveries and notifications.
+	Published bool `json:"published" gorm:"not null;default:false"`
+}
+
+type OutboxEventResponse struct {
+	CreatedAt     time.Time       `json:"createdAt"`
+	UpdatedAt     time.Time       `json:"updatedAt"`
+	NextAttemptAt time.Time       `json:"nextAttemptAt"`
+	Kind          EventKind       `json:"kind"`
+	Payload       json.RawMessage `json:"payload"`
+	Status        OutboxStatus    `json:"status"`
+	LastError     string          `json:"lastError"`
+	ID            int64           `json:"id"`
+	CircleID      int64           `json:"circleId"`
+	Attempts      int             `json:"attempts"`
+}
+
+// OutboxEventsCallback creates the outbox events of a change, that
+// will be written in the same transaction as the change itself.
+type OutboxEventsCallback func() ([]*OutboxEvent, error)
+
+// RankingOutboxEventsCallback creates the outbox events of a vote change,
+// based on the updated ranking and the vote count of the candidate, that
+// will be written in the same transaction as the vote change itself.
+// The rankings callback reads the rankings of the circle in that transaction.
+type RankingOutboxEventsCallback func(
+	ranking *RankingResponse,
+	voteCount int64,
+	rankings RankingsCallback,
+) ([]*OutboxEvent, error)
+
+// RankingChange of the ranking of a candidate, whose vote count changed.
+// A vote count of 0 means the ranking has been removed.
+type RankingChange struct {
+	Candidate *CircleCandidate
+	Ranking   *RankingResponse
+	VoteCount int64
+}
+
+// RankingChangesOutboxEventsCallback creates the outbox events of a vote change,
+// that changed the rankings of several candidates at once, e.g. an approval vote.
+// It will be written in the same transaction as the vote change itself.
+// The rankings callback reads the rankings of the circle in that transaction.
+type RankingChangesOutboxEventsCallback func(
+	changes []*RankingChange,
+	rankings RankingsCallback,
+) ([]*OutboxEvent, error)
+
+// RejectedCommitmentsOutboxEventsCallback creates the outbox events of the
+// candidates and voters, whose open commitments have been rejected at the
+// commitment deadline. It will be written in the same transaction as the
+// rejection itself.
+type RejectedCommitmentsOutboxEventsCallback func(
+	candidates []*CircleCandidate,
+	voters []*CircleVoter,
+) ([]*OutboxEvent, error)
+
+// RankingsCallback reads the persisted rankings of a circle
+type RankingsCallback func() ([]*Ranking, error)
+
+// NewOutboxEvent of the given kind for the circle with the payload encoded as JSON
+func NewOutboxEvent(
+	circleId int64,
+	kind EventKind,
+	payload interface{},
+) (*OutboxEvent, error) {
+	encodedPayload, err := json.Marshal(payload)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &OutboxEvent{
+		NextAttemptAt: time.Now(),
+		Kind:          kind,
+		Payload:       string(encodedPayload),
+		Status:        OutboxStatusPending,
+		CircleID:      circleId,
+	}, nil
+}
+
+type OutboxStatus string
+
+const (
+	OutboxStatusPending   OutboxStatus = "PENDING"
+	OutboxStatusDelivered OutboxStatus = "DELIVERED"
+	OutboxStatusDead      OutboxStatus = "DEAD"
+)
+
+func (e *OutboxStatus) Scan(value interface{}) error {
+	*e = OutboxStatus(value.(string))
+	return nil
+}
+
+func (e OutboxStatus) Value() (driver.Value, error) {
+	return string(e), nil
+}
+
+func (e OutboxStatus) IsValid() bool {
+	switch e {
+	case OutboxStatusPending, OutboxStatusDelivered, OutboxStatusDead:
+		return true
+	}
+	return false
+}
+
+func (e OutboxStatus) String() string {
+	return string(e)
+}
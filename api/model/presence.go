@@ -0,0 +1,16 @@
+package model
+
+// CirclePresenceResponse of the current viewers of a circle.
+// The identities of the viewers are hidden for private circles.
+type CirclePresenceResponse struct {
+	Viewers  []string `json:"viewers,omitempty"`
+	CircleID int64    `json:"circleId"`
+	Count    int64    `json:"count"`
+}
+
+// CirclePresenceChangedEvent is published, if the count of the viewers changed.
+// It is not part of the event log, as it is not a change of the circle.
+type CirclePresenceChangedEvent struct {
+	CircleID int64 `json:"circleId"`
+	Count    int64 `json:"count"`
+}
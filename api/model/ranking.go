@@ -15,8 +15,26 @@ type Ranking struct {
 	Placement  Placement `json:"placement" gorm:"type:placement;not null;default:NEUTRAL"`
 	ID         int64     `json:"id" gorm:"primary_key;index;"`
 	Number     int64     `json:"number" gorm:"primary_key;index;"`
-	Votes      int64     `json:"votes" gorm:"not null;default:0"`
-	CircleID   int64     `json:"circleId" gorm:"not null;"`
+	Total      int64     `json:"total" gorm:"not null;default:0"`
+	Ballots    int64     `json:"ballots" gorm:"not null;default:0"`
+	Average    float64   `json:"average" gorm:"not null;default:0"`
+	// Score the rankings are ordered by, either the total or the
+	// average depending on the ranking order of the circle.
+	Score    float64 `json:"score" gorm:"not null;default:0"`
+	CircleID int64   `json:"circleId" gorm:"not null;"`
+}
+
+// SetTally of the votes of the candidate with the score
+// of the given ranking order.
+func (r *Ranking) SetTally(tally *RankingTally, order RankingOrder) {
+	r.Total = tally.Total
+	r.Ballots = tally.Ballots
+	r.Average = tally.Average()
+	r.Score = float64(tally.Total)
+
+	if order == RankingOrderAverage {
+		r.Score = r.Average
+	}
 }
 
 type RankingResponse struct {
@@ -27,9 +45,16 @@ type RankingResponse struct {
 	ID           int64     `json:"id"`
 	CandidateID  int64     `json:"candidateId"`
 	Number       int64     `json:"number"`
-	Votes        int64     `json:"votes"`
+	Total        int64     `json:"total"`
+	Average      float64   `json:"average"`
+	Ballots      int64     `json:"ballots"`
 	IndexedOrder int64     `json:"indexedOrder"`
 	CircleID     int64     `json:"circleId"`
+	// NoneOfTheAbove marks the ranking of the none of the above pseudo candidate
+	NoneOfTheAbove bool `json:"noneOfTheAbove"`
+	// Score the ranking is ordered by, only used to continue
+	// a ranking list from this ranking.
+	Score float64 `json:"-"`
 }
 
 type RankingsUriRequest struct {
@@ -37,8 +62,8 @@ type RankingsUriRequest struct {
 }
 
 type RankingScore struct {
-	UserIdentityId string `redis:"userIdentityId"`
-	VoteCount      int64  `redis:"voteCount"`
+	UserIdentityId string  `redis:"userIdentityId"`
+	Score          float64 `redis:"score"`
 }
 
 type RankingUserCandidate struct {
@@ -46,17 +71,54 @@ type RankingUserCandidate struct {
 	UpdatedAt   time.Time `redis:"time"`
 	CandidateID int64     `redis:"candidateId"`
 	RankingID   int64     `redis:"rankingId"`
+	Total       int64     `redis:"total"`
+	Ballots     int64     `redis:"ballots"`
 }
 
+// RankingCacheItem of a candidate with the persisted ranking,
+// that contains the tally of the votes of the candidate.
 type RankingCacheItem struct {
 	Ranking   *Ranking
 	Candidate *CircleCandidate
-	VoteCount int64
+}
+
+// RankingTally of the votes of a candidate. The total is the sum of the
+// scores of all ballots, in single and approval circles each ballot scores 1.
+// A ballot counts with the weight of the votes delegated to the voter.
+type RankingTally struct {
+	Total   int64
+	Ballots int64
+}
+
+// Average score per ballot, 0 if there are no ballots
+func (t *RankingTally) Average() float64 {
+	if t.Ballots == 0 {
+		return 0
+	}
+
+	return float64(t.Total) / float64(t.Ballots)
 }
 
 type RankingChangedEvent struct {
 	Ranking   *RankingResponse `json:"ranking"`
 	Operation EventOperation   `json:"operation"`
+	Sequence  int64            `json:"sequence"`
+}
+
+func (e *RankingChangedEvent) SetSequence(sequence int64) {
+	e.Sequence = sequence
+}
+
+func (e *RankingChangedEvent) GetSequence() int64 {
+	return e.Sequence
+}
+
+// RankingsChangedEvent is the compacted message of the ranking changes
+// of a circle within the coalescing window. The sequence is the sequence
+// of the last event.
+type RankingsChangedEvent struct {
+	Events   []*RankingChangedEvent `json:"events"`
+	Sequence int64                  `json:"sequence"`
 }
 
 func (s RankingScore) MarshalBinary() ([]byte, error) {
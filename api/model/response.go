@@ -2,6 +2,7 @@ package model
 
 type Response struct {
 	Data   interface{}    `json:"data"`
+	Meta   interface{}    `json:"meta,omitempty"`
 	Status ResponseStatus `json:"status"`
 	Msg    string         `json:"msg"`
 }
@@ -0,0 +1,18 @@
+package model
+
+import "encoding/json"
+
+// CircleStreamEvent is an event of a circle, that is streamed to the clients.
+// Events without a sequence number are not part of the event log and
+// cannot be replayed.
+type CircleStreamEvent struct {
+	Name     string
+	Data     json.RawMessage
+	Sequence int64
+}
+
+// CircleResyncEvent is streamed, if not all missed events of the circle
+// could be replayed. The client must fetch the full state of the circle again.
+type CircleResyncEvent struct {
+	Sequence int64 `json:"sequence"`
+}
@@ -0,0 +1,7 @@
+package model
+
+// AblyTokenRequest contains the circles the user wants to watch in addition
+// to the circles of the user, e.g. public circles the user is visiting.
+type AblyTokenRequest struct {
+	CircleIDs []int64 `json:"circleIds" form:"circleId" validate:"lte=50,dive,gt=0"`
+}
@@ -8,21 +8,109 @@ type UserOption struct {
 	IdentityID           string              `json:"identityId" gorm:"type:varchar(50);not null"`
 	Package              SubscriptionPackage `json:"package" gorm:"type:subscriptionPackage;not null;default:S"`
 	ID                   int64               `json:"id" gorm:"primary_key;index;"`
-	MaxCircles           int                 `json:"maxCircles" gorm:"not null"`
-	MaxVoters            int                 `json:"maxVoters" gorm:"not null"`
-	MaxCandidates        int                 `json:"maxCandidates" gorm:"not null"`
-	MaxPrivateVoters     int                 `json:"maxPrivateVoters" gorm:"not null"`
-	MaxPrivateCandidates int                 `json:"maxPrivateCandidates" gorm:"not null"`
+	MaxCircles           int                 `json:"maxCircles" gorm:"not null;default:0"`
+	MaxVoters            int                 `json:"maxVoters" gorm:"not null;default:0"`
+	MaxCandidates        int                 `json:"maxCandidates" gorm:"not null;default:0"`
+	MaxPrivateVoters     int                 `json:"maxPrivateVoters" gorm:"not null;default:0"`
+	MaxPrivateCandidates int                 `json:"maxPrivateCandidates" gorm:"not null;default:0"`
+	MaxImageUploads      int                 `json:"maxImageUploads" gorm:"not null;default:0"`
+	MaxWebhooks          int                 `json:"maxWebhooks" gorm:"not null;default:0"`
 }
 
 type UserOptionResponse struct {
-	MaxCircles    int                       `json:"maxCircles"`
-	MaxVoters     int                       `json:"maxVoters"`
-	MaxCandidates int                       `json:"maxCandidates"`
-	PrivateOption UserPrivateOptionResponse `json:"privateOption"`
+	Package         SubscriptionPackage       `json:"package"`
+	MaxCircles      int                       `json:"maxCircles"`
+	MaxVoters       int                       `json:"maxVoters"`
+	MaxCandidates   int                       `json:"maxCandidates"`
+	MaxImageUploads int                       `json:"maxImageUploads"`
+	MaxWebhooks     int                       `json:"maxWebhooks"`
+	PrivateOption   UserPrivateOptionResponse `json:"privateOption"`
 }
 
 type UserPrivateOptionResponse struct {
 	MaxVoters     int `json:"maxVoters"`
 	MaxCandidates int `json:"maxCandidates"`
 }
+
+// CircleMaxVoters of the option for a private or public circle
+func (o *UserOptionResponse) CircleMaxVoters(private bool) int {
+	if private {
+		return o.PrivateOption.MaxVoters
+	}
+	return o.MaxVoters
+}
+
+// CircleMaxCandidates of the option for a private or public circle
+func (o *UserOptionResponse) CircleMaxCandidates(private bool) int {
+	if private {
+		return o.PrivateOption.MaxCandidates
+	}
+	return o.MaxCandidates
+}
+
+// CircleUsage of the voters, candidates and webhooks of a circle
+type CircleUsage struct {
+	Name       string `json:"name"`
+	ID         int64  `json:"id"`
+	Voters     int64  `json:"voters"`
+	Candidates int64  `json:"candidates"`
+	Webhooks   int64  `json:"webhooks"`
+	Private    bool   `json:"private"`
+	Image      bool   `json:"image"`
+}
+
+type CircleUsageResponse struct {
+	Name          string `json:"name"`
+	CircleID      int64  `json:"circleId"`
+	Voters        int64  `json:"voters"`
+	MaxVoters     int    `json:"maxVoters"`
+	Candidates    int64  `json:"candidates"`
+	MaxCandidates int    `json:"maxCandidates"`
+	Webhooks      int64  `json:"webhooks"`
+	MaxWebhooks   int    `json:"maxWebhooks"`
+	Private       bool   `json:"private"`
+}
+
+// UserOptionUsageResponse of the quotas of the option of the user,
+// used by the circles the user owns
+type UserOptionUsageResponse struct {
+	Option       *UserOptionResponse    `json:"option"`
+	Circles      int64                  `json:"circles"`
+	ImageUploads int64                  `json:"imageUploads"`
+	CircleUsages []*CircleUsageResponse `json:"circleUsages"`
+}
+
+// UserOptionAudit records the change of a field of the option of a user by an admin
+type UserOptionAudit struct {
+	CreatedAt  time.Time `json:"createdAt" gorm:"autoCreateTime;"`
+	IdentityID string    `json:"identityId" gorm:"type:varchar(50);not null;index"`
+	ChangedBy  string    `json:"changedBy" gorm:"type:varchar(50);not null"`
+	Field      string    `json:"field" gorm:"type:varchar(40);not null"`
+	Previous   string    `json:"previous" gorm:"type:varchar(20);not null"`
+	Current    string    `json:"current" gorm:"type:varchar(20);not null"`
+	ID         int64     `json:"id" gorm:"primary_key;"`
+}
+
+type UserOptionUriRequest struct {
+	IdentityID string `uri:"identityId" validate:"gt=0,lte=50"`
+}
+
+// UserOptionUpdateRequest of the package and the limits of a user, that raise
+// the quotas of the plan of the package. A limit of 0 resets the override.
+type UserOptionUpdateRequest struct {
+	Package              *SubscriptionPackage `json:"package,omitempty" validate:"omitempty,oneof=S M L"`
+	MaxCircles           *int                 `json:"maxCircles,omitempty" validate:"omitempty,gte=0,lte=1000"`
+	MaxVoters            *int                 `json:"maxVoters,omitempty" validate:"omitempty,gte=0,lte=100000"`
+	MaxCandidates        *int                 `json:"maxCandidates,omitempty" validate:"omitempty,gte=0,lte=10000"`
+	MaxPrivateVoters     *int                 `json:"maxPrivateVoters,omitempty" validate:"omitempty,gte=0,lte=100000"`
+	MaxPrivateCandidates *int                 `json:"maxPrivateCandidates,omitempty" validate:"omitempty,gte=0,lte=10000"`
+	MaxImageUploads      *int                 `json:"maxImageUploads,omitempty" validate:"omitempty,gte=0,lte=1000"`
+	MaxWebhooks          *int                 `json:"maxWebhooks,omitempty" validate:"omitempty,gte=0,lte=100"`
+}
+
+// AdminUserOptionResponse of the stored option of a user with the
+// resulting quotas, that are enforced for the user
+type AdminUserOptionResponse struct {
+	Overrides *UserOption         `json:"overrides"`
+	Option    *UserOptionResponse `json:"option"`
+}
@@ -16,8 +16,29 @@ type Vote struct {
 	VoterRefer     int64            `json:"voterRefer"`
 	CandidateRefer int64            `json:"candidateRefer"`
 	CircleID       int64            `json:"circleId" gorm:"not null;"`
+	// Score of the candidate, in single and approval circles each vote scores 1
+	Score int64 `json:"score" gorm:"type:smallint;not null;"`
+	// Weight of the vote, the voter itself and all voters that delegated to the voter
+	Weight int64 `json:"weight" gorm:"not null;default:1"`
 }
 
+// VoteCreateRequest votes for the candidate or abstains from voting
 type VoteCreateRequest struct {
+	CandidateID string `json:"candidateId" validate:"required_unless=Abstain true,lte=50"`
+	Abstain     bool   `json:"abstain"`
+}
+
+// ApprovalVoteCreateRequest approves the given candidates in an approval circle
+type ApprovalVoteCreateRequest struct {
+	CandidateIDs []string `json:"candidateIds" validate:"gt=0,lte=100,unique,dive,gt=0,lte=50"`
+}
+
+// ScoreVoteCreateRequest scores the given candidates in a score circle
+type ScoreVoteCreateRequest struct {
+	Scores []CandidateScoreRequest `json:"scores" validate:"gt=0,lte=100,unique=CandidateID,dive"`
+}
+
+type CandidateScoreRequest struct {
 	CandidateID string `json:"candidateId" validate:"gt=0,lte=50"`
+	Score       int64  `json:"score" validate:"gte=0,lte=5"`
 }
@@ -0,0 +1,111 @@
+package model
+
+import (
+	"errors"
+	"time"
+)
+
+var (
+	ErrDelegationCycle = errors.New("delegation cycle")
+)
+
+// VoteDelegation of a voter to another voter of the same circle. The vote of the
+// delegate counts with the weight of all voters, that delegated to the delegate
+// directly or transitively, unless a delegator votes or abstains directly.
+type VoteDelegation struct {
+	CreatedAt      time.Time    `json:"createdAt" gorm:"autoCreateTime;"`
+	UpdatedAt      time.Time    `json:"updatedAt" gorm:"autoUpdateTime;"`
+	Delegator      *CircleVoter `json:"delegator" gorm:"foreignKey:DelegatorRefer;constraint:OnDelete:RESTRICT;"`
+	Delegate       *CircleVoter `json:"delegate" gorm:"foreignKey:DelegateRefer;constraint:OnDelete:RESTRICT;"`
+	ID             int64        `json:"id" gorm:"primary_key;index;"`
+	DelegatorRefer int64        `json:"delegatorRefer" gorm:"not null;uniqueIndex;"`
+	DelegateRefer  int64        `json:"delegateRefer" gorm:"not null;"`
+	CircleID       int64        `json:"circleId" gorm:"not null;index;"`
+}
+
+type VoteDelegationCreateRequest struct {
+	Delegate string `json:"delegate" validate:"gt=0,lte=50"`
+}
+
+type VoteDelegationResponse struct {
+	CreatedAt time.Time `json:"createdAt"`
+	UpdatedAt time.Time `json:"updatedAt"`
+	Delegator string    `json:"delegator"`
+	Delegate  string    `json:"delegate"`
+	ID        int64     `json:"id"`
+	CircleID  int64     `json:"circleId"`
+}
+
+// DelegatedWeights of the voters of a circle, that voted directly. The weight
+// of each voter, that neither voted nor abstained, is passed along the chain of
+// delegations to the first delegate, that voted. If the chain ends at a voter
+// without a delegation or at an abstained voter, the weight is not counted.
+// The weight of a voter contains the own vote.
+func DelegatedWeights(
+	delegations map[int64]int64,
+	voted map[int64]bool,
+	abstained map[int64]bool,
+) map[int64]int64 {
+	weights := make(map[int64]int64, len(voted))
+
+	for voterId := range voted {
+		weights[voterId] = 1
+	}
+
+	for delegatorId, delegateId := range delegations {
+		if voted[delegatorId] || abstained[delegatorId] {
+			continue
+		}
+
+		visited := map[int64]bool{delegatorId: true}
+
+		for !visited[delegateId] {
+			if voted[delegateId] {
+				weights[delegateId]++
+				break
+			}
+
+			if abstained[delegateId] {
+				break
+			}
+
+			visited[delegateId] = true
+			next, ok := delegations[delegateId]
+
+			if !ok {
+				break
+			}
+
+			delegateId = next
+		}
+	}
+
+	return weights
+}
+
+// IsDelegationCycle determines if the delegation of the delegator to the
+// delegate would close a cycle with the given delegations.
+func IsDelegationCycle(
+	delegations map[int64]int64,
+	delegatorId int64,
+	delegateId int64,
+) bool {
+	visited := make(map[int64]bool, len(delegations))
+
+	for delegateId != delegatorId {
+		if visited[delegateId] {
+			return false
+		}
+
+		visited[delegateId] = true
+		next, ok := delegations[delegateId]
+
+		if !ok {
+			return false
+		}
+
+		delegateId = next
+	}
+
+	return true
+}
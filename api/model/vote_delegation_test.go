@@ -0,0 +1,139 @@
+package model
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestDelegatedWeights(t *testing.T) {
+	tests := []struct {
+		name        string
+		delegations map[int64]int64
+		voted       map[int64]bool
+		abstained   map[int64]bool
+		expected    map[int64]int64
+	}{
+		{
+			name:     "Test DelegatedWeights without delegations",
+			voted:    map[int64]bool{1: true, 2: true},
+			expected: map[int64]int64{1: 1, 2: 1},
+		},
+		{
+			name:        "Test DelegatedWeights passes the weight to the delegate",
+			delegations: map[int64]int64{2: 1, 3: 1},
+			voted:       map[int64]bool{1: true},
+			expected:    map[int64]int64{1: 3},
+		},
+		{
+			name:        "Test DelegatedWeights passes the weight along the chain",
+			delegations: map[int64]int64{3: 2, 2: 1},
+			voted:       map[int64]bool{1: true},
+			expected:    map[int64]int64{1: 3},
+		},
+		{
+			name:        "Test DelegatedWeights ignores the delegation of a voter, that voted",
+			delegations: map[int64]int64{2: 1},
+			voted:       map[int64]bool{1: true, 2: true},
+			expected:    map[int64]int64{1: 1, 2: 1},
+		},
+		{
+			name:        "Test DelegatedWeights ignores the delegation of a voter, that abstained",
+			delegations: map[int64]int64{2: 1},
+			voted:       map[int64]bool{1: true},
+			abstained:   map[int64]bool{2: true},
+			expected:    map[int64]int64{1: 1},
+		},
+		{
+			name:        "Test DelegatedWeights drops the weight at an abstained delegate",
+			delegations: map[int64]int64{3: 2, 2: 1},
+			voted:       map[int64]bool{1: true},
+			abstained:   map[int64]bool{2: true},
+			expected:    map[int64]int64{1: 1},
+		},
+		{
+			name:        "Test DelegatedWeights drops the weight at the end of the chain",
+			delegations: map[int64]int64{3: 2},
+			voted:       map[int64]bool{1: true},
+			expected:    map[int64]int64{1: 1},
+		},
+		{
+			name:        "Test DelegatedWeights drops the weight in a cycle",
+			delegations: map[int64]int64{2: 3, 3: 2},
+			voted:       map[int64]bool{1: true},
+			expected:    map[int64]int64{1: 1},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(
+			tt.name, func(t *testing.T) {
+				result := DelegatedWeights(tt.delegations, tt.voted, tt.abstained)
+				if !reflect.DeepEqual(result, tt.expected) {
+					t.Errorf("Expected: %v, but got: %v", tt.expected, result)
+				}
+			},
+		)
+	}
+}
+
+func TestIsDelegationCycle(t *testing.T) {
+	tests := []struct {
+		name        string
+		delegations map[int64]int64
+		delegatorId int64
+		delegateId  int64
+		expected    bool
+	}{
+		{
+			name:        "Test IsDelegationCycle without delegations",
+			delegatorId: 1,
+			delegateId:  2,
+			expected:    false,
+		},
+		{
+			name:        "Test IsDelegationCycle with a delegation to oneself",
+			delegatorId: 1,
+			delegateId:  1,
+			expected:    true,
+		},
+		{
+			name:        "Test IsDelegationCycle with a direct cycle",
+			delegations: map[int64]int64{2: 1},
+			delegatorId: 1,
+			delegateId:  2,
+			expected:    true,
+		},
+		{
+			name:        "Test IsDelegationCycle with a cycle along the chain",
+			delegations: map[int64]int64{2: 3, 3: 1},
+			delegatorId: 1,
+			delegateId:  2,
+			expected:    true,
+		},
+		{
+			name:        "Test IsDelegationCycle with a chain without the delegator",
+			delegations: map[int64]int64{2: 3, 3: 4},
+			delegatorId: 1,
+			delegateId:  2,
+			expected:    false,
+		},
+		{
+			name:        "Test IsDelegationCycle with an existing cycle without the delegator",
+			delegations: map[int64]int64{2: 3, 3: 2},
+			delegatorId: 1,
+			delegateId:  2,
+			expected:    false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(
+			tt.name, func(t *testing.T) {
+				result := IsDelegationCycle(tt.delegations, tt.delegatorId, tt.delegateId)
+				if result != tt.expected {
+					t.Errorf("Expected: %v, but got: %v", tt.expected, result)
+				}
+			},
+		)
+	}
+}
@@ -0,0 +1,139 @@
+package model
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"github.com/lib/pq"
+	"time"
+)
+
+// Webhook of a circle, that receives the subscribed events of the circle
+type Webhook struct {
+	CreatedAt   time.Time      `json:"createdAt" gorm:"autoCreateTime;"`
+	UpdatedAt   time.Time      `json:"updatedAt" gorm:"autoUpdateTime;"`
+	URL         string         `json:"url" gorm:"type:text;not null;"`
+	Secret      string         `json:"-" gorm:"type:varchar(64);not null;"`
+	CreatedFrom string         `json:"createdFrom" gorm:"type:varchar(50);not null"`
+	Events      pq.StringArray `json:"events" gorm:"type:text[];not null;"`
+	ID          int64          `json:"id" gorm:"primary_key;index;"`
+	CircleID    int64          `json:"circleId" gorm:"not null;index;"`
+	Active      bool           `json:"active" gorm:"not null;default:true;"`
+}
+
+// WebhookDelivery of an event to a webhook
+type WebhookDelivery struct {
+	CreatedAt     time.Time             `json:"createdAt" gorm:"autoCreateTime;"`
+	UpdatedAt     time.Time             `json:"updatedAt" gorm:"autoUpdateTime;"`
+	NextAttemptAt time.Time             `json:"nextAttemptAt" gorm:"not null;"`
+	DeliveredAt   *time.Time            `json:"deliveredAt"`
+	Webhook       *Webhook              `json:"webhook" gorm:"constraint:OnDelete:CASCADE;"`
+	Event         WebhookEvent          `json:"event" gorm:"type:varchar(40);not null;"`
+	Payload       string                `json:"payload" gorm:"type:jsonb;not null;"`
+	Status        WebhookDeliveryStatus `json:"status" gorm:"type:webhookDeliveryStatus;not null;default:PENDING"`
+	LastError     string                `json:"lastError" gorm:"type:text;not null;default:''"`
+	ID            int64                 `json:"id" gorm:"primary_key;index;"`
+	SourceID      int64                 `json:"sourceId" gorm:"not null;"`
+	WebhookID     int64                 `json:"webhookId" gorm:"not null;"`
+	ResponseCode  int                   `json:"responseCode" gorm:"not null;default:0"`
+	Attempts      int                   `json:"attempts" gorm:"not null;default:0"`
+}
+
+type WebhookUriRequest struct {
+	CircleID  int64 `uri:"circleId"`
+	WebhookID int64 `uri:"webhookId" validate:"gt=0"`
+}
+
+type WebhookCreateRequest struct {
+	URL    string         `json:"url" validate:"required,http_url,lte=2000"`
+	Events []WebhookEvent `json:"events" validate:"gt=0,lte=4,unique,dive,oneof=VOTE_CAST CANDIDATE_COMMITTED STAGE_CHANGED CIRCLE_CLOSED"`
+}
+
+type WebhookUpdateRequest struct {
+	URL    *string        `json:"url,omitempty" validate:"omitempty,http_url,lte=2000"`
+	Events []WebhookEvent `json:"events,omitempty" validate:"omitempty,gt=0,lte=4,unique,dive,oneof=VOTE_CAST CANDIDATE_COMMITTED STAGE_CHANGED CIRCLE_CLOSED"`
+	Active *bool          `json:"active,omitempty" validate:"omitempty"`
+}
+
+// WebhookResponse of a webhook. The secret is only returned,
+// when the webhook has been created.
+type WebhookResponse struct {
+	CreatedAt time.Time      `json:"createdAt"`
+	UpdatedAt time.Time      `json:"updatedAt"`
+	URL       string         `json:"url"`
+	Secret    string         `json:"secret,omitempty"`
+	Events    []WebhookEvent `json:"events"`
+	ID        int64          `json:"id"`
+	CircleID  int64          `json:"circleId"`
+	Active    bool           `json:"active"`
+}
+
+type WebhookDeliveryResponse struct {
+	CreatedAt     time.Time             `json:"createdAt"`
+	NextAttemptAt time.Time             `json:"nextAttemptAt"`
+	DeliveredAt   *time.Time            `json:"deliveredAt"`
+	Event         WebhookEvent          `json:"event"`
+	Status        WebhookDeliveryStatus `json:"status"`
+	LastError     string                `json:"lastError"`
+	ID            int64                 `json:"id"`
+	ResponseCode  int                   `json:"responseCode"`
+	Attempts      int                   `json:"attempts"`
+}
+
+// WebhookPayload is the body, that is sent to the webhook
+type WebhookPayload struct {
+	CreatedAt time.Time       `json:"createdAt"`
+	Event     WebhookEvent    `json:"event"`
+	Data      json.RawMessage `json:"data"`
+	CircleID  int64           `json:"circleId"`
+}
+
+// CircleClosedWebhookData is sent with the CIRCLE_CLOSED event
+// and contains the final rankings and the turnout of the circle.
+type CircleClosedWebhookData struct {
+	Circle   *CircleResponse `json:"circle"`
+	Rankings []*Ranking      `json:"rankings"`
+	Turnout  *CircleTurnout  `json:"turnout"`
+	Quorum   *CircleQuorum   `json:"quorum"`
+}
+
+type WebhookEvent string
+
+const (
+	WebhookEventVoteCast           WebhookEvent = "VOTE_CAST"
+	WebhookEventCandidateCommitted WebhookEvent = "CANDIDATE_COMMITTED"
+	WebhookEventStageChanged       WebhookEvent = "STAGE_CHANGED"
+	WebhookEventCircleClosed       WebhookEvent = "CIRCLE_CLOSED"
+)
+
+type WebhookDeliveryStatus string
+
+const (
+	WebhookDeliveryStatusPending   WebhookDeliveryStatus = "PENDING"
+	WebhookDeliveryStatusDelivered WebhookDeliveryStatus = "DELIVERED"
+	WebhookDeliveryStatusDead      WebhookDeliveryStatus = "DEAD"
+	WebhookDeliveryStatusSkipped   WebhookDeliveryStatus = "SKIPPED"
+)
+
+func (e *WebhookDeliveryStatus) Scan(value interface{}) error {
+	*e = WebhookDeliveryStatus(value.(string))
+	return nil
+}
+
+func (e WebhookDeliveryStatus) Value() (driver.Value, error) {
+	return string(e), nil
+}
+
+func (e WebhookDeliveryStatus) IsValid() bool {
+	switch e {
+	case WebhookDeliveryStatusPending,
+		WebhookDeliveryStatusDelivered,
+		WebhookDeliveryStatusDead,
+		WebhookDeliveryStatusSkipped:
+		return true
+	}
+	return false
+}
+
+func (e WebhookDeliveryStatus) String() string {
+	return string(e)
+}
@@ -0,0 +1,45 @@
+package model
+
+import "encoding/json"
+
+type WebSocketMessageType string
+
+const (
+	WebSocketMessageSubscribe    WebSocketMessageType = "subscribe"
+	WebSocketMessageUnsubscribe  WebSocketMessageType = "unsubscribe"
+	WebSocketMessageVote         WebSocketMessageType = "vote"
+	WebSocketMessageRevokeVote   WebSocketMessageType = "revoke-vote"
+	WebSocketMessageSubscribed   WebSocketMessageType = "subscribed"
+	WebSocketMessageUnsubscribed WebSocketMessageType = "unsubscribed"
+	WebSocketMessageEvent        WebSocketMessageType = "event"
+	WebSocketMessageResult       WebSocketMessageType = "result"
+	WebSocketMessageError        WebSocketMessageType = "error"
+)
+
+// WebSocketRequest is a frame sent by the client over the WebSocket.
+// The request id is returned with the response to the request.
+type WebSocketRequest struct {
+	Type         WebSocketMessageType `json:"type" validate:"oneof=subscribe unsubscribe vote revoke-vote"`
+	RequestID    string               `json:"requestId" validate:"lte=64"`
+	CircleID     int64                `json:"circleId" validate:"gt=0"`
+	LastSequence int64                `json:"lastSequence" validate:"gte=0"`
+	CandidateID  string               `json:"candidateId"`
+	// CandidateIDs of the candidates approved in an approval circle
+	CandidateIDs []string `json:"candidateIds"`
+	// Scores of the candidates in a score circle
+	Scores []CandidateScoreRequest `json:"scores"`
+	// Abstain from voting instead of voting for a candidate
+	Abstain bool `json:"abstain"`
+}
+
+// WebSocketResponse is a frame sent by the server over the WebSocket,
+// either as response to a request or as event of a subscribed circle.
+type WebSocketResponse struct {
+	Type      WebSocketMessageType `json:"type"`
+	RequestID string               `json:"requestId,omitempty"`
+	CircleID  int64                `json:"circleId,omitempty"`
+	Name      string               `json:"name,omitempty"`
+	Sequence  int64                `json:"sequence,omitempty"`
+	Data      json.RawMessage      `json:"data,omitempty"`
+	Msg       string               `json:"msg,omitempty"`
+}
@@ -0,0 +1,848 @@
+package api
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	logger "github.com/VerzCar/vyf-lib-logger"
+	"github.com/VerzCar/vyf-vote-circle/api/model"
+	"github.com/VerzCar/vyf-vote-circle/app/config"
+	"github.com/VerzCar/vyf-vote-circle/app/database"
+	routerContext "github.com/VerzCar/vyf-vote-circle/app/router/ctx"
+	"time"
+)
+
+const (
+	// defaultNotificationInterval is used if no dispatch interval is configured.
+	defaultNotificationInterval = time.Second
+	// defaultNotificationBatchSize is used if no batch size is configured.
+	defaultNotificationBatchSize = 50
+	// defaultNotificationMaxAttempts is used if no max attempts are configured.
+	defaultNotificationMaxAttempts = 5
+	// defaultNotificationBackoff is used if no backoff is configured.
+	defaultNotificationBackoff = 30 * time.Second
+	// defaultNotificationTimeout is used if no timeout is configured.
+	defaultNotificationTimeout = 5 * time.Second
+	// defaultNotificationClosingSoon is used if no closing soon duration is configured.
+	defaultNotificationClosingSoon = 24 * time.Hour
+	// defaultNotificationCommitmentDeadlineSoon is used if no commitment deadline soon duration is configured.
+	defaultNotificationCommitmentDeadlineSoon = 24 * time.Hour
+	// defaultNotificationClosingSoonInterval is used if no closing soon interval is configured.
+	defaultNotificationClosingSoonInterval = time.Minute
+	// notificationMaxBackoff is the longest delay between two delivery attempts.
+	notificationMaxBackoff = 6 * time.Hour
+	// notificationClaimLease is the time the claimed deliveries are reserved for
+	// the delivery, before another instance will claim them again.
+	notificationClaimLease = 5 * time.Minute
+	// notificationsLimit of the notifications returned of the inbox
+	notificationsLimit = 100
+	// notificationClosingSoonBatchSize of the circles closing soon notified at once
+	notificationClosingSoonBatchSize = 50
+	// notificationTimeFormat of the times in the messages of the notifications
+	notificationTimeFormat = "2006-01-02 15:04 MST"
+	// notificationEmailVerificationExpiry is the time the verification token
+	// of an email is valid after it has been sent.
+	notificationEmailVerificationExpiry = 24 * time.Hour
+)
+
+// ErrEmailVerificationInvalid is returned, if the verification token of
+// the email is wrong or expired.
+var ErrEmailVerificationInvalid = errors.New("email verification token is invalid or expired")
+
+// NotificationService notifies the users about pending commitments and
+// the stages of their circles. The notifications are stored in the inbox
+// of the user and delivered to the channels the user enabled.
+type NotificationService interface {
+	Notifications(
+		ctx context.Context,
+		unread bool,
+	) (*model.NotificationsResponse, error)
+	MarkNotificationRead(
+		ctx context.Context,
+		notificationId int64,
+	) error
+	MarkAllNotificationsRead(
+		ctx context.Context,
+	) error
+	NotificationPreference(
+		ctx context.Context,
+	) (*model.NotificationPreference, error)
+	UpdateNotificationPreference(
+		ctx context.Context,
+		preferenceUpdateRequest *model.NotificationPreferenceUpdateRequest,
+	) (*model.NotificationPreference, error)
+	VerifyNotificationEmail(
+		ctx context.Context,
+		verifyRequest *model.NotificationEmailVerifyRequest,
+	) (*model.NotificationPreference, error)
+	CircleEvent(
+		ctx context.Context,
+		sourceId int64,
+		circleId int64,
+		payload interface{},
+	) error
+	Run(ctx context.Context)
+}
+
+type NotificationRepository interface {
+	CircleById(id int64) (*model.Circle, error)
+	NotificationsByUserIdentityId(
+		userIdentityId string,
+		unread bool,
+		limit int,
+	) ([]*model.Notification, error)
+	CountUnreadNotifications(userIdentityId string) (int64, error)
+	MarkNotificationRead(
+		userIdentityId string,
+		notificationId int64,
+	) error
+	MarkAllNotificationsRead(userIdentityId string) error
+	NotificationPreferenceByUserIdentityId(
+		userIdentityId string,
+	) (*model.NotificationPreference, error)
+	NotificationPreferencesByUserIdentityIds(
+		userIdentityIds []string,
+	) ([]*model.NotificationPreference, error)
+	SaveNotificationPreference(
+		preference *model.NotificationPreference,
+	) (*model.NotificationPreference, error)
+	CreateNotifications(
+		notifications []*model.Notification,
+		channels map[string][]model.NotificationChannel,
+	) error
+	CircleMemberIdentityIds(circleId int64) ([]string, error)
+	CirclesClosingSoon(
+		until time.Time,
+		limit int,
+	) ([]*model.Circle, error)
+	CirclesCommitmentDeadlineSoon(
+		until time.Time,
+		limit int,
+	) ([]*model.Circle, error)
+	ClaimNotificationDeliveries(
+		limit int,
+		lease time.Duration,
+	) ([]*model.NotificationDelivery, error)
+	UpdateNotificationDeliveries(deliveries []*model.NotificationDelivery) error
+}
+
+type notificationService struct {
+	storage  NotificationRepository
+	channels map[model.NotificationChannel]NotificationChannel
+	config   *config.Config
+	log      logger.Logger
+}
+
+func NewNotificationService(
+	notificationRepo NotificationRepository,
+	channels []NotificationChannel,
+	config *config.Config,
+	log logger.Logger,
+) NotificationService {
+	channelsByName := make(map[model.NotificationChannel]NotificationChannel, len(channels))
+
+	for _, channel := range channels {
+		channelsByName[channel.Channel()] = channel
+	}
+
+	return &notificationService{
+		storage:  notificationRepo,
+		channels: channelsByName,
+		config:   config,
+		log:      log,
+	}
+}
+
+// Notifications of the inbox of the user with the latest notifications first
+func (c *notificationService) Notifications(
+	ctx context.Context,
+	unread bool,
+) (*model.NotificationsResponse, error) {
+	authClaims, err := routerContext.ContextToAuthClaims(ctx)
+
+	if err != nil {
+		c.log.Errorf("error getting auth claims: %s", err)
+		return nil, err
+	}
+
+	notifications, err := c.storage.NotificationsByUserIdentityId(authClaims.Subject, unread, notificationsLimit)
+
+	if err != nil {
+		return nil, err
+	}
+
+	unreadCount, err := c.storage.CountUnreadNotifications(authClaims.Subject)
+
+	if err != nil {
+		return nil, err
+	}
+
+	notificationsResponse := make([]*model.NotificationResponse, 0, len(notifications))
+
+	for _, notification := range notifications {
+		notificationsResponse = append(notificationsResponse, toNotificationResponse(notification))
+	}
+
+	return &model.NotificationsResponse{
+		Notifications: notificationsResponse,
+		Unread:        unreadCount,
+	}, nil
+}
+
+func (c *notificationService) MarkNotificationRead(
+	ctx context.Context,
+	notificationId int64,
+) error {
+	authClaims, err := routerContext.ContextToAuthClaims(ctx)
+
+	if err != nil {
+		c.log.Errorf("error getting auth claims: %s", err)
+		return err
+	}
+
+	return c.storage.MarkNotificationRead(authClaims.Subject, notificationId)
+}
+
+func (c *notificationService) MarkAllNotificationsRead(
+	ctx context.Context,
+) error {
+	authClaims, err := routerContext.ContextToAuthClaims(ctx)
+
+	if err != nil {
+		c.log.Errorf("error getting auth claims: %s", err)
+		return err
+	}
+
+	return c.storage.MarkAllNotificationsRead(authClaims.Subject)
+}
+
+// NotificationPreference of the user. If the user has no preference yet,
+// the default preference with all kinds and only the inbox is returned.
+func (c *notificationService) NotificationPreference(
+	ctx context.Context,
+) (*model.NotificationPreference, error) {
+	authClaims, err := routerContext.ContextToAuthClaims(ctx)
+
+	if err != nil {
+		c.log.Errorf("error getting auth claims: %s", err)
+		return nil, err
+	}
+
+	return c.preferenceOfUser(authClaims.Subject)
+}
+
+// UpdateNotificationPreference of the user. A secret for the webhook
+// is generated, when the webhook is enabled for the first time.
+// If an email is given, that is not verified yet, a verification token is sent
+// to the email. The email notifications are delivered after the verification.
+func (c *notificationService) UpdateNotificationPreference(
+	ctx context.Context,
+	preferenceUpdateRequest *model.NotificationPreferenceUpdateRequest,
+) (*model.NotificationPreference, error) {
+	authClaims, err := routerContext.ContextToAuthClaims(ctx)
+
+	if err != nil {
+		c.log.Errorf("error getting auth claims: %s", err)
+		return nil, err
+	}
+
+	preference, err := c.preferenceOfUser(authClaims.Subject)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if preferenceUpdateRequest.Kinds != nil {
+		kinds := make([]string, 0, len(preferenceUpdateRequest.Kinds))
+
+		for _, kind := range preferenceUpdateRequest.Kinds {
+			kinds = append(kinds, string(kind))
+		}
+
+		preference.Kinds = kinds
+	}
+
+	var verificationToken string
+
+	if preferenceUpdateRequest.Email != nil {
+		if *preferenceUpdateRequest.Email != preference.Email {
+			preference.EmailVerifiedAt = nil
+		}
+
+		preference.Email = *preferenceUpdateRequest.Email
+		preference.EmailVerificationHash = ""
+		preference.EmailVerificationExpiresAt = nil
+
+		if preference.Email != "" && !preference.EmailVerified() {
+			verificationToken, err = generateEmailVerificationToken()
+
+			if err != nil {
+				c.log.Errorf("could not generate email verification token: %s", err)
+				return nil, err
+			}
+
+			expiresAt := time.Now().Add(notificationEmailVerificationExpiry)
+			preference.EmailVerificationHash = emailVerificationHash(verificationToken)
+			preference.EmailVerificationExpiresAt = &expiresAt
+		}
+	}
+
+	if preferenceUpdateRequest.EmailEnabled != nil {
+		preference.EmailEnabled = *preferenceUpdateRequest.EmailEnabled
+	}
+
+	if preferenceUpdateRequest.WebhookURL != nil {
+		if err := validateWebhookURL(ctx, *preferenceUpdateRequest.WebhookURL); err != nil {
+			c.log.Infof("webhook url of user %s is not allowed: %s", authClaims.Subject, err)
+			return nil, fmt.Errorf("webhook url is not allowed")
+		}
+
+		preference.WebhookURL = *preferenceUpdateRequest.WebhookURL
+	}
+
+	if preferenceUpdateRequest.WebhookEnabled != nil {
+		preference.WebhookEnabled = *preferenceUpdateRequest.WebhookEnabled
+	}
+
+	if preference.EmailEnabled && preference.Email == "" {
+		return nil, fmt.Errorf("email must be set to enable the email notifications")
+	}
+
+	if preference.WebhookEnabled && preference.WebhookURL == "" {
+		return nil, fmt.Errorf("webhook url must be set to enable the webhook notifications")
+	}
+
+	if preference.WebhookEnabled && preference.WebhookSecret == "" {
+		secret, err := generateWebhookSecret()
+
+		if err != nil {
+			c.log.Errorf("could not generate webhook secret: %s", err)
+			return nil, err
+		}
+
+		preference.WebhookSecret = secret
+	}
+
+	preference, err = c.storage.SaveNotificationPreference(preference)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if verificationToken != "" {
+		if err := c.sendEmailVerification(ctx, preference, verificationToken); err != nil {
+			c.log.Errorf("could not send email verification to user %s: %s", authClaims.Subject, err)
+			return nil, fmt.Errorf("email verification could not be sent")
+		}
+	}
+
+	return preference, nil
+}
+
+// VerifyNotificationEmail of the user with the token sent to the email.
+func (c *notificationService) VerifyNotificationEmail(
+	ctx context.Context,
+	verifyRequest *model.NotificationEmailVerifyRequest,
+) (*model.NotificationPreference, error) {
+	authClaims, err := routerContext.ContextToAuthClaims(ctx)
+
+	if err != nil {
+		c.log.Errorf("error getting auth claims: %s", err)
+		return nil, err
+	}
+
+	preference, err := c.preferenceOfUser(authClaims.Subject)
+
+	if err != nil {
+		return nil, err
+	}
+
+	hash := emailVerificationHash(verifyRequest.Token)
+
+	if preference.EmailVerificationHash == "" ||
+		preference.EmailVerificationExpiresAt == nil ||
+		time.Now().After(*preference.EmailVerificationExpiresAt) ||
+		subtle.ConstantTimeCompare([]byte(hash), []byte(preference.EmailVerificationHash)) != 1 {
+		return nil, ErrEmailVerificationInvalid
+	}
+
+	verifiedAt := time.Now()
+	preference.EmailVerifiedAt = &verifiedAt
+	preference.EmailVerificationHash = ""
+	preference.EmailVerificationExpiresAt = nil
+
+	return c.storage.SaveNotificationPreference(preference)
+}
+
+// CircleEvent notifies the users about the delivered outbox event of the circle.
+// A candidate added to a circle is asked for the commitment and the members of
+// a circle are notified when the circle becomes hot and when it closed.
+func (c *notificationService) CircleEvent(
+	ctx context.Context,
+	sourceId int64,
+	circleId int64,
+	payload interface{},
+) error {
+	switch event := payload.(type) {
+	case *model.CircleCandidateChangedEvent:
+		if event.Operation != model.EventOperationCreated || event.Candidate.Commitment != model.CommitmentOpen {
+			return nil
+		}
+
+		circle, err := c.storage.CircleById(circleId)
+
+		if err != nil {
+			return err
+		}
+
+		return c.notify(
+			circle.ID,
+			[]string{event.Candidate.Candidate},
+			model.NotificationKindCommitmentPending,
+			fmt.Sprintf("commitment-pending:%d", event.Candidate.ID),
+			"Commitment requested",
+			fmt.Sprintf(
+				"You have been added as candidate to the circle %s. Please commit to or reject your candidacy.",
+				circle.Name,
+			),
+		)
+	case *model.CircleChangedEvent:
+		if event.Operation != model.EventOperationStageChanged {
+			return nil
+		}
+
+		switch event.Circle.Stage {
+		case model.CircleStageHot:
+			message := fmt.Sprintf("The circle %s is open for voting.", event.Circle.Name)
+
+			if event.Circle.ValidUntil != nil {
+				message = fmt.Sprintf(
+					"The circle %s is open for voting until %s.",
+					event.Circle.Name,
+					formatNotificationTime(*event.Circle.ValidUntil),
+				)
+			}
+
+			return c.notifyMembers(circleId, model.NotificationKindCircleHot, "Voting started", message)
+		case model.CircleStageClosed:
+			return c.notifyMembers(
+				circleId,
+				model.NotificationKindCircleResults,
+				"Results published",
+				fmt.Sprintf("The circle %s has closed, the results are available.", event.Circle.Name),
+			)
+		}
+	}
+
+	return nil
+}
+
+// Run delivers the pending notifications to the channels, notifies the
+// members of the circles closing soon and warns the creators of the circles,
+// whose commitment deadline is soon, in the configured intervals.
+// Blocks until the context is done.
+func (c *notificationService) Run(ctx context.Context) {
+	interval := time.Duration(c.config.Notification.Interval) * time.Millisecond
+
+	if interval <= 0 {
+		interval = defaultNotificationInterval
+	}
+
+	closingSoonInterval := time.Duration(c.config.Notification.ClosingSoonInterval) * time.Second
+
+	if closingSoonInterval <= 0 {
+		closingSoonInterval = defaultNotificationClosingSoonInterval
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	closingSoonTicker := time.NewTicker(closingSoonInterval)
+	defer closingSoonTicker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			c.dispatchPending(ctx)
+		case <-closingSoonTicker.C:
+			c.notifyCirclesClosingSoon()
+			c.notifyCommitmentDeadlinesSoon()
+		}
+	}
+}
+
+// dispatchPending claims the due deliveries, sends them outside of
+// the claiming transaction and writes the result of the deliveries.
+func (c *notificationService) dispatchPending(ctx context.Context) {
+	deliveries, err := c.storage.ClaimNotificationDeliveries(c.batchSize(), notificationClaimLease)
+
+	if err != nil || len(deliveries) == 0 {
+		return
+	}
+
+	c.dispatch(ctx, deliveries)
+
+	_ = c.storage.UpdateNotificationDeliveries(deliveries)
+}
+
+// notifyCirclesClosingSoon notifies the members of the hot circles,
+// that close within the configured closing soon duration.
+func (c *notificationService) notifyCirclesClosingSoon() {
+	closingSoon := time.Duration(c.config.Notification.ClosingSoon) * time.Minute
+
+	if closingSoon <= 0 {
+		closingSoon = defaultNotificationClosingSoon
+	}
+
+	circles, err := c.storage.CirclesClosingSoon(time.Now().UTC().Add(closingSoon), notificationClosingSoonBatchSize)
+
+	if err != nil {
+		return
+	}
+
+	for _, circle := range circles {
+		err := c.notifyMembers(
+			circle.ID,
+			model.NotificationKindCircleClosingSoon,
+			"Circle closing soon",
+			fmt.Sprintf(
+				"The circle %s closes at %s. Cast your vote before it is too late.",
+				circle.Name,
+				formatNotificationTime(*circle.ValidUntil),
+			),
+		)
+
+		if err != nil {
+			c.log.Errorf("could not notify members of circle id %d closing soon: %s", circle.ID, err)
+		}
+	}
+}
+
+// notifyCommitmentDeadlinesSoon warns the creators of the circles with open
+// commitments, whose commitment deadline is within the configured duration,
+// that the open commitments will be rejected at the deadline.
+func (c *notificationService) notifyCommitmentDeadlinesSoon() {
+	deadlineSoon := time.Duration(c.config.Notification.CommitmentDeadlineSoon) * time.Minute
+
+	if deadlineSoon <= 0 {
+		deadlineSoon = defaultNotificationCommitmentDeadlineSoon
+	}
+
+	circles, err := c.storage.CirclesCommitmentDeadlineSoon(
+		time.Now().UTC().Add(deadlineSoon),
+		notificationClosingSoonBatchSize,
+	)
+
+	if err != nil {
+		return
+	}
+
+	for _, circle := range circles {
+		err := c.notify(
+			circle.ID,
+			[]string{circle.CreatedFrom},
+			model.NotificationKindCommitmentDeadlineSoon,
+			fmt.Sprintf("%s:%d", model.NotificationKindCommitmentDeadlineSoon, circle.ID),
+			"Commitment deadline soon",
+			fmt.Sprintf(
+				"The commitment deadline of the circle %s is at %s. Candidates and voters, that have not answered their commitment until then, will be rejected.",
+				circle.Name,
+				formatNotificationTime(*circle.CommitmentDeadline),
+			),
+		)
+
+		if err != nil {
+			c.log.Errorf("could not warn creator of circle id %d about the commitment deadline: %s", circle.ID, err)
+		}
+	}
+}
+
+// notifyMembers of the circle. The members are notified only once per kind.
+func (c *notificationService) notifyMembers(
+	circleId int64,
+	kind model.NotificationKind,
+	title string,
+	message string,
+) error {
+	identityIds, err := c.storage.CircleMemberIdentityIds(circleId)
+
+	if err != nil {
+		return err
+	}
+
+	dedupeKey := fmt.Sprintf("%s:%d", kind, circleId)
+
+	return c.notify(circleId, identityIds, kind, dedupeKey, title, message)
+}
+
+// notify the users, that have not muted the kind of the notification,
+// and create the deliveries to the channels each user enabled.
+func (c *notificationService) notify(
+	circleId int64,
+	identityIds []string,
+	kind model.NotificationKind,
+	dedupeKey string,
+	title string,
+	message string,
+) error {
+	if len(identityIds) == 0 {
+		return nil
+	}
+
+	preferences, err := c.preferencesOfUsers(identityIds)
+
+	if err != nil {
+		return err
+	}
+
+	notifications := make([]*model.Notification, 0, len(identityIds))
+	channels := make(map[string][]model.NotificationChannel)
+
+	for _, identityId := range identityIds {
+		preference, ok := preferences[identityId]
+
+		if ok && !preferenceHasKind(preference, kind) {
+			continue
+		}
+
+		notifications = append(
+			notifications, &model.Notification{
+				UserIdentityID: identityId,
+				Kind:           kind,
+				Title:          title,
+				Message:        message,
+				DedupeKey:      dedupeKey,
+				CircleID:       circleId,
+			},
+		)
+
+		if !ok {
+			continue
+		}
+
+		for name, channel := range c.channels {
+			if channel.Enabled(preference) {
+				channels[identityId] = append(channels[identityId], name)
+			}
+		}
+	}
+
+	return c.storage.CreateNotifications(notifications, channels)
+}
+
+// dispatch the deliveries to their channels. A failed delivery is retried
+// with an exponential backoff, until the max attempts are reached.
+func (c *notificationService) dispatch(ctx context.Context, deliveries []*model.NotificationDelivery) {
+	identityIds := make([]string, 0, len(deliveries))
+
+	for _, delivery := range deliveries {
+		if delivery.Notification != nil {
+			identityIds = append(identityIds, delivery.Notification.UserIdentityID)
+		}
+	}
+
+	preferences, err := c.preferencesOfUsers(identityIds)
+
+	if err != nil {
+		return
+	}
+
+	for _, delivery := range deliveries {
+		delivery.Attempts++
+
+		err := c.deliver(ctx, preferences, delivery)
+
+		if err == nil {
+			deliveredAt := time.Now()
+			delivery.Status = model.NotificationDeliveryStatusDelivered
+			delivery.DeliveredAt = &deliveredAt
+			delivery.LastError = ""
+			continue
+		}
+
+		delivery.LastError = err.Error()
+
+		if delivery.Attempts >= c.maxAttempts() {
+			c.log.Warnf(
+				"notification delivery id %d to channel %s is dead after %d attempts: %s",
+				delivery.ID,
+				delivery.Channel,
+				delivery.Attempts,
+				err,
+			)
+			delivery.Status = model.NotificationDeliveryStatusDead
+			continue
+		}
+
+		delivery.NextAttemptAt = time.Now().Add(c.backoff(delivery.Attempts))
+	}
+}
+
+// deliver the notification of the delivery to its channel, if the
+// user has still enabled the channel.
+func (c *notificationService) deliver(
+	ctx context.Context,
+	preferences map[string]*model.NotificationPreference,
+	delivery *model.NotificationDelivery,
+) error {
+	if delivery.Notification == nil {
+		return fmt.Errorf("notification not found")
+	}
+
+	channel, ok := c.channels[delivery.Channel]
+
+	if !ok {
+		return fmt.Errorf("notification channel %s is not available", delivery.Channel)
+	}
+
+	preference, ok := preferences[delivery.Notification.UserIdentityID]
+
+	if !ok || !channel.Enabled(preference) {
+		return fmt.Errorf("notification channel %s is disabled", delivery.Channel)
+	}
+
+	return channel.Send(ctx, preference, delivery.Notification)
+}
+
+// preferenceOfUser or the default preference if the user has none
+func (c *notificationService) preferenceOfUser(userIdentityId string) (*model.NotificationPreference, error) {
+	preference, err := c.storage.NotificationPreferenceByUserIdentityId(userIdentityId)
+
+	if err != nil && !database.RecordNotFound(err) {
+		return nil, err
+	}
+
+	if database.RecordNotFound(err) {
+		kinds := make([]string, 0, len(model.AllNotificationKind))
+
+		for _, kind := range model.AllNotificationKind {
+			kinds = append(kinds, string(kind))
+		}
+
+		return &model.NotificationPreference{
+			UserIdentityID: userIdentityId,
+			Kinds:          kinds,
+		}, nil
+	}
+
+	return preference, nil
+}
+
+// preferencesOfUsers by the identity of the user.
+// Users without a preference are not contained.
+func (c *notificationService) preferencesOfUsers(
+	identityIds []string,
+) (map[string]*model.NotificationPreference, error) {
+	preferencesById := make(map[string]*model.NotificationPreference, len(identityIds))
+
+	if len(identityIds) == 0 {
+		return preferencesById, nil
+	}
+
+	preferences, err := c.storage.NotificationPreferencesByUserIdentityIds(identityIds)
+
+	if err != nil {
+		return nil, err
+	}
+
+	for _, preference := range preferences {
+		preferencesById[preference.UserIdentityID] = preference
+	}
+
+	return preferencesById, nil
+}
+
+// backoff before the next delivery attempt, that doubles with every attempt
+// sendEmailVerification sends the verification token to the email of the preference
+// regardless of the enabled channels, as the email is not verified yet.
+func (c *notificationService) sendEmailVerification(
+	ctx context.Context,
+	preference *model.NotificationPreference,
+	token string,
+) error {
+	channel, ok := c.channels[model.NotificationChannelEmail]
+
+	if !ok {
+		return fmt.Errorf("email channel is not configured")
+	}
+
+	return channel.Send(
+		ctx, preference, &model.Notification{
+			Title: "Verify your email",
+			Message: fmt.Sprintf(
+				"Verify your email for the notifications of your circles with the token %s. "+
+					"The token expires at %s.",
+				token,
+				preference.EmailVerificationExpiresAt.Format(notificationTimeFormat),
+			),
+		},
+	)
+}
+
+func (c *notificationService) backoff(attempts int) time.Duration {
+	backoff := time.Duration(c.config.Notification.Backoff) * time.Millisecond
+
+	if backoff <= 0 {
+		backoff = defaultNotificationBackoff
+	}
+
+	for i := 1; i < attempts && backoff < notificationMaxBackoff; i++ {
+		backoff *= 2
+	}
+
+	if backoff > notificationMaxBackoff {
+		return notificationMaxBackoff
+	}
+
+	return backoff
+}
+
+func (c *notificationService) batchSize() int {
+	if c.config.Notification.BatchSize > 0 {
+		return c.config.Notification.BatchSize
+	}
+	return defaultNotificationBatchSize
+}
+
+func (c *notificationService) maxAttempts() int {
+	if c.config.Notification.MaxAttempts > 0 {
+		return c.config.Notification.MaxAttempts
+	}
+	return defaultNotificationMaxAttempts
+}
+
+func preferenceHasKind(preference *model.NotificationPreference, kind model.NotificationKind) bool {
+	for _, preferenceKind := range preference.Kinds {
+		if preferenceKind == string(kind) {
+			return true
+		}
+	}
+	return false
+}
+
+func formatNotificationTime(t time.Time) string {
+	return t.UTC().Format(notificationTimeFormat)
+}
+
+// generateEmailVerificationToken of 32 random bytes hex encoded
+func generateEmailVerificationToken() (string, error) {
+	token := make([]byte, 32)
+
+	if _, err := rand.Read(token); err != nil {
+		return "", err
+	}
+
+	return hex.EncodeToString(token), nil
+}
+
+// emailVerificationHash is the hex encoded SHA-256 of the verification token
+func emailVerificationHash(token string) string {
+	hash := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(hash[:])
+}
@@ -0,0 +1,167 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	logger "github.com/VerzCar/vyf-lib-logger"
+	"github.com/VerzCar/vyf-vote-circle/api/model"
+	"github.com/VerzCar/vyf-vote-circle/app/config"
+	"net"
+	"net/http"
+	"net/smtp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// NotificationChannel delivers the notifications of a user to a
+// channel besides the inbox, e.g. an email address or a webhook.
+type NotificationChannel interface {
+	Channel() model.NotificationChannel
+	// Enabled determines if the user enabled the channel in the preference
+	Enabled(preference *model.NotificationPreference) bool
+	Send(
+		ctx context.Context,
+		preference *model.NotificationPreference,
+		notification *model.Notification,
+	) error
+}
+
+type smtpNotificationChannel struct {
+	config *config.Config
+	log    logger.Logger
+}
+
+// NewSmtpNotificationChannel delivers the notifications as email
+// via the configured smtp server.
+func NewSmtpNotificationChannel(
+	config *config.Config,
+	log logger.Logger,
+) NotificationChannel {
+	return &smtpNotificationChannel{
+		config: config,
+		log:    log,
+	}
+}
+
+func (c *smtpNotificationChannel) Channel() model.NotificationChannel {
+	return model.NotificationChannelEmail
+}
+
+func (c *smtpNotificationChannel) Enabled(preference *model.NotificationPreference) bool {
+	return preference.EmailEnabled && preference.EmailVerified()
+}
+
+func (c *smtpNotificationChannel) Send(
+	ctx context.Context,
+	preference *model.NotificationPreference,
+	notification *model.Notification,
+) error {
+	smtpConfig := c.config.Notification.Smtp
+	addr := net.JoinHostPort(smtpConfig.Host, strconv.Itoa(smtpConfig.Port))
+
+	var auth smtp.Auth
+
+	if smtpConfig.Username != "" {
+		auth = smtp.PlainAuth("", smtpConfig.Username, smtpConfig.Password, smtpConfig.Host)
+	}
+
+	var msg strings.Builder
+	msg.WriteString("From: " + smtpConfig.From + "\r\n")
+	msg.WriteString("To: " + preference.Email + "\r\n")
+	msg.WriteString("Subject: " + notification.Title + "\r\n")
+	msg.WriteString("MIME-Version: 1.0\r\n")
+	msg.WriteString("Content-Type: text/plain; charset=\"utf-8\"\r\n")
+	msg.WriteString("\r\n")
+	msg.WriteString(notification.Message + "\r\n")
+
+	return smtp.SendMail(addr, auth, smtpConfig.From, []string{preference.Email}, []byte(msg.String()))
+}
+
+type webhookNotificationChannel struct {
+	client *http.Client
+	config *config.Config
+	log    logger.Logger
+}
+
+// NewWebhookNotificationChannel delivers the notifications to the webhook of the user.
+// The requests are signed like the webhooks of the circles with the webhook
+// secret of the preference.
+func NewWebhookNotificationChannel(
+	config *config.Config,
+	log logger.Logger,
+) NotificationChannel {
+	timeout := time.Duration(config.Notification.Timeout) * time.Second
+
+	if timeout <= 0 {
+		timeout = defaultNotificationTimeout
+	}
+
+	return &webhookNotificationChannel{
+		client: newWebhookClient(timeout),
+		config: config,
+		log:    log,
+	}
+}
+
+func (c *webhookNotificationChannel) Channel() model.NotificationChannel {
+	return model.NotificationChannelWebhook
+}
+
+func (c *webhookNotificationChannel) Enabled(preference *model.NotificationPreference) bool {
+	return preference.WebhookEnabled && preference.WebhookURL != ""
+}
+
+func (c *webhookNotificationChannel) Send(
+	ctx context.Context,
+	preference *model.NotificationPreference,
+	notification *model.Notification,
+) error {
+	body, err := json.Marshal(toNotificationResponse(notification))
+
+	if err != nil {
+		return err
+	}
+
+	timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, preference.WebhookURL, bytes.NewReader(body))
+
+	if err != nil {
+		return err
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(WebhookHeaderDelivery, strconv.FormatInt(notification.ID, 10))
+	req.Header.Set(WebhookHeaderEvent, string(notification.Kind))
+	req.Header.Set(WebhookHeaderTimestamp, timestamp)
+	req.Header.Set(WebhookHeaderSignature, "sha256="+webhookSignature(preference.WebhookSecret, timestamp, body))
+
+	res, err := c.client.Do(req)
+
+	if err != nil {
+		return err
+	}
+
+	defer res.Body.Close()
+
+	if res.StatusCode < 200 || res.StatusCode > 299 {
+		return fmt.Errorf("webhook responded with status %d", res.StatusCode)
+	}
+
+	return nil
+}
+
+func toNotificationResponse(notification *model.Notification) *model.NotificationResponse {
+	return &model.NotificationResponse{
+		CreatedAt: notification.CreatedAt,
+		ReadAt:    notification.ReadAt,
+		Kind:      notification.Kind,
+		Title:     notification.Title,
+		Message:   notification.Message,
+		ID:        notification.ID,
+		CircleID:  notification.CircleID,
+	}
+}
@@ -0,0 +1,438 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	logger "github.com/VerzCar/vyf-lib-logger"
+	"github.com/VerzCar/vyf-vote-circle/api/model"
+	"github.com/VerzCar/vyf-vote-circle/app/config"
+	"github.com/VerzCar/vyf-vote-circle/app/database"
+	routerContext "github.com/VerzCar/vyf-vote-circle/app/router/ctx"
+	"time"
+)
+
+const (
+	// defaultOutboxInterval is used if no dispatch interval is configured.
+	defaultOutboxInterval = 500 * time.Millisecond
+	// defaultOutboxBatchSize is used if no batch size is configured.
+	defaultOutboxBatchSize = 100
+	// defaultOutboxMaxAttempts is used if no max attempts are configured.
+	defaultOutboxMaxAttempts = 10
+	// defaultOutboxBackoff is used if no backoff is configured.
+	defaultOutboxBackoff = time.Second
+	// outboxMaxBackoff is the longest delay between two delivery attempts.
+	outboxMaxBackoff = 5 * time.Minute
+	// outboxClaimLease is the time the claimed events are reserved for the
+	// delivery, before another instance will claim them again.
+	outboxClaimLease = 5 * time.Minute
+)
+
+// OutboxService dispatches the outbox events, that have been written in the same
+// transaction as the change they originate from, to the subscription services.
+// Events that could not be delivered after the configured attempts are dead
+// and can be inspected by the owner of the circle.
+type OutboxService interface {
+	Run(ctx context.Context)
+	DeadLetters(
+		ctx context.Context,
+		circleId int64,
+	) ([]*model.OutboxEventResponse, error)
+}
+
+type OutboxRepository interface {
+	CircleById(id int64) (*model.Circle, error)
+	ClaimOutboxEvents(
+		limit int,
+		lease time.Duration,
+	) ([]*model.OutboxEvent, error)
+	UpdateOutboxEvents(events []*model.OutboxEvent) error
+	DeadOutboxEventsByCircleId(circleId int64) ([]*model.OutboxEvent, error)
+}
+
+type OutboxRankingSubscription interface {
+	RankingChangedEvent(
+		ctx context.Context,
+		circleId int64,
+		events []*model.RankingChangedEvent,
+	) error
+}
+
+type OutboxCircleVoterSubscription interface {
+	CircleVoterChangedEvent(
+		ctx context.Context,
+		circleId int64,
+		event *model.CircleVoterChangedEvent,
+	) error
+}
+
+type OutboxCircleCandidateSubscription interface {
+	CircleCandidateChangedEvent(
+		ctx context.Context,
+		circleId int64,
+		event *model.CircleCandidateChangedEvent,
+	) error
+}
+
+type OutboxCircleSubscription interface {
+	CircleChangedEvent(
+		ctx context.Context,
+		circleId int64,
+		event *model.CircleChangedEvent,
+	) error
+}
+
+type OutboxWebhookSubscription interface {
+	CircleEvent(
+		ctx context.Context,
+		sourceId int64,
+		circleId int64,
+		payload interface{},
+	) error
+}
+
+type OutboxNotificationSubscription interface {
+	CircleEvent(
+		ctx context.Context,
+		sourceId int64,
+		circleId int64,
+		payload interface{},
+	) error
+}
+
+type outboxService struct {
+	storage                     OutboxRepository
+	rankingSubscription         OutboxRankingSubscription
+	circleVoterSubscription     OutboxCircleVoterSubscription
+	circleCandidateSubscription OutboxCircleCandidateSubscription
+	circleSubscription          OutboxCircleSubscription
+	webhookSubscription         OutboxWebhookSubscription
+	notificationSubscription    OutboxNotificationSubscription
+	config                      *config.Config
+	log                         logger.Logger
+}
+
+func NewOutboxService(
+	outboxRepo OutboxRepository,
+	rankingSubscription OutboxRankingSubscription,
+	circleVoterSubscription OutboxCircleVoterSubscription,
+	circleCandidateSubscription OutboxCircleCandidateSubscription,
+	circleSubscription OutboxCircleSubscription,
+	webhookSubscription OutboxWebhookSubscription,
+	notificationSubscription OutboxNotificationSubscription,
+	config *config.Config,
+	log logger.Logger,
+) OutboxService {
+	return &outboxService{
+		storage:                     outboxRepo,
+		rankingSubscription:         rankingSubscription,
+		circleVoterSubscription:     circleVoterSubscription,
+		circleCandidateSubscription: circleCandidateSubscription,
+		circleSubscription:          circleSubscription,
+		webhookSubscription:         webhookSubscription,
+		notificationSubscription:    notificationSubscription,
+		config:                      config,
+		log:                         log,
+	}
+}
+
+// Run dispatches the pending outbox events in the configured interval.
+// Blocks until the context is done.
+func (c *outboxService) Run(ctx context.Context) {
+	interval := time.Duration(c.config.Outbox.Interval) * time.Millisecond
+
+	if interval <= 0 {
+		interval = defaultOutboxInterval
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			c.dispatchPending(ctx)
+		}
+	}
+}
+
+// dispatchPending claims the pending events, delivers them outside of
+// the claiming transaction and writes the result of the delivery.
+func (c *outboxService) dispatchPending(ctx context.Context) {
+	events, err := c.storage.ClaimOutboxEvents(c.batchSize(), outboxClaimLease)
+
+	if err != nil || len(events) == 0 {
+		return
+	}
+
+	c.dispatch(ctx, events)
+
+	_ = c.storage.UpdateOutboxEvents(events)
+}
+
+// DeadLetters of the circle, that could not be delivered.
+// Only the owner of the circle is eligible to inspect them.
+func (c *outboxService) DeadLetters(
+	ctx context.Context,
+	circleId int64,
+) ([]*model.OutboxEventResponse, error) {
+	authClaims, err := routerContext.ContextToAuthClaims(ctx)
+
+	if err != nil {
+		c.log.Errorf("error getting auth claims: %s", err)
+		return nil, err
+	}
+
+	circle, err := c.storage.CircleById(circleId)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if circle.CreatedFrom != authClaims.Subject {
+		c.log.Infof(
+			"user is not eligible to inspect dead letters of circle: user %s, circle ID %d",
+			authClaims.Subject,
+			circle.ID,
+		)
+		return nil, fmt.Errorf("user is not eligible to inspect dead letters of circle")
+	}
+
+	events, err := c.storage.DeadOutboxEventsByCircleId(circleId)
+
+	if err != nil && !database.RecordNotFound(err) {
+		return nil, err
+	}
+
+	deadLetters := make([]*model.OutboxEventResponse, 0, len(events))
+
+	for _, event := range events {
+		deadLetters = append(
+			deadLetters, &model.OutboxEventResponse{
+				CreatedAt:     event.CreatedAt,
+				UpdatedAt:     event.UpdatedAt,
+				NextAttemptAt: event.NextAttemptAt,
+				Kind:          event.Kind,
+				Payload:       json.RawMessage(event.Payload),
+				Status:        event.Status,
+				LastError:     event.LastError,
+				ID:            event.ID,
+				CircleID:      event.CircleID,
+				Attempts:      event.Attempts,
+			},
+		)
+	}
+
+	return deadLetters, nil
+}
+
+// dispatch the events in the given order and set the state of the events
+// according to the result of the delivery. If an event of a circle could not
+// be delivered, all following events of the circle stay pending, to keep the
+// order of the events per circle. An event that failed for the maximum count of
+// attempts is dead and the following events of the circle will be delivered.
+func (c *outboxService) dispatch(ctx context.Context, events []*model.OutboxEvent) {
+	blockedCircles := make(map[int64]bool)
+	coalescedEvents := make(map[int64]bool)
+
+	for i, event := range events {
+		if blockedCircles[event.CircleID] || coalescedEvents[event.ID] {
+			continue
+		}
+
+		group := c.coalesce(events[i:])
+
+		for _, groupEvent := range group {
+			coalescedEvents[groupEvent.ID] = true
+		}
+
+		delivered, err := c.deliver(ctx, group)
+
+		for _, deliveredEvent := range group[:delivered] {
+			deliveredEvent.Attempts++
+			deliveredEvent.Status = model.OutboxStatusDelivered
+			deliveredEvent.LastError = ""
+		}
+
+		if err == nil {
+			continue
+		}
+
+		// the events of the group after the failed event have not been
+		// attempted and stay pending behind the failed event
+		failedEvent := group[delivered]
+		failedEvent.Attempts++
+		failedEvent.LastError = err.Error()
+
+		if failedEvent.Attempts >= c.maxAttempts() {
+			c.log.Errorf(
+				"outbox event id %d of circle id %d is dead after %d attempts: %s",
+				failedEvent.ID,
+				failedEvent.CircleID,
+				failedEvent.Attempts,
+				err,
+			)
+			failedEvent.Status = model.OutboxStatusDead
+			continue
+		}
+
+		failedEvent.NextAttemptAt = time.Now().Add(c.backoff(failedEvent.Attempts))
+		blockedCircles[failedEvent.CircleID] = true
+	}
+}
+
+// coalesce the first event with the following ranking events of its circle,
+// that have been written within the coalescing window. The ranking events
+// are coalesced until another event of the circle is written in between.
+// Without a coalescing window every event is delivered on its own.
+func (c *outboxService) coalesce(events []*model.OutboxEvent) []*model.OutboxEvent {
+	first := events[0]
+	group := []*model.OutboxEvent{first}
+	window := time.Duration(c.config.PubSub.CoalescingWindow) * time.Millisecond
+
+	if first.Kind != model.EventKindRanking || window <= 0 {
+		return group
+	}
+
+	for _, event := range events[1:] {
+		if event.CircleID != first.CircleID {
+			continue
+		}
+
+		if event.Kind != model.EventKindRanking ||
+			event.Published != first.Published ||
+			event.CreatedAt.Sub(first.CreatedAt) > window {
+			break
+		}
+
+		group = append(group, event)
+	}
+
+	return group
+}
+
+// deliver the events to the subscription service of their kind. Either a single
+// event or the coalesced ranking events of a circle are published at once.
+// The payload is updated with the sequence numbers assigned during the
+// delivery, so that a retry does not assign new sequence numbers.
+// Each step records its progress, a retry of an event that has
+// been published does not publish it again.
+// Returns the count of the delivered events and the error of the event,
+// that could not be delivered.
+func (c *outboxService) deliver(ctx context.Context, events []*model.OutboxEvent) (int, error) {
+	first := events[0]
+	payloads := make([]interface{}, len(events))
+	var publish func() error
+
+	switch first.Kind {
+	case model.EventKindRanking:
+		rankingEvents := make([][]*model.RankingChangedEvent, len(events))
+
+		for i := range events {
+			payloads[i] = &rankingEvents[i]
+		}
+
+		publish = func() error {
+			coalescedEvents := make([]*model.RankingChangedEvent, 0)
+
+			for _, eventsOfOutboxEvent := range rankingEvents {
+				coalescedEvents = append(coalescedEvents, eventsOfOutboxEvent...)
+			}
+
+			return c.rankingSubscription.RankingChangedEvent(ctx, first.CircleID, coalescedEvents)
+		}
+	case model.EventKindCircleVoter:
+		voterEvent := &model.CircleVoterChangedEvent{}
+		payloads[0] = voterEvent
+		publish = func() error {
+			return c.circleVoterSubscription.CircleVoterChangedEvent(ctx, first.CircleID, voterEvent)
+		}
+	case model.EventKindCircleCandidate:
+		candidateEvent := &model.CircleCandidateChangedEvent{}
+		payloads[0] = candidateEvent
+		publish = func() error {
+			return c.circleCandidateSubscription.CircleCandidateChangedEvent(ctx, first.CircleID, candidateEvent)
+		}
+	case model.EventKindCircle:
+		circleEvent := &model.CircleChangedEvent{}
+		payloads[0] = circleEvent
+		publish = func() error {
+			return c.circleSubscription.CircleChangedEvent(ctx, first.CircleID, circleEvent)
+		}
+	default:
+		return 0, fmt.Errorf("unknown outbox event kind %s", first.Kind)
+	}
+
+	for i, event := range events {
+		if err := json.Unmarshal([]byte(event.Payload), payloads[i]); err != nil {
+			return 0, err
+		}
+	}
+
+	if !first.Published {
+		publishErr := publish()
+
+		for i, event := range events {
+			if encodedPayload, err := json.Marshal(payloads[i]); err == nil {
+				event.Payload = string(encodedPayload)
+			}
+		}
+
+		if publishErr != nil {
+			return 0, publishErr
+		}
+
+		for _, event := range events {
+			event.Published = true
+		}
+	}
+
+	// the webhook deliveries and notifications are created once per
+	// outbox event, a retry of the event does not duplicate them
+	for i, event := range events {
+		if err := c.webhookSubscription.CircleEvent(ctx, event.ID, event.CircleID, payloads[i]); err != nil {
+			return i, err
+		}
+
+		if err := c.notificationSubscription.CircleEvent(ctx, event.ID, event.CircleID, payloads[i]); err != nil {
+			return i, err
+		}
+	}
+
+	return len(events), nil
+}
+
+// backoff before the next delivery attempt, that doubles with every attempt
+func (c *outboxService) backoff(attempts int) time.Duration {
+	backoff := time.Duration(c.config.Outbox.Backoff) * time.Millisecond
+
+	if backoff <= 0 {
+		backoff = defaultOutboxBackoff
+	}
+
+	for i := 1; i < attempts && backoff < outboxMaxBackoff; i++ {
+		backoff *= 2
+	}
+
+	if backoff > outboxMaxBackoff {
+		return outboxMaxBackoff
+	}
+
+	return backoff
+}
+
+func (c *outboxService) batchSize() int {
+	if c.config.Outbox.BatchSize > 0 {
+		return c.config.Outbox.BatchSize
+	}
+	return defaultOutboxBatchSize
+}
+
+func (c *outboxService) maxAttempts() int {
+	if c.config.Outbox.MaxAttempts > 0 {
+		return c.config.Outbox.MaxAttempts
+	}
+	return defaultOutboxMaxAttempts
+}
@@ -18,19 +18,33 @@ type RankingService interface {
 	LastViewedRankings(
 		ctx context.Context,
 	) ([]*model.RankingLastViewedResponse, error)
+	RankingChanges(
+		ctx context.Context,
+		circleId int64,
+		since int64,
+	) (*model.EventChangesResponse, error)
+	Turnout(
+		ctx context.Context,
+		circleId int64,
+	) (*model.CircleTurnout, error)
+	Quorum(
+		ctx context.Context,
+		circleId int64,
+	) (*model.CircleQuorum, error)
 }
 
 type RankingRepository interface {
 	CircleById(id int64) (*model.Circle, error)
 	RankingsByCircleId(circleId int64) ([]*model.Ranking, error)
-	Votes(circleId int64) ([]*model.Vote, error)
-	CountsVotesOfCandidateByCircleId(circleId int64, candidateId int64) (int64, error)
-	RankingByCircleId(circleId int64, identityId string) (*model.Ranking, error)
+	RankingCacheItems(circleId int64) ([]*model.RankingCacheItem, error)
 	CreateNewRankingLastViewed(
 		circleId int64,
 		identityId string,
 	) (*model.RankingLastViewed, error)
 	RankingsLastViewedByUserIdentityId(identityId string) ([]*model.RankingLastViewed, error)
+	CircleTurnout(circleId int64) (*model.CircleTurnout, error)
+	IsVoterInCircle(userIdentityId string, circleId int64) (bool, error)
+	IsCandidateInCircle(userIdentityId string, circleId int64) (bool, error)
 }
 
 type RankingCache interface {
@@ -50,31 +64,46 @@ type RankingCache interface {
 	) error
 }
 
+type RankingEventLog interface {
+	Changes(
+		ctx context.Context,
+		circleId int64,
+		since int64,
+	) (*model.EventChangesResponse, error)
+}
+
 type rankingService struct {
-	storage RankingRepository
-	cache   RankingCache
-	config  *config.Config
-	log     logger.Logger
+	storage    RankingRepository
+	cache      RankingCache
+	cacheState RankingCacheState
+	eventLog   RankingEventLog
+	config     *config.Config
+	log        logger.Logger
 }
 
 func NewRankingService(
 	circleRepo RankingRepository,
 	cache RankingCache,
+	cacheState RankingCacheState,
+	eventLog RankingEventLog,
 	config *config.Config,
 	log logger.Logger,
 ) RankingService {
 	return &rankingService{
-		storage: circleRepo,
-		cache:   cache,
-		config:  config,
-		log:     log,
+		storage:    circleRepo,
+		cache:      cache,
+		cacheState: cacheState,
+		eventLog:   eventLog,
+		config:     config,
+		log:        log,
 	}
 }
 
 // Rankings from the circle with the given circle id.
-// It returns always the ranking list from the cache.
+// It returns the ranking list from the cache.
 // It first checks whether some votes already exists for this circle in the cache
 // otherwise it will build up the cache with the votes for this circle.
+// If the cache is unavailable, the ranking list is computed from the database.
 // If the circle hasn't any votes, an empty list will be returned.
 func (c *rankingService) Rankings(
 	ctx context.Context,
@@ -109,18 +138,24 @@ func (c *rankingService) Rankings(
 		return c.mapRankingToRankingResponse(rankings), nil
 	}
 
+	if !c.cacheState.IsAvailable(circleId) {
+		return c.storageRankings(circleId, authClaims.Subject)
+	}
+
 	exists, err := c.cache.ExistsRankingListForCircle(ctx, circleId)
 
 	if err != nil {
 		c.log.Errorf("error for check if ranking list exists for circle with id %d: %s", circleId, err)
-		return nil, err
+		c.cacheState.MarkUnavailable(circleId)
+		return c.storageRankings(circleId, authClaims.Subject)
 	}
 
 	if !exists {
 		isEmpty, err := c.buildCacheRankingList(ctx, circleId)
 
 		if err != nil {
-			return nil, err
+			c.cacheState.MarkUnavailable(circleId)
+			return c.storageRankings(circleId, authClaims.Subject)
 		}
 
 		if isEmpty {
@@ -132,7 +167,9 @@ func (c *rankingService) Rankings(
 	rankings, err := c.cache.RankingList(ctx, circleId, nil)
 
 	if err != nil {
-		return nil, err
+		c.log.Errorf("error reading ranking list for circle with id %d: %s", circleId, err)
+		c.cacheState.MarkUnavailable(circleId)
+		return c.storageRankings(circleId, authClaims.Subject)
 	}
 
 	_, _ = c.storage.CreateNewRankingLastViewed(circleId, authClaims.Subject)
@@ -140,6 +177,23 @@ func (c *rankingService) Rankings(
 	return rankings, nil
 }
 
+// storageRankings computes the ranking list of the circle from the database,
+// while the ranking cache is unavailable.
+func (c *rankingService) storageRankings(
+	circleId int64,
+	identityId string,
+) ([]*model.RankingResponse, error) {
+	rankings, err := c.storage.RankingsByCircleId(circleId)
+
+	if err != nil && !database.RecordNotFound(err) {
+		return nil, err
+	}
+
+	_, _ = c.storage.CreateNewRankingLastViewed(circleId, identityId)
+
+	return computeRankingResponses(rankings), nil
+}
+
 func (c *rankingService) LastViewedRankings(
 	ctx context.Context,
 ) ([]*model.RankingLastViewedResponse, error) {
@@ -184,14 +238,89 @@ func (c *rankingService) LastViewedRankings(
 	return lastViewedRankingsResponse, nil
 }
 
+// RankingChanges of the circle with the given circle id, that have been
+// published after the given sequence number.
+// If the changes are not complete, the client must fetch the rankings again.
+func (c *rankingService) RankingChanges(
+	ctx context.Context,
+	circleId int64,
+	since int64,
+) (*model.EventChangesResponse, error) {
+	authClaims, err := routerContext.ContextToAuthClaims(ctx)
+
+	if err != nil {
+		c.log.Errorf("error getting auth claims: %s", err)
+		return nil, err
+	}
+
+	circle, err := c.storage.CircleById(circleId)
+
+	if err != nil {
+		return nil, err
+	}
+
+	eligibleToBeInCircle, err := c.eligibleToBeInCircle(authClaims.Subject, circle)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if !eligibleToBeInCircle {
+		c.log.Infof(
+			"user is not eligible to interact with circle: user %s, circle ID %d",
+			authClaims.Subject,
+			circle.ID,
+		)
+		return nil, fmt.Errorf("user is not eligible to interact with circle")
+	}
+
+	changes, err := c.eventLog.Changes(ctx, circleId, since)
+
+	if err != nil {
+		c.log.Errorf("error reading changes for circle id %d since %d: %s", circleId, since, err)
+		return nil, err
+	}
+
+	return changes, nil
+}
+
+// determines, when the circle is private, if the user is eligible to
+// interact with the circle
+func (c *rankingService) eligibleToBeInCircle(
+	userIdentityId string,
+	circle *model.Circle,
+) (bool, error) {
+	if !circle.Private {
+		return true, nil
+	}
+
+	if userIdentityId == circle.CreatedFrom {
+		return true, nil
+	}
+
+	ok, err := c.storage.IsVoterInCircle(userIdentityId, circle.ID)
+
+	if err != nil && !database.RecordNotFound(err) {
+		return false, err
+	}
+
+	if ok {
+		return true, nil
+	}
+
+	return c.storage.IsCandidateInCircle(userIdentityId, circle.ID)
+}
+
 // buildCacheRankingList for the given circle.
+// The vote counts of all candidates are aggregated in one query and
+// written to the cache in one go.
 // Returns true if the circle does not contain any votes
 // (has an empty ranking list), otherwise false or an error if any occurs.
 func (c *rankingService) buildCacheRankingList(
 	ctx context.Context,
 	circleId int64,
 ) (bool, error) {
-	votes, err := c.storage.Votes(circleId)
+	rankingCacheItems, err := c.storage.RankingCacheItems(circleId)
 
 	switch {
 	case err != nil && !database.RecordNotFound(err):
@@ -199,36 +328,12 @@ func (c *rankingService) buildCacheRankingList(
 			c.log.Errorf("error building up ranking list for circle id %d: %s", circleId, err)
 			return false, err
 		}
-	case database.RecordNotFound(err):
+	case database.RecordNotFound(err) || len(rankingCacheItems) == 0:
 		{
 			return true, nil
 		}
 	default:
 		{
-			var rankingCacheItems []*model.RankingCacheItem
-			for _, vote := range votes {
-				voteCount, err := c.storage.CountsVotesOfCandidateByCircleId(circleId, vote.Candidate.ID)
-
-				if err != nil {
-					c.log.Errorf("error getting vote count for candidate id %d: %s", vote.Candidate.ID, err)
-					return false, err
-				}
-
-				ranking, err := c.storage.RankingByCircleId(circleId, vote.Candidate.Candidate)
-
-				if err != nil {
-					c.log.Errorf("error reading by circle id %d ranking: %s", circleId, err)
-					return false, err
-				}
-
-				rankingCacheItem := &model.RankingCacheItem{
-					Candidate: vote.Candidate,
-					Ranking:   ranking,
-					VoteCount: voteCount,
-				}
-				rankingCacheItems = append(rankingCacheItems, rankingCacheItem)
-			}
-
 			err := c.cache.BuildRankingList(ctx, circleId, rankingCacheItems)
 
 			return false, err
@@ -236,20 +341,119 @@ func (c *rankingService) buildCacheRankingList(
 	}
 }
 
+// Turnout of the voters of the circle with the given circle id,
+// that voted for any candidate or abstained.
+func (c *rankingService) Turnout(
+	ctx context.Context,
+	circleId int64,
+) (*model.CircleTurnout, error) {
+	_, err := routerContext.ContextToAuthClaims(ctx)
+
+	if err != nil {
+		c.log.Errorf("error getting auth claims: %s", err)
+		return nil, err
+	}
+
+	circle, err := c.storage.CircleById(circleId)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return c.storage.CircleTurnout(circle.ID)
+}
+
+// Quorum progress of the circle while it is hot, nil in any other stage
+func (c *rankingService) Quorum(
+	ctx context.Context,
+	circleId int64,
+) (*model.CircleQuorum, error) {
+	_, err := routerContext.ContextToAuthClaims(ctx)
+
+	if err != nil {
+		c.log.Errorf("error getting auth claims: %s", err)
+		return nil, err
+	}
+
+	circle, err := c.storage.CircleById(circleId)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if circle.Stage != model.CircleStageHot {
+		return nil, nil
+	}
+
+	turnout, err := c.storage.CircleTurnout(circle.ID)
+
+	if err != nil {
+		return nil, err
+	}
+
+	rankings, err := c.storage.RankingsByCircleId(circle.ID)
+
+	if err != nil && !database.RecordNotFound(err) {
+		return nil, err
+	}
+
+	return model.NewCircleQuorum(circle.QuorumTurnout, circle.QuorumWinnerVotes, turnout, rankings), nil
+}
+
 func (c *rankingService) mapRankingToRankingResponse(rankings []*model.Ranking) []*model.RankingResponse {
 	var responses []*model.RankingResponse
 	for _, ranking := range rankings {
 		response := &model.RankingResponse{
-			CreatedAt:    ranking.CreatedAt,
-			UpdatedAt:    ranking.UpdatedAt,
-			IdentityID:   ranking.IdentityID,
-			Placement:    ranking.Placement,
-			ID:           ranking.ID,
-			CandidateID:  0,
-			Number:       ranking.Number,
-			Votes:        ranking.Votes,
-			IndexedOrder: 0,
-			CircleID:     ranking.CircleID,
+			CreatedAt:      ranking.CreatedAt,
+			UpdatedAt:      ranking.UpdatedAt,
+			IdentityID:     ranking.IdentityID,
+			NoneOfTheAbove: ranking.IdentityID == model.NoneOfTheAboveCandidate,
+			Placement:      ranking.Placement,
+			ID:             ranking.ID,
+			CandidateID:    0,
+			Number:         ranking.Number,
+			Total:          ranking.Total,
+			Average:        ranking.Average,
+			Ballots:        ranking.Ballots,
+			IndexedOrder:   0,
+			CircleID:       ranking.CircleID,
+			Score:          ranking.Score,
+		}
+		responses = append(responses, response)
+	}
+
+	return responses
+}
+
+// computeRankingResponses of the rankings, that are ordered by the score.
+// The placement numbers are computed the same way as for the cached
+// ranking list, so that rankings with the same score share the same number.
+func computeRankingResponses(rankings []*model.Ranking) []*model.RankingResponse {
+	responses := make([]*model.RankingResponse, 0)
+	placementNumber := int64(0)
+	score := float64(0)
+
+	for index, ranking := range rankings {
+		if index == 0 || score != ranking.Score {
+			score = ranking.Score
+			placementNumber++
+		}
+
+		response := &model.RankingResponse{
+			CreatedAt:      ranking.CreatedAt,
+			UpdatedAt:      ranking.UpdatedAt,
+			IdentityID:     ranking.IdentityID,
+			NoneOfTheAbove: ranking.IdentityID == model.NoneOfTheAboveCandidate,
+			Placement:      model.PlacementNeutral,
+			ID:             ranking.ID,
+			CandidateID:    0,
+			Number:         placementNumber,
+			Total:          ranking.Total,
+			Average:        ranking.Average,
+			Ballots:        ranking.Ballots,
+			IndexedOrder:   int64(index),
+			CircleID:       ranking.CircleID,
+			Score:          ranking.Score,
 		}
 		responses = append(responses, response)
 	}
@@ -5,7 +5,7 @@ import (
 	"fmt"
 	logger "github.com/VerzCar/vyf-lib-logger"
 	"github.com/VerzCar/vyf-vote-circle/api/model"
-	"github.com/ably/ably-go/ably"
+	"github.com/VerzCar/vyf-vote-circle/app/pubsub"
 )
 
 type RankingSubscriptionService interface {
@@ -17,16 +17,19 @@ type RankingSubscriptionService interface {
 }
 
 type rankingSubscriptionService struct {
-	pubSubService *ably.Realtime
+	pubSubService pubsub.Client
+	eventLog      EventLogService
 	log           logger.Logger
 }
 
 func NewRankingSubscriptionService(
-	pubSubService *ably.Realtime,
+	pubSubService pubsub.Client,
+	eventLog EventLogService,
 	log logger.Logger,
 ) RankingSubscriptionService {
 	return &rankingSubscriptionService{
 		pubSubService: pubSubService,
+		eventLog:      eventLog,
 		log:           log,
 	}
 }
@@ -39,21 +42,30 @@ func (s *rankingSubscriptionService) RankingChangedEvent(
 	circleId int64,
 	events []*model.RankingChangedEvent,
 ) error {
-	channelName := fmt.Sprintf("circle-%d:rankings", circleId)
-	msgName := "ranking-changed"
+	channelName := rankingChannelName(circleId)
+	msgName := model.EventNameRankingChanged
 
-	messages := make([]*ably.Message, 0)
+	sequencedEvents := make([]model.SequencedEvent, 0, len(events))
 
 	for _, event := range events {
-		message := &ably.Message{
+		sequencedEvents = append(sequencedEvents, event)
+	}
+
+	// the events are published even if they cannot be logged,
+	// clients will then detect the gap in the sequence
+	_ = s.eventLog.Append(ctx, circleId, model.EventKindRanking, sequencedEvents...)
+
+	messages := make([]*pubsub.Message, 0)
+
+	for _, event := range events {
+		message := &pubsub.Message{
 			Name: msgName,
 			Data: event,
 		}
 		messages = append(messages, message)
 	}
 
-	channel := s.pubSubService.Channels.Get(channelName)
-	err := channel.PublishMultiple(ctx, messages)
+	err := s.pubSubService.Publish(ctx, channelName, messages...)
 
 	if err != nil {
 		s.log.Errorf(
@@ -77,3 +89,7 @@ func CreateRankingChangedEvent(
 		Ranking:   ranking,
 	}
 }
+
+func rankingChannelName(circleId int64) string {
+	return fmt.Sprintf("circle-%d:rankings", circleId)
+}
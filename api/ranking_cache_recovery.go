@@ -0,0 +1,188 @@
+package api
+
+import (
+	"context"
+	logger "github.com/VerzCar/vyf-lib-logger"
+	"github.com/VerzCar/vyf-vote-circle/api/model"
+	"github.com/VerzCar/vyf-vote-circle/app/config"
+	"github.com/VerzCar/vyf-vote-circle/app/database"
+	"sync"
+	"time"
+)
+
+// defaultRecoveryInterval is used if no recovery interval is configured.
+const defaultRecoveryInterval = 10 * time.Second
+
+// RankingCacheRecoveryService keeps track of the availability of the
+// ranking cache. If the cache is unavailable, the ranking lists of the
+// affected circles are marked as dirty and will be rebuilt from the database
+// as soon as the cache is reachable again.
+type RankingCacheRecoveryService interface {
+	RankingCacheState
+	Run(ctx context.Context)
+}
+
+// RankingCacheState of the ranking lists of the circles in the cache.
+type RankingCacheState interface {
+	IsAvailable(circleId int64) bool
+	MarkDirty(circleId int64)
+	MarkUnavailable(circleId int64)
+}
+
+type RankingCacheRecoveryRepository interface {
+	RankingCacheItems(circleId int64) ([]*model.RankingCacheItem, error)
+}
+
+type RankingCacheRecoveryCache interface {
+	Ping(ctx context.Context) error
+	BuildRankingList(
+		ctx context.Context,
+		circleId int64,
+		rankingCacheItems []*model.RankingCacheItem,
+	) error
+	RemoveRankingList(
+		ctx context.Context,
+		circleId int64,
+	) error
+}
+
+type rankingCacheRecoveryService struct {
+	storage     RankingCacheRecoveryRepository
+	cache       RankingCacheRecoveryCache
+	mu          sync.Mutex
+	unavailable bool
+	// dirty circles with a generation, that is increased on every
+	// change to the circle while the ranking list is out of sync.
+	dirty  map[int64]uint64
+	config *config.Config
+	log    logger.Logger
+}
+
+func NewRankingCacheRecoveryService(
+	rankingRepo RankingCacheRecoveryRepository,
+	cache RankingCacheRecoveryCache,
+	config *config.Config,
+	log logger.Logger,
+) RankingCacheRecoveryService {
+	return &rankingCacheRecoveryService{
+		storage: rankingRepo,
+		cache:   cache,
+		dirty:   make(map[int64]uint64),
+		config:  config,
+		log:     log,
+	}
+}
+
+// IsAvailable checks whether the ranking list of the circle
+// can be served from and written to the cache.
+func (c *rankingCacheRecoveryService) IsAvailable(circleId int64) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	_, isDirty := c.dirty[circleId]
+
+	return !c.unavailable && !isDirty
+}
+
+// MarkDirty the ranking list of the circle, as it is out of sync
+// with the database and must be rebuilt.
+func (c *rankingCacheRecoveryService) MarkDirty(circleId int64) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.dirty[circleId]++
+}
+
+// MarkUnavailable the cache, after an operation for the circle failed.
+// The ranking list of the circle is marked as dirty.
+func (c *rankingCacheRecoveryService) MarkUnavailable(circleId int64) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if !c.unavailable {
+		c.log.Warnf("ranking cache unavailable, serving rankings from database")
+	}
+
+	c.unavailable = true
+	c.dirty[circleId]++
+}
+
+// Run checks the availability of the cache in the configured interval
+// and rebuilds the ranking lists of all dirty circles once the cache is reachable.
+// Blocks until the context is done.
+func (c *rankingCacheRecoveryService) Run(ctx context.Context) {
+	interval := time.Duration(c.config.Cache.RecoveryInterval) * time.Second
+
+	if interval <= 0 {
+		interval = defaultRecoveryInterval
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			c.recover(ctx)
+		}
+	}
+}
+
+// recover the cache if it is reachable by rebuilding all dirty circles.
+func (c *rankingCacheRecoveryService) recover(ctx context.Context) {
+	if err := c.cache.Ping(ctx); err != nil {
+		c.mu.Lock()
+		c.unavailable = true
+		c.mu.Unlock()
+		return
+	}
+
+	c.mu.Lock()
+	if c.unavailable {
+		c.log.Infof("ranking cache reachable again, rebuilding %d dirty circles", len(c.dirty))
+	}
+	c.unavailable = false
+
+	dirty := make(map[int64]uint64, len(c.dirty))
+	for circleId, generation := range c.dirty {
+		dirty[circleId] = generation
+	}
+	c.mu.Unlock()
+
+	for circleId, generation := range dirty {
+		if err := c.rebuild(ctx, circleId); err != nil {
+			c.log.Errorf("error rebuilding ranking list for circle id %d: %s", circleId, err)
+			continue
+		}
+
+		c.mu.Lock()
+		// the circle changed during the rebuild, therefore
+		// it stays dirty and will be rebuilt on the next run
+		if c.dirty[circleId] == generation {
+			delete(c.dirty, circleId)
+		}
+		c.mu.Unlock()
+	}
+}
+
+// rebuild the ranking list of the circle from the database.
+func (c *rankingCacheRecoveryService) rebuild(ctx context.Context, circleId int64) error {
+	err := c.cache.RemoveRankingList(ctx, circleId)
+
+	if err != nil {
+		return err
+	}
+
+	rankingCacheItems, err := c.storage.RankingCacheItems(circleId)
+
+	switch {
+	case err != nil && !database.RecordNotFound(err):
+		return err
+	case database.RecordNotFound(err) || len(rankingCacheItems) == 0:
+		return nil
+	}
+
+	return c.cache.BuildRankingList(ctx, circleId, rankingCacheItems)
+}
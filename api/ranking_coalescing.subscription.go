@@ -0,0 +1,165 @@
+package api
+
+import (
+	"context"
+	"expvar"
+	logger "github.com/VerzCar/vyf-lib-logger"
+	"github.com/VerzCar/vyf-vote-circle/api/model"
+	"github.com/VerzCar/vyf-vote-circle/app/pubsub"
+)
+
+// rankingCoalescingMetrics are exposed via expvar. The compression ratio
+// is the count of received ranking changes per published ranking change.
+var rankingCoalescingMetrics = expvar.NewMap("rankingCoalescing")
+
+var (
+	rankingCoalescingReceivedEvents    = new(expvar.Int)
+	rankingCoalescingPublishedEvents   = new(expvar.Int)
+	rankingCoalescingPublishedMessages = new(expvar.Int)
+)
+
+func init() {
+	rankingCoalescingMetrics.Set("receivedEvents", rankingCoalescingReceivedEvents)
+	rankingCoalescingMetrics.Set("publishedEvents", rankingCoalescingPublishedEvents)
+	rankingCoalescingMetrics.Set("publishedMessages", rankingCoalescingPublishedMessages)
+	rankingCoalescingMetrics.Set(
+		"compressionRatio", expvar.Func(
+			func() any {
+				published := rankingCoalescingPublishedEvents.Value()
+
+				if published == 0 {
+					return 0
+				}
+
+				return float64(rankingCoalescingReceivedEvents.Value()) / float64(published)
+			},
+		),
+	)
+}
+
+// rankingChanges of a circle coalesced within the coalescing window
+type rankingChanges struct {
+	// order of the ranking ids, as they have been changed first
+	order  []int64
+	events map[int64]*model.RankingChangedEvent
+}
+
+type rankingCoalescingSubscriptionService struct {
+	pubSubService pubsub.Client
+	eventLog      EventLogService
+	log           logger.Logger
+}
+
+// NewRankingCoalescingSubscriptionService creates a ranking subscription service,
+// that merges the ranking changes of a circle into the minimal set of changes
+// and publishes them as one message. The outbox dispatcher passes the ranking
+// changes of a circle within the configured coalescing window at once.
+func NewRankingCoalescingSubscriptionService(
+	pubSubService pubsub.Client,
+	eventLog EventLogService,
+	log logger.Logger,
+) RankingSubscriptionService {
+	return &rankingCoalescingSubscriptionService{
+		pubSubService: pubSubService,
+		eventLog:      eventLog,
+		log:           log,
+	}
+}
+
+// RankingChangedEvent merges the changed rankings of the circle and
+// publishes the compacted changes as one message.
+func (s *rankingCoalescingSubscriptionService) RankingChangedEvent(
+	ctx context.Context,
+	circleId int64,
+	events []*model.RankingChangedEvent,
+) error {
+	rankingCoalescingReceivedEvents.Add(int64(len(events)))
+
+	changes := &rankingChanges{events: make(map[int64]*model.RankingChangedEvent)}
+
+	for _, event := range events {
+		changes.merge(event)
+	}
+
+	compactedEvents := changes.compacted()
+
+	if len(compactedEvents) == 0 {
+		return nil
+	}
+
+	sequencedEvents := make([]model.SequencedEvent, 0, len(compactedEvents))
+
+	for _, event := range compactedEvents {
+		sequencedEvents = append(sequencedEvents, event)
+	}
+
+	// the events are published even if they cannot be logged,
+	// clients will then detect the gap in the sequence
+	_ = s.eventLog.Append(ctx, circleId, model.EventKindRanking, sequencedEvents...)
+
+	channelName := rankingChannelName(circleId)
+	msgName := model.EventNameRankingsChanged
+
+	message := &pubsub.Message{
+		Name: msgName,
+		Data: &model.RankingsChangedEvent{
+			Events:   compactedEvents,
+			Sequence: compactedEvents[len(compactedEvents)-1].Sequence,
+		},
+	}
+
+	if err := s.pubSubService.Publish(ctx, channelName, message); err != nil {
+		s.log.Errorf(
+			"could not publish message to channel: %s with message name: %s cause: %s",
+			channelName,
+			msgName,
+			err,
+		)
+		return err
+	}
+
+	rankingCoalescingPublishedEvents.Add(int64(len(compactedEvents)))
+	rankingCoalescingPublishedMessages.Add(1)
+
+	return nil
+}
+
+// merge the event into the changes of the ranking. The latest ranking wins,
+// a created ranking stays created and a ranking that has been created
+// and deleted within the window is dropped.
+func (r *rankingChanges) merge(event *model.RankingChangedEvent) {
+	rankingId := event.Ranking.ID
+	prev, ok := r.events[rankingId]
+
+	if !ok {
+		r.order = append(r.order, rankingId)
+		r.events[rankingId] = event
+		return
+	}
+
+	switch {
+	case prev == nil && event.Operation == model.EventOperationDeleted:
+		return
+	case prev == nil:
+		r.events[rankingId] = event
+	case prev.Operation == model.EventOperationCreated && event.Operation == model.EventOperationDeleted:
+		r.events[rankingId] = nil
+	case prev.Operation == model.EventOperationCreated:
+		r.events[rankingId] = CreateRankingChangedEvent(model.EventOperationCreated, event.Ranking)
+	default:
+		r.events[rankingId] = event
+	}
+}
+
+// compacted changes in the order the rankings have been changed first
+func (r *rankingChanges) compacted() []*model.RankingChangedEvent {
+	events := make([]*model.RankingChangedEvent, 0, len(r.order))
+
+	for _, rankingId := range r.order {
+		if event := r.events[rankingId]; event != nil {
+			events = append(events, event)
+		}
+	}
+
+	return events
+}
@@ -69,7 +69,7 @@ func (m mockRankingRepository) RankingsByCircleId(circleId int64) ([]*model.Rank
 			ID:         1,
 			IdentityID: "1",
 			Number:     1,
-			Votes:      0,
+			Total:      0,
 			Placement:  "",
 			CircleID:   circleId,
 			Circle:     nil,
@@ -80,7 +80,7 @@ func (m mockRankingRepository) RankingsByCircleId(circleId int64) ([]*model.Rank
 			ID:         2,
 			IdentityID: "1",
 			Number:     2,
-			Votes:      0,
+			Total:      0,
 			Placement:  "",
 			CircleID:   circleId,
 			Circle:     nil,
@@ -127,7 +127,7 @@ func (m mockRankingCache) RankingList(ctx context.Context, circleId int64) ([]*m
 			ID:         1,
 			IdentityID: "1",
 			Number:     1,
-			Votes:      0,
+			Total:      0,
 			Placement:  "",
 			CircleID:   circleId,
 			Circle:     nil,
@@ -138,7 +138,7 @@ func (m mockRankingCache) RankingList(ctx context.Context, circleId int64) ([]*m
 			ID:         2,
 			IdentityID: "1",
 			Number:     2,
-			Votes:      0,
+			Total:      0,
 			Placement:  "",
 			CircleID:   circleId,
 			Circle:     nil,
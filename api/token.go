@@ -2,39 +2,82 @@ package api
 
 import (
 	"context"
+	"encoding/json"
 	logger "github.com/VerzCar/vyf-lib-logger"
+	"github.com/VerzCar/vyf-vote-circle/api/model"
 	"github.com/VerzCar/vyf-vote-circle/app/config"
+	"github.com/VerzCar/vyf-vote-circle/app/pubsub"
 	routerContext "github.com/VerzCar/vyf-vote-circle/app/router/ctx"
 	"github.com/ably/ably-go/ably"
+	"time"
+)
+
+const (
+	// defaultAblyTokenTTL is used if no token ttl is configured.
+	defaultAblyTokenTTL = 10 * time.Minute
+	// maxAblyTokenCircles the capabilities of a token are limited to.
+	maxAblyTokenCircles = 100
 )
 
 type TokenService interface {
 	GenerateAblyToken(
 		ctx context.Context,
+		tokenRequest *model.AblyTokenRequest,
 	) (*ably.TokenRequest, error)
 }
 
+type TokenRepository interface {
+	CircleIdsOfUser(userIdentityId string, limit int) ([]int64, error)
+}
+
+type TokenAblyIssuer interface {
+	CreateTokenRequest(params *ably.TokenParams) (*ably.TokenRequest, error)
+}
+
+type TokenCircleService interface {
+	EligibleToBeInCircle(
+		ctx context.Context,
+		circleId int64,
+	) (bool, error)
+}
+
 type tokenService struct {
-	pubSubService *ably.Realtime
+	storage       TokenRepository
+	circleService TokenCircleService
+	tokenIssuer   TokenAblyIssuer
 	config        *config.Config
 	log           logger.Logger
 }
 
 func NewTokenService(
-	pubSubService *ably.Realtime,
+	tokenRepo TokenRepository,
+	circleService TokenCircleService,
+	tokenIssuer TokenAblyIssuer,
 	config *config.Config,
 	log logger.Logger,
 ) TokenService {
 	return &tokenService{
-		pubSubService: pubSubService,
+		storage:       tokenRepo,
+		circleService: circleService,
+		tokenIssuer:   tokenIssuer,
 		config:        config,
 		log:           log,
 	}
 }
 
+// GenerateAblyToken generates a short living token request, that allows
+// the user to subscribe to the channels of the circles the user has created
+// or is a voter or candidate of. The requested circles are added, if the user
+// is eligible to be in the circle. As the capabilities are computed on every
+// request, a new token reflects the circles the user has joined or left.
 func (t *tokenService) GenerateAblyToken(
 	ctx context.Context,
+	tokenRequest *model.AblyTokenRequest,
 ) (*ably.TokenRequest, error) {
+	if t.tokenIssuer == nil {
+		return nil, pubsub.ErrTokenRequestUnsupported
+	}
+
 	authClaims, err := routerContext.ContextToAuthClaims(ctx)
 
 	if err != nil {
@@ -42,11 +85,28 @@ func (t *tokenService) GenerateAblyToken(
 		return nil, err
 	}
 
+	circleIds, err := t.storage.CircleIdsOfUser(authClaims.Subject, maxAblyTokenCircles)
+
+	if err != nil {
+		return nil, err
+	}
+
+	circleIds = t.addEligibleCircles(ctx, circleIds, tokenRequest.CircleIDs)
+
+	capability, err := ablyCapability(circleIds)
+
+	if err != nil {
+		t.log.Errorf("could not create capability for user id %s: %s", authClaims.Subject, err)
+		return nil, err
+	}
+
 	params := &ably.TokenParams{
-		ClientID: authClaims.PrivateClaims.ClientId,
+		ClientID:   authClaims.PrivateClaims.ClientId,
+		Capability: capability,
+		TTL:        t.tokenTTL().Milliseconds(),
 	}
 
-	tokenRequest, err := t.pubSubService.Auth.CreateTokenRequest(params)
+	ablyTokenRequest, err := t.tokenIssuer.CreateTokenRequest(params)
 
 	if err != nil {
 		t.log.Errorf(
@@ -56,5 +116,81 @@ func (t *tokenService) GenerateAblyToken(
 		return nil, err
 	}
 
-	return tokenRequest, nil
+	return ablyTokenRequest, nil
+}
+
+// addEligibleCircles adds the requested circles, that are not already
+// part of the circles and the user is eligible to be in.
+func (t *tokenService) addEligibleCircles(
+	ctx context.Context,
+	circleIds []int64,
+	requestedCircleIds []int64,
+) []int64 {
+	known := make(map[int64]struct{}, len(circleIds))
+
+	for _, circleId := range circleIds {
+		known[circleId] = struct{}{}
+	}
+
+	for _, circleId := range requestedCircleIds {
+		if len(circleIds) >= maxAblyTokenCircles {
+			break
+		}
+
+		if _, ok := known[circleId]; ok {
+			continue
+		}
+
+		eligible, err := t.circleService.EligibleToBeInCircle(ctx, circleId)
+
+		if err != nil || !eligible {
+			t.log.Infof("circle id %d is not added to the token capability", circleId)
+			continue
+		}
+
+		known[circleId] = struct{}{}
+		circleIds = append(circleIds, circleId)
+	}
+
+	return circleIds
+}
+
+func (t *tokenService) tokenTTL() time.Duration {
+	ttl := time.Duration(t.config.Ably.TokenTTL) * time.Second
+
+	if ttl <= 0 {
+		return defaultAblyTokenTTL
+	}
+
+	return ttl
+}
+
+// ablyCapability of the circles, that only allows to subscribe
+// to the channels of the circles.
+func ablyCapability(circleIds []int64) (string, error) {
+	capability := make(map[string][]string, len(circleIds)*4)
+
+	for _, circleId := range circleIds {
+		for _, channel := range circleChannelNames(circleId) {
+			capability[channel] = []string{"subscribe"}
+		}
+	}
+
+	data, err := json.Marshal(capability)
+
+	if err != nil {
+		return "", err
+	}
+
+	return string(data), nil
+}
+
+// circleChannelNames of all the channels the events of the circle are published to
+func circleChannelNames(circleId int64) []string {
+	return []string{
+		rankingChannelName(circleId),
+		circleVoterChannelName(circleId),
+		circleCandidateChannelName(circleId),
+		circleChannelName(circleId),
+	}
 }
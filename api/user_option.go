@@ -13,10 +13,20 @@ type UserOptionService interface {
 	UserOption(
 		ctx context.Context,
 	) (*model.UserOptionResponse, error)
+	UserOptionOfUser(
+		userIdentityId string,
+	) (*model.UserOptionResponse, error)
+	Usage(
+		ctx context.Context,
+	) (*model.UserOptionUsageResponse, error)
 }
 
 type UserOptionRepository interface {
 	UserOptionByUserIdentityId(userIdentityId string) (*model.UserOption, error)
+	CircleUsagesOfUser(
+		userIdentityId string,
+		imageSrcPattern string,
+	) ([]*model.CircleUsage, error)
 }
 
 type userOptionService struct {
@@ -37,6 +47,7 @@ func NewUserOptionService(
 	}
 }
 
+// UserOption of the authenticated user
 func (c *userOptionService) UserOption(
 	ctx context.Context,
 ) (*model.UserOptionResponse, error) {
@@ -47,38 +58,137 @@ func (c *userOptionService) UserOption(
 		return nil, err
 	}
 
-	option, err := c.storage.UserOptionByUserIdentityId(authClaims.Subject)
+	return c.UserOptionOfUser(authClaims.Subject)
+}
+
+// UserOptionOfUser with the quotas of the plan of the subscription package
+// of the user. The limits stored for the user, that are not 0, override the
+// quotas of the plan. Users without an option get the plan of the smallest package.
+func (c *userOptionService) UserOptionOfUser(
+	userIdentityId string,
+) (*model.UserOptionResponse, error) {
+	option, err := c.storage.UserOptionByUserIdentityId(userIdentityId)
 
 	if err != nil && !database.RecordNotFound(err) {
-		c.log.Errorf("error ruding query of user option")
-		return c.defaultUserOption(), nil
+		c.log.Errorf("error reading user option of user %s: %s", userIdentityId, err)
+		return nil, err
 	}
 
 	if database.RecordNotFound(err) {
-		return c.defaultUserOption(), nil
+		return c.planOption(model.SubscriptionPackageS), nil
 	}
 
-	optionResponse := &model.UserOptionResponse{
-		MaxCircles:    option.MaxCircles,
-		MaxVoters:     option.MaxVoters,
-		MaxCandidates: option.MaxCandidates,
-		PrivateOption: model.UserPrivateOptionResponse{
-			MaxVoters:     option.MaxPrivateVoters,
-			MaxCandidates: option.MaxPrivateCandidates,
-		},
-	}
+	optionResponse := c.planOption(option.Package)
+	optionResponse.MaxCircles = quotaOrDefault(option.MaxCircles, optionResponse.MaxCircles)
+	optionResponse.MaxVoters = quotaOrDefault(option.MaxVoters, optionResponse.MaxVoters)
+	optionResponse.MaxCandidates = quotaOrDefault(option.MaxCandidates, optionResponse.MaxCandidates)
+	optionResponse.MaxImageUploads = quotaOrDefault(option.MaxImageUploads, optionResponse.MaxImageUploads)
+	optionResponse.MaxWebhooks = quotaOrDefault(option.MaxWebhooks, optionResponse.MaxWebhooks)
+	optionResponse.PrivateOption.MaxVoters = quotaOrDefault(
+		option.MaxPrivateVoters,
+		optionResponse.PrivateOption.MaxVoters,
+	)
+	optionResponse.PrivateOption.MaxCandidates = quotaOrDefault(
+		option.MaxPrivateCandidates,
+		optionResponse.PrivateOption.MaxCandidates,
+	)
 
 	return optionResponse, nil
 }
 
-func (c *userOptionService) defaultUserOption() *model.UserOptionResponse {
+// Usage of the quotas of the option of the authenticated user by the
+// circles the user owns
+func (c *userOptionService) Usage(
+	ctx context.Context,
+) (*model.UserOptionUsageResponse, error) {
+	authClaims, err := routerContext.ContextToAuthClaims(ctx)
+
+	if err != nil {
+		c.log.Errorf("error getting auth claims: %s", err)
+		return nil, err
+	}
+
+	option, err := c.UserOptionOfUser(authClaims.Subject)
+
+	if err != nil {
+		return nil, err
+	}
+
+	usages, err := c.storage.CircleUsagesOfUser(authClaims.Subject, circleImageSrcPattern)
+
+	if err != nil && !database.RecordNotFound(err) {
+		return nil, err
+	}
+
+	usageResponse := &model.UserOptionUsageResponse{
+		Option:       option,
+		Circles:      int64(len(usages)),
+		CircleUsages: make([]*model.CircleUsageResponse, 0, len(usages)),
+	}
+
+	for _, usage := range usages {
+		if usage.Image {
+			usageResponse.ImageUploads++
+		}
+
+		usageResponse.CircleUsages = append(
+			usageResponse.CircleUsages, &model.CircleUsageResponse{
+				Name:          usage.Name,
+				CircleID:      usage.ID,
+				Voters:        usage.Voters,
+				MaxVoters:     option.CircleMaxVoters(usage.Private),
+				Candidates:    usage.Candidates,
+				MaxCandidates: option.CircleMaxCandidates(usage.Private),
+				Webhooks:      usage.Webhooks,
+				MaxWebhooks:   option.MaxWebhooks,
+				Private:       usage.Private,
+			},
+		)
+	}
+
+	return usageResponse, nil
+}
+
+// planOption of the quotas of the plan of the given subscription package.
+// The quotas, that are not set in the plan, fall back to the circle and
+// webhook settings.
+func (c *userOptionService) planOption(subscriptionPackage model.SubscriptionPackage) *model.UserOptionResponse {
+	var plan config.Plan
+
+	switch subscriptionPackage {
+	case model.SubscriptionPackageM:
+		plan = c.config.Plans.M
+	case model.SubscriptionPackageL:
+		plan = c.config.Plans.L
+	default:
+		subscriptionPackage = model.SubscriptionPackageS
+		plan = c.config.Plans.S
+	}
+
 	return &model.UserOptionResponse{
-		MaxCircles:    int(c.config.Circle.MaxAmountPerUser),
-		MaxVoters:     c.config.Circle.MaxVoters,
-		MaxCandidates: c.config.Circle.MaxCandidates,
+		Package:         subscriptionPackage,
+		MaxCircles:      quotaOrDefault(plan.MaxCircles, int(c.config.Circle.MaxAmountPerUser)),
+		MaxVoters:       quotaOrDefault(plan.MaxVoters, c.config.Circle.MaxVoters),
+		MaxCandidates:   quotaOrDefault(plan.MaxCandidates, c.config.Circle.MaxCandidates),
+		MaxImageUploads: quotaOrDefault(plan.MaxImageUploads, int(c.config.Circle.MaxAmountPerUser)),
+		MaxWebhooks:     quotaOrDefault(plan.MaxWebhooks, c.maxWebhooksPerCircle()),
 		PrivateOption: model.UserPrivateOptionResponse{
-			MaxVoters:     c.config.Circle.Private.MaxVoters,
-			MaxCandidates: c.config.Circle.Private.MaxCandidates,
+			MaxVoters:     quotaOrDefault(plan.MaxPrivateVoters, c.config.Circle.Private.MaxVoters),
+			MaxCandidates: quotaOrDefault(plan.MaxPrivateCandidates, c.config.Circle.Private.MaxCandidates),
 		},
 	}
 }
+
+func (c *userOptionService) maxWebhooksPerCircle() int {
+	if c.config.Webhook.MaxPerCircle > 0 {
+		return c.config.Webhook.MaxPerCircle
+	}
+	return defaultWebhooksPerCircle
+}
+
+func quotaOrDefault(quota int, defaultQuota int) int {
+	if quota > 0 {
+		return quota
+	}
+	return defaultQuota
+}
@@ -9,6 +9,7 @@ import (
 	"github.com/VerzCar/vyf-vote-circle/app/config"
 	"github.com/VerzCar/vyf-vote-circle/app/database"
 	routerContext "github.com/VerzCar/vyf-vote-circle/app/router/ctx"
+	"slices"
 	"time"
 )
 
@@ -18,15 +19,51 @@ type VoteService interface {
 		circleId int64,
 		voteReq *model.VoteCreateRequest,
 	) (bool, error)
+	CreateApprovalVote(
+		ctx context.Context,
+		circleId int64,
+		voteReq *model.ApprovalVoteCreateRequest,
+	) (bool, error)
+	CreateScoreVote(
+		ctx context.Context,
+		circleId int64,
+		voteReq *model.ScoreVoteCreateRequest,
+	) (bool, error)
 	RevokeVote(
 		ctx context.Context,
 		circleId int64,
 	) (bool, error)
+	VoteDelegation(
+		ctx context.Context,
+		circleId int64,
+	) (*model.VoteDelegation, error)
+	DelegateVote(
+		ctx context.Context,
+		circleId int64,
+		delegationReq *model.VoteDelegationCreateRequest,
+	) (*model.VoteDelegation, error)
+	RevokeVoteDelegation(
+		ctx context.Context,
+		circleId int64,
+	) (bool, error)
+	RemoveCircleVoter(
+		ctx context.Context,
+		circleId int64,
+		voter *model.CircleVoter,
+		outboxEvents model.OutboxEventsCallback,
+	) error
 }
 
 type VoteRepository interface {
 	CircleById(id int64) (*model.Circle, error)
 	CircleVoterByCircleId(circleId int64, userIdentityId string) (*model.CircleVoter, error)
+	UpdateCircleVoterAbstention(
+		ctx context.Context,
+		voter *model.CircleVoter,
+		abstained bool,
+		upsertRankingCache cache.UpsertRankingCacheCallback,
+		outboxEvents model.RankingChangesOutboxEventsCallback,
+	) (*model.CircleVoter, error)
 	CircleCandidateByCircleId(
 		circleId int64,
 		userIdentityId string,
@@ -37,11 +74,33 @@ type VoteRepository interface {
 		voter *model.CircleVoter,
 		candidate *model.CircleCandidate,
 		upsertRankingCache cache.UpsertRankingCacheCallback,
+		outboxEvents model.RankingOutboxEventsCallback,
 	) (*model.RankingResponse, int64, error)
+	CreateNewVotes(
+		ctx context.Context,
+		circleId int64,
+		voter *model.CircleVoter,
+		votes []*model.Vote,
+		upsertRankingCache cache.UpsertRankingCacheCallback,
+		outboxEvents model.RankingChangesOutboxEventsCallback,
+	) ([]*model.RankingChange, error)
 	VoteByCircleId(
 		circleId int64,
 		voterId int64,
 	) (*model.Vote, error)
+	VotesByVoterId(
+		circleId int64,
+		voterId int64,
+	) ([]*model.Vote, error)
+	DeleteVotes(
+		ctx context.Context,
+		circleId int64,
+		votes []*model.Vote,
+		voter *model.CircleVoter,
+		upsertRankingCache cache.UpsertRankingCacheCallback,
+		removeRankingCache cache.RemoveRankingCacheCallback,
+		outboxEvents model.RankingChangesOutboxEventsCallback,
+	) ([]*model.RankingChange, error)
 	DeleteVote(
 		ctx context.Context,
 		circleId int64,
@@ -49,12 +108,40 @@ type VoteRepository interface {
 		voter *model.CircleVoter,
 		upsertRankingCache cache.UpsertRankingCacheCallback,
 		removeRankingCache cache.RemoveRankingCacheCallback,
+		outboxEvents model.RankingOutboxEventsCallback,
 	) (*model.RankingResponse, int64, error)
 	HasVoterVotedForCircle(
 		circleId int64,
 		voterId int64,
 	) (bool, error)
 	UpdateRanking(ranking *model.Ranking) (*model.Ranking, error)
+	VoteDelegationByVoterId(
+		circleId int64,
+		voterId int64,
+	) (*model.VoteDelegation, error)
+	SaveVoteDelegation(
+		ctx context.Context,
+		delegation *model.VoteDelegation,
+		upsertRankingCache cache.UpsertRankingCacheCallback,
+		removeRankingCache cache.RemoveRankingCacheCallback,
+		outboxEvents model.RankingChangesOutboxEventsCallback,
+	) (*model.VoteDelegation, []*model.RankingChange, error)
+	DeleteVoteDelegation(
+		ctx context.Context,
+		delegation *model.VoteDelegation,
+		upsertRankingCache cache.UpsertRankingCacheCallback,
+		removeRankingCache cache.RemoveRankingCacheCallback,
+		outboxEvents model.RankingChangesOutboxEventsCallback,
+	) ([]*model.RankingChange, error)
+	DeleteCircleVoter(
+		ctx context.Context,
+		circleId int64,
+		voterId int64,
+		upsertRankingCache cache.UpsertRankingCacheCallback,
+		removeRankingCache cache.RemoveRankingCacheCallback,
+		outboxEvents model.OutboxEventsCallback,
+		rankingOutboxEvents model.RankingChangesOutboxEventsCallback,
+	) ([]*model.RankingChange, error)
 }
 
 type VoteCache interface {
@@ -63,7 +150,6 @@ type VoteCache interface {
 		circleId int64,
 		candidate *model.CircleCandidate,
 		ranking *model.Ranking,
-		votes int64,
 	) (*model.RankingResponse, error)
 	RemoveRanking(
 		ctx context.Context,
@@ -77,60 +163,33 @@ type VoteCache interface {
 	) ([]*model.RankingResponse, error)
 }
 
-type VoteRankingSubscription interface {
-	RankingChangedEvent(
-		ctx context.Context,
-		circleId int64,
-		events []*model.RankingChangedEvent,
-	) error
-}
-
-type VoteCircleVoterSubscription interface {
-	CircleVoterChangedEvent(
-		ctx context.Context,
-		circleId int64,
-		event *model.CircleVoterChangedEvent,
-	) error
-}
-
-type VoteCircleCandidateSubscription interface {
-	CircleCandidateChangedEvent(
-		ctx context.Context,
-		circleId int64,
-		event *model.CircleCandidateChangedEvent,
-	) error
-}
-
 type voteService struct {
-	storage                     VoteRepository
-	cache                       VoteCache
-	rankingSubscription         VoteRankingSubscription
-	circleVoterSubscription     VoteCircleVoterSubscription
-	circleCandidateSubscription VoteCircleCandidateSubscription
-	config                      *config.Config
-	log                         logger.Logger
+	storage    VoteRepository
+	cache      VoteCache
+	cacheState RankingCacheState
+	config     *config.Config
+	log        logger.Logger
 }
 
 func NewVoteService(
 	circleRepo VoteRepository,
 	cache VoteCache,
-	rankingSubscription VoteRankingSubscription,
-	circleVoterSubscription VoteCircleVoterSubscription,
-	circleCandidateSubscription VoteCircleCandidateSubscription,
+	cacheState RankingCacheState,
 	config *config.Config,
 	log logger.Logger,
 ) VoteService {
 	return &voteService{
-		storage:                     circleRepo,
-		cache:                       cache,
-		rankingSubscription:         rankingSubscription,
-		circleVoterSubscription:     circleVoterSubscription,
-		circleCandidateSubscription: circleCandidateSubscription,
-		config:                      config,
-		log:                         log,
+		storage:    circleRepo,
+		cache:      cache,
+		cacheState: cacheState,
+		config:     config,
+		log:        log,
 	}
 }
 
+// CreateVote for the candidate of the request or abstains from voting.
+// An abstention counts toward the turnout of the circle, but not toward
+// any candidate and can be cast in any voting mode.
 func (c *voteService) CreateVote(
 	ctx context.Context,
 	circleId int64,
@@ -145,111 +204,176 @@ func (c *voteService) CreateVote(
 
 	voterId := authClaims.Subject
 
+	if voteReq.Abstain {
+		return c.abstain(ctx, circleId, voterId)
+	}
+
 	if voterId == voteReq.CandidateID {
 		c.log.Errorf("error voter id %s is equal candidate id: %s", voterId, voteReq.CandidateID)
 		return false, fmt.Errorf("cannot vote for yourself")
 	}
 
-	circle, err := c.storage.CircleById(circleId)
+	circle, voter, err := c.votableCircleVoter(circleId, voterId, model.VotingModeSingle)
 
 	if err != nil {
 		return false, err
 	}
 
-	if !circle.IsEditable() {
-		c.log.Infof(
-			"tried to vote for an ineditable circle with circle id %d and subject %s",
-			circleId,
-			authClaims.Subject,
-		)
-		return false, fmt.Errorf("circle is not editable")
+	candidate, err := c.committedCandidate(circleId, voteReq.CandidateID)
+
+	if err != nil {
+		return false, err
 	}
 
-	if circle.Stage == model.CircleStageCold {
-		c.log.Infof(
-			"tried to vote for an cold circle with circle id %d and subject %s",
-			circleId,
-			authClaims.Subject,
-		)
-		return false, fmt.Errorf("circle is cold")
+	// the vote may change the delegated weights of the votes of other candidates
+	if circle.Delegation {
+		return c.createVotes(ctx, circleId, voter, []*model.Vote{{Candidate: candidate, Score: 1}})
 	}
 
-	voter, err := c.storage.CircleVoterByCircleId(circleId, voterId)
+	outboxEvents := func(
+		cachedRanking *model.RankingResponse,
+		voteCount int64,
+		rankings model.RankingsCallback,
+	) ([]*model.OutboxEvent, error) {
+		operation := model.EventOperationCreated
+
+		if voteCount > 1 {
+			operation = model.EventOperationUpdated
+		}
+
+		return c.voteOutboxEvents(ctx, circleId, operation, cachedRanking, cachedRanking, rankings, voter)
+	}
+
+	_, _, err = c.storage.CreateNewVote(ctx, circleId, voter, candidate, c.upsertRankingCache, outboxEvents)
 
 	if err != nil {
-		c.log.Errorf("error voter id %s not in circle: %s", voterId, err)
 		return false, err
 	}
 
-	candidate, err := c.storage.CircleCandidateByCircleId(circleId, voteReq.CandidateID)
+	//TODO: do not only send events also update rankings table, or do it async
+	// in the background from time to time, as votes are already persisted.
+
+	return true, nil
+}
+
+// CreateApprovalVote approves the given candidates in an approval circle.
+// The votes of all candidates are created at once, a voter can
+// approve candidates only once until the vote is revoked.
+func (c *voteService) CreateApprovalVote(
+	ctx context.Context,
+	circleId int64,
+	voteReq *model.ApprovalVoteCreateRequest,
+) (bool, error) {
+	authClaims, err := routerContext.ContextToAuthClaims(ctx)
 
 	if err != nil {
-		c.log.Errorf("error candidate id %s not in circle: %s", voteReq.CandidateID, err)
+		c.log.Errorf("error getting auth claims: %s", err)
 		return false, err
 	}
 
-	if candidate.Commitment != model.CommitmentCommitted {
-		c.log.Infof(
-			"tried to vote for an uncommitted candidate with circle id %d and candidate id %d",
-			circleId,
-			candidate.ID,
-		)
-		return false, fmt.Errorf("candidate uncommitted")
+	voterId := authClaims.Subject
+
+	_, voter, err := c.votableCircleVoter(circleId, voterId, model.VotingModeApproval)
+
+	if err != nil {
+		return false, err
 	}
 
-	// validate if voter already elected once - if so throw an error
-	hasVoted, err := c.storage.HasVoterVotedForCircle(circleId, voter.ID)
+	votes := make([]*model.Vote, 0, len(voteReq.CandidateIDs))
 
-	if err != nil && !database.RecordNotFound(err) {
-		return false, fmt.Errorf("already voted in circle")
+	for _, candidateId := range voteReq.CandidateIDs {
+		vote, err := c.candidateVote(circleId, voterId, candidateId, 1)
+
+		if err != nil {
+			return false, err
+		}
+
+		votes = append(votes, vote)
 	}
-	if err == nil && hasVoted {
-		c.log.Errorf(
-			"voter %s for candidate %s already voted in circle: %d",
-			voter.Voter,
-			candidate.Candidate,
-			circleId,
-		)
-		return false, fmt.Errorf("already voted in circle")
+
+	return c.createVotes(ctx, circleId, voter, votes)
+}
+
+// CreateScoreVote gives the candidates of the request 0 to 5 stars in a score circle.
+// The votes of all scored candidates are created at once, a voter can
+// score candidates only once until the vote is revoked.
+func (c *voteService) CreateScoreVote(
+	ctx context.Context,
+	circleId int64,
+	voteReq *model.ScoreVoteCreateRequest,
+) (bool, error) {
+	authClaims, err := routerContext.ContextToAuthClaims(ctx)
+
+	if err != nil {
+		c.log.Errorf("error getting auth claims: %s", err)
+		return false, err
 	}
 
-	cachedRanking, voteCount, err := c.storage.CreateNewVote(ctx, circleId, voter, candidate, c.cache.UpsertRanking)
+	voterId := authClaims.Subject
+
+	_, voter, err := c.votableCircleVoter(circleId, voterId, model.VotingModeScore)
 
 	if err != nil {
 		return false, err
 	}
 
-	events := make([]*model.RankingChangedEvent, 0)
+	votes := make([]*model.Vote, 0, len(voteReq.Scores))
 
-	if voteCount > 1 {
-		event := CreateRankingChangedEvent(model.EventOperationUpdated, cachedRanking)
-		events = append(events, event)
-	} else {
-		event := CreateRankingChangedEvent(model.EventOperationCreated, cachedRanking)
-		events = append(events, event)
+	for _, score := range voteReq.Scores {
+		vote, err := c.candidateVote(circleId, voterId, score.CandidateID, score.Score)
+
+		if err != nil {
+			return false, err
+		}
+
+		votes = append(votes, vote)
 	}
 
-	// TODO: update only if the number and index has not changed from the cachedRanking
-	changedRankings, err := c.changedRankings(ctx, circleId, cachedRanking)
+	return c.createVotes(ctx, circleId, voter, votes)
+}
+
+// createVotes of the voter for several candidates at once
+func (c *voteService) createVotes(
+	ctx context.Context,
+	circleId int64,
+	voter *model.CircleVoter,
+	votes []*model.Vote,
+) (bool, error) {
+	outboxEvents := func(
+		changes []*model.RankingChange,
+		rankings model.RankingsCallback,
+	) ([]*model.OutboxEvent, error) {
+		return c.rankingChangesOutboxEvents(ctx, circleId, changes, rankings, voter)
+	}
+
+	_, err := c.storage.CreateNewVotes(ctx, circleId, voter, votes, c.upsertRankingCache, outboxEvents)
 
 	if err != nil {
 		return false, err
 	}
 
-	for _, changedRanking := range changedRankings {
-		event := CreateRankingChangedEvent(model.EventOperationUpdated, changedRanking)
-		events = append(events, event)
-	}
+	return true, nil
+}
 
-	_ = c.rankingSubscription.RankingChangedEvent(ctx, circleId, events)
+// candidateVote of the voter for the committed candidate with the given score
+func (c *voteService) candidateVote(
+	circleId int64,
+	voterId string,
+	candidateId string,
+	score int64,
+) (*model.Vote, error) {
+	if voterId == candidateId {
+		c.log.Errorf("error voter id %s is equal candidate id: %s", voterId, candidateId)
+		return nil, fmt.Errorf("cannot vote for yourself")
+	}
 
-	voterEvent := CreateVoterChangedEvent(model.EventOperationUpdated, voter)
-	_ = c.circleVoterSubscription.CircleVoterChangedEvent(ctx, circleId, voterEvent)
+	candidate, err := c.committedCandidate(circleId, candidateId)
 
-	//TODO: do not only send events also update rankings table, or do it async
-	// in the background from time to time, as votes are already persisted.
+	if err != nil {
+		return nil, err
+	}
 
-	return true, nil
+	return &model.Vote{Candidate: candidate, Score: score}, nil
 }
 
 func (c *voteService) RevokeVote(
@@ -296,6 +420,14 @@ func (c *voteService) RevokeVote(
 		return false, err
 	}
 
+	if voter.Abstained {
+		return c.updateAbstention(ctx, voter, false)
+	}
+
+	if circle.VotingMode != model.VotingModeSingle || circle.Delegation {
+		return c.revokeVotes(ctx, circleId, voter)
+	}
+
 	vote, err := c.storage.VoteByCircleId(circleId, voter.ID)
 
 	if err != nil && !database.RecordNotFound(err) {
@@ -308,55 +440,356 @@ func (c *voteService) RevokeVote(
 		return false, fmt.Errorf("no voting exists")
 	}
 
-	cachedRanking, voteCount, err := c.storage.DeleteVote(
+	outboxEvents := func(
+		cachedRanking *model.RankingResponse,
+		voteCount int64,
+		rankings model.RankingsCallback,
+	) ([]*model.OutboxEvent, error) {
+		if voteCount > 0 {
+			return c.voteOutboxEvents(ctx, circleId, model.EventOperationUpdated, cachedRanking, nil, rankings, voter)
+		}
+
+		events, err := c.voteOutboxEvents(ctx, circleId, model.EventOperationDeleted, cachedRanking, nil, rankings, voter)
+
+		if err != nil {
+			return nil, err
+		}
+
+		candidateEvent, err := model.NewOutboxEvent(
+			circleId,
+			model.EventKindCircleCandidate,
+			CreateCandidateChangedEvent(model.EventOperationRepositioned, vote.Candidate),
+		)
+
+		if err != nil {
+			return nil, err
+		}
+
+		return append(events, candidateEvent), nil
+	}
+
+	_, _, err = c.storage.DeleteVote(
 		ctx,
 		circleId,
 		vote,
 		voter,
-		c.cache.UpsertRanking,
-		c.cache.RemoveRanking,
+		c.upsertRankingCache,
+		c.removeRankingCache,
+		outboxEvents,
 	)
 
 	if err != nil {
 		return false, err
 	}
 
-	if voteCount > 0 {
-		events := make([]*model.RankingChangedEvent, 0)
+	return true, nil
+}
 
-		event := CreateRankingChangedEvent(model.EventOperationUpdated, cachedRanking)
-		events = append(events, event)
+// abstain the voter from voting in the circle
+func (c *voteService) abstain(
+	ctx context.Context,
+	circleId int64,
+	voterId string,
+) (bool, error) {
+	_, voter, err := c.votableCircleVoter(circleId, voterId, model.AllVotingMode...)
 
-		// TODO: update only if the number and index has not changed from the cachedRanking
-		changedRankings, err := c.changedRankings(ctx, circleId, nil)
+	if err != nil {
+		return false, err
+	}
 
-		if err != nil {
-			return false, err
+	return c.updateAbstention(ctx, voter, true)
+}
+
+// updateAbstention of the voter with the changed voter event. If the abstention
+// changed the delegated weights of votes, the ranking events are created as well.
+func (c *voteService) updateAbstention(
+	ctx context.Context,
+	voter *model.CircleVoter,
+	abstained bool,
+) (bool, error) {
+	voter.Abstained = abstained
+
+	outboxEvents := func(
+		changes []*model.RankingChange,
+		rankings model.RankingsCallback,
+	) ([]*model.OutboxEvent, error) {
+		if len(changes) == 0 {
+			return CreateVoterOutboxEvents(voter.CircleID, model.EventOperationUpdated, voter)()
+		}
+
+		return c.rankingChangesOutboxEvents(ctx, voter.CircleID, changes, rankings, voter)
+	}
+
+	_, err := c.storage.UpdateCircleVoterAbstention(
+		ctx,
+		voter,
+		abstained,
+		c.upsertRankingCache,
+		outboxEvents,
+	)
+
+	if err != nil {
+		return false, err
+	}
+
+	return true, nil
+}
+
+// revokeVotes removes all approvals or scores of the voter in the circle
+func (c *voteService) revokeVotes(
+	ctx context.Context,
+	circleId int64,
+	voter *model.CircleVoter,
+) (bool, error) {
+	votes, err := c.storage.VotesByVoterId(circleId, voter.ID)
+
+	if err != nil {
+		c.log.Errorf("getting votes for voter %d for circle id %d: %s", voter.ID, circleId, err)
+		return false, err
+	}
+
+	if len(votes) == 0 {
+		c.log.Errorf("user has not voted for circle id %d", circleId)
+		return false, fmt.Errorf("no voting exists")
+	}
+
+	outboxEvents := func(
+		changes []*model.RankingChange,
+		rankings model.RankingsCallback,
+	) ([]*model.OutboxEvent, error) {
+		return c.rankingChangesOutboxEvents(ctx, circleId, changes, rankings, voter)
+	}
+
+	_, err = c.storage.DeleteVotes(
+		ctx,
+		circleId,
+		votes,
+		voter,
+		c.upsertRankingCache,
+		c.removeRankingCache,
+		outboxEvents,
+	)
+
+	if err != nil {
+		return false, err
+	}
+
+	return true, nil
+}
+
+// votableCircleVoter of the user in the circle, if the circle with one of the given
+// voting modes is open for voting and the voter has neither voted nor abstained yet.
+func (c *voteService) votableCircleVoter(
+	circleId int64,
+	voterId string,
+	votingModes ...model.VotingMode,
+) (*model.Circle, *model.CircleVoter, error) {
+	circle, err := c.storage.CircleById(circleId)
+
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if !circle.IsEditable() {
+		c.log.Infof(
+			"tried to vote for an ineditable circle with circle id %d and subject %s",
+			circleId,
+			voterId,
+		)
+		return nil, nil, fmt.Errorf("circle is not editable")
+	}
+
+	if circle.Stage == model.CircleStageCold {
+		c.log.Infof(
+			"tried to vote for an cold circle with circle id %d and subject %s",
+			circleId,
+			voterId,
+		)
+		return nil, nil, fmt.Errorf("circle is cold")
+	}
+
+	if !slices.Contains(votingModes, circle.VotingMode) {
+		c.log.Infof(
+			"tried to vote with voting modes %v for circle id %d with voting mode %s",
+			votingModes,
+			circleId,
+			circle.VotingMode,
+		)
+		return nil, nil, fmt.Errorf("circle does not allow voting mode %s", circle.VotingMode)
+	}
+
+	voter, err := c.storage.CircleVoterByCircleId(circleId, voterId)
+
+	if err != nil {
+		c.log.Errorf("error voter id %s not in circle: %s", voterId, err)
+		return nil, nil, err
+	}
+
+	if voter.Abstained {
+		c.log.Errorf(
+			"voter %s already abstained in circle: %d",
+			voter.Voter,
+			circleId,
+		)
+		return nil, nil, fmt.Errorf("already voted in circle")
+	}
+
+	// validate if voter already elected once - if so throw an error
+	hasVoted, err := c.storage.HasVoterVotedForCircle(circleId, voter.ID)
+
+	if err != nil && !database.RecordNotFound(err) {
+		return nil, nil, fmt.Errorf("already voted in circle")
+	}
+	if err == nil && hasVoted {
+		c.log.Errorf(
+			"voter %s already voted in circle: %d",
+			voter.Voter,
+			circleId,
+		)
+		return nil, nil, fmt.Errorf("already voted in circle")
+	}
+
+	return circle, voter, nil
+}
+
+// committedCandidate of the circle, only committed candidates can be voted for
+func (c *voteService) committedCandidate(
+	circleId int64,
+	candidateId string,
+) (*model.CircleCandidate, error) {
+	candidate, err := c.storage.CircleCandidateByCircleId(circleId, candidateId)
+
+	if err != nil {
+		c.log.Errorf("error candidate id %s not in circle: %s", candidateId, err)
+		return nil, err
+	}
+
+	if candidate.Commitment != model.CommitmentCommitted {
+		c.log.Infof(
+			"tried to vote for an uncommitted candidate with circle id %d and candidate id %d",
+			circleId,
+			candidate.ID,
+		)
+		return nil, fmt.Errorf("candidate uncommitted")
+	}
+
+	return candidate, nil
+}
+
+// rankingChangesOutboxEvents of a vote, that changed the rankings of several
+// candidates. The changed rankings are followed by all other rankings of the
+// circle, as the placements of several candidates may have changed.
+// Candidates without votes anymore are repositioned. The voter is published
+// as updated, unless it is nil.
+func (c *voteService) rankingChangesOutboxEvents(
+	ctx context.Context,
+	circleId int64,
+	changes []*model.RankingChange,
+	rankings model.RankingsCallback,
+	voter *model.CircleVoter,
+) ([]*model.OutboxEvent, error) {
+	currentRankings, err := c.changedRankings(ctx, circleId, nil, rankings)
+
+	if err != nil {
+		return nil, err
+	}
+
+	currentRankingsById := make(map[int64]*model.RankingResponse, len(currentRankings))
+
+	for _, ranking := range currentRankings {
+		currentRankingsById[ranking.ID] = ranking
+	}
+
+	events := make([]*model.RankingChangedEvent, 0, len(currentRankings)+len(changes))
+	changedRankingIds := make(map[int64]bool, len(changes))
+	outboxEvents := make([]*model.OutboxEvent, 0, 2)
+
+	for _, change := range changes {
+		changedRankingIds[change.Ranking.ID] = true
+
+		if change.VoteCount == 0 {
+			events = append(events, CreateRankingChangedEvent(model.EventOperationDeleted, change.Ranking))
+
+			candidateEvent, err := model.NewOutboxEvent(
+				circleId,
+				model.EventKindCircleCandidate,
+				CreateCandidateChangedEvent(model.EventOperationRepositioned, change.Candidate),
+			)
+
+			if err != nil {
+				return nil, err
+			}
+
+			outboxEvents = append(outboxEvents, candidateEvent)
+			continue
+		}
+
+		operation := model.EventOperationUpdated
+
+		if change.VoteCount == 1 {
+			operation = model.EventOperationCreated
+		}
+
+		ranking := change.Ranking
+
+		if currentRanking, ok := currentRankingsById[ranking.ID]; ok {
+			ranking = currentRanking
 		}
 
-		for _, changedRanking := range changedRankings {
-			event := CreateRankingChangedEvent(model.EventOperationUpdated, changedRanking)
-			events = append(events, event)
+		events = append(events, CreateRankingChangedEvent(operation, ranking))
+	}
+
+	for _, ranking := range currentRankings {
+		if !changedRankingIds[ranking.ID] {
+			events = append(events, CreateRankingChangedEvent(model.EventOperationUpdated, ranking))
 		}
+	}
+
+	rankingEvent, err := model.NewOutboxEvent(circleId, model.EventKindRanking, events)
+
+	if err != nil {
+		return nil, err
+	}
 
-		_ = c.rankingSubscription.RankingChangedEvent(ctx, circleId, events)
+	if voter == nil {
+		return append([]*model.OutboxEvent{rankingEvent}, outboxEvents...), nil
+	}
 
-		voterEvent := CreateVoterChangedEvent(model.EventOperationUpdated, voter)
-		_ = c.circleVoterSubscription.CircleVoterChangedEvent(ctx, circleId, voterEvent)
+	voterEvent, err := model.NewOutboxEvent(
+		circleId,
+		model.EventKindCircleVoter,
+		CreateVoterChangedEvent(model.EventOperationUpdated, voter),
+	)
 
-		return true, nil
+	if err != nil {
+		return nil, err
 	}
 
+	return append([]*model.OutboxEvent{rankingEvent, voterEvent}, outboxEvents...), nil
+}
+
+// voteOutboxEvents of a changed vote, that contain the changed ranking
+// with the given operation followed by the rankings that changed
+// after the from ranking, and the updated voter.
+func (c *voteService) voteOutboxEvents(
+	ctx context.Context,
+	circleId int64,
+	operation model.EventOperation,
+	cachedRanking *model.RankingResponse,
+	fromRanking *model.RankingResponse,
+	rankings model.RankingsCallback,
+	voter *model.CircleVoter,
+) ([]*model.OutboxEvent, error) {
 	events := make([]*model.RankingChangedEvent, 0)
 
-	event := CreateRankingChangedEvent(model.EventOperationDeleted, cachedRanking)
+	event := CreateRankingChangedEvent(operation, cachedRanking)
 	events = append(events, event)
 
 	// TODO: update only if the number and index has not changed from the cachedRanking
-	changedRankings, err := c.changedRankings(ctx, circleId, nil)
+	changedRankings, err := c.changedRankings(ctx, circleId, fromRanking, rankings)
 
 	if err != nil {
-		return false, err
+		return nil, err
 	}
 
 	for _, changedRanking := range changedRankings {
@@ -364,21 +797,128 @@ func (c *voteService) RevokeVote(
 		events = append(events, event)
 	}
 
-	_ = c.rankingSubscription.RankingChangedEvent(ctx, circleId, events)
+	rankingEvent, err := model.NewOutboxEvent(circleId, model.EventKindRanking, events)
 
-	voterEvent := CreateVoterChangedEvent(model.EventOperationUpdated, voter)
-	_ = c.circleVoterSubscription.CircleVoterChangedEvent(ctx, circleId, voterEvent)
+	if err != nil {
+		return nil, err
+	}
 
-	candidateEvent := CreateCandidateChangedEvent(model.EventOperationRepositioned, vote.Candidate)
-	_ = c.circleCandidateSubscription.CircleCandidateChangedEvent(ctx, circleId, candidateEvent)
+	voterEvent, err := model.NewOutboxEvent(
+		circleId,
+		model.EventKindCircleVoter,
+		CreateVoterChangedEvent(model.EventOperationUpdated, voter),
+	)
 
-	return true, nil
+	if err != nil {
+		return nil, err
+	}
+
+	return []*model.OutboxEvent{rankingEvent, voterEvent}, nil
 }
 
+// changedRankings following the updated ranking.
+// If the ranking cache is unavailable the rankings are computed from the
+// given persisted rankings and the placement of the updated ranking is refreshed accordingly.
 func (c *voteService) changedRankings(
 	ctx context.Context,
 	circleId int64,
 	updatedRanking *model.RankingResponse,
+	persistedRankings model.RankingsCallback,
 ) ([]*model.RankingResponse, error) {
-	return c.cache.RankingList(ctx, circleId, updatedRanking)
+	if c.cacheState.IsAvailable(circleId) {
+		rankings, err := c.cache.RankingList(ctx, circleId, updatedRanking)
+
+		if err == nil {
+			return rankings, nil
+		}
+
+		c.log.Errorf("error reading ranking list for circle id %d: %s", circleId, err)
+		c.cacheState.MarkUnavailable(circleId)
+	}
+
+	rankings, err := persistedRankings()
+
+	if err != nil {
+		return nil, err
+	}
+
+	rankingResponses := computeRankingResponses(rankings)
+
+	if updatedRanking == nil {
+		return rankingResponses, nil
+	}
+
+	for index, rankingResponse := range rankingResponses {
+		if rankingResponse.ID == updatedRanking.ID {
+			updatedRanking.Number = rankingResponse.Number
+			updatedRanking.IndexedOrder = rankingResponse.IndexedOrder
+			return rankingResponses[index+1:], nil
+		}
+	}
+
+	return rankingResponses, nil
+}
+
+// upsertRankingCache of the candidate, if the ranking cache of the circle is available.
+// If the cache is unavailable or fails, the ranking list of the circle is marked
+// as dirty and the persisted ranking is returned, so that the vote will still be committed.
+func (c *voteService) upsertRankingCache(
+	ctx context.Context,
+	circleId int64,
+	candidate *model.CircleCandidate,
+	ranking *model.Ranking,
+) (*model.RankingResponse, error) {
+	if c.cacheState.IsAvailable(circleId) {
+		rankingRes, err := c.cache.UpsertRanking(ctx, circleId, candidate, ranking)
+
+		if err == nil {
+			return rankingRes, nil
+		}
+
+		c.log.Errorf("error upserting ranking cache for circle id %d: %s", circleId, err)
+		c.cacheState.MarkUnavailable(circleId)
+	} else {
+		c.cacheState.MarkDirty(circleId)
+	}
+
+	return &model.RankingResponse{
+		CreatedAt:      ranking.CreatedAt,
+		UpdatedAt:      ranking.UpdatedAt,
+		IdentityID:     ranking.IdentityID,
+		NoneOfTheAbove: ranking.IdentityID == model.NoneOfTheAboveCandidate,
+		Placement:      model.PlacementNeutral,
+		ID:             ranking.ID,
+		CandidateID:    candidate.ID,
+		Number:         ranking.Number,
+		Total:          ranking.Total,
+		Average:        ranking.Average,
+		Ballots:        ranking.Ballots,
+		IndexedOrder:   0,
+		CircleID:       circleId,
+		Score:          ranking.Score,
+	}, nil
+}
+
+// removeRankingCache of the candidate, if the ranking cache of the circle is available.
+// If the cache is unavailable or fails, the ranking list of the circle is marked as dirty.
+func (c *voteService) removeRankingCache(
+	ctx context.Context,
+	circleId int64,
+	candidate *model.CircleCandidate,
+) error {
+	if c.cacheState.IsAvailable(circleId) {
+		err := c.cache.RemoveRanking(ctx, circleId, candidate)
+
+		if err == nil {
+			return nil
+		}
+
+		c.log.Errorf("error removing ranking cache for circle id %d: %s", circleId, err)
+		c.cacheState.MarkUnavailable(circleId)
+		return nil
+	}
+
+	c.cacheState.MarkDirty(circleId)
+
+	return nil
 }
@@ -0,0 +1,220 @@
+package api
+
+import (
+	"context"
+	"fmt"
+	"github.com/VerzCar/vyf-vote-circle/api/model"
+	"github.com/VerzCar/vyf-vote-circle/app/database"
+	routerContext "github.com/VerzCar/vyf-vote-circle/app/router/ctx"
+)
+
+// VoteDelegation of the user in the circle, nil if the user has not delegated
+func (c *voteService) VoteDelegation(
+	ctx context.Context,
+	circleId int64,
+) (*model.VoteDelegation, error) {
+	authClaims, err := routerContext.ContextToAuthClaims(ctx)
+
+	if err != nil {
+		c.log.Errorf("error getting auth claims: %s", err)
+		return nil, err
+	}
+
+	voter, err := c.storage.CircleVoterByCircleId(circleId, authClaims.Subject)
+
+	if err != nil {
+		c.log.Errorf("error voter id %s not in circle: %s", authClaims.Subject, err)
+		return nil, err
+	}
+
+	delegation, err := c.storage.VoteDelegationByVoterId(circleId, voter.ID)
+
+	switch {
+	case err != nil && !database.RecordNotFound(err):
+		return nil, err
+	case database.RecordNotFound(err):
+		return nil, nil
+	}
+
+	return delegation, nil
+}
+
+// DelegateVote of the user to another voter of the circle. An existing
+// delegation of the user is replaced. The delegation is transitive, the
+// vote of the delegate counts with the weight of all voters that delegated
+// to the delegate, unless a delegator votes or abstains directly.
+func (c *voteService) DelegateVote(
+	ctx context.Context,
+	circleId int64,
+	delegationReq *model.VoteDelegationCreateRequest,
+) (*model.VoteDelegation, error) {
+	authClaims, err := routerContext.ContextToAuthClaims(ctx)
+
+	if err != nil {
+		c.log.Errorf("error getting auth claims: %s", err)
+		return nil, err
+	}
+
+	voterId := authClaims.Subject
+
+	if voterId == delegationReq.Delegate {
+		c.log.Errorf("error voter id %s is equal delegate id: %s", voterId, delegationReq.Delegate)
+		return nil, fmt.Errorf("cannot delegate to yourself")
+	}
+
+	voter, err := c.delegatingCircleVoter(circleId, voterId)
+
+	if err != nil {
+		return nil, err
+	}
+
+	delegate, err := c.storage.CircleVoterByCircleId(circleId, delegationReq.Delegate)
+
+	if err != nil {
+		c.log.Errorf("error delegate id %s not in circle: %s", delegationReq.Delegate, err)
+		return nil, err
+	}
+
+	delegation := &model.VoteDelegation{
+		Delegator:      voter,
+		Delegate:       delegate,
+		DelegatorRefer: voter.ID,
+		DelegateRefer:  delegate.ID,
+		CircleID:       circleId,
+	}
+
+	delegation, _, err = c.storage.SaveVoteDelegation(
+		ctx,
+		delegation,
+		c.upsertRankingCache,
+		c.removeRankingCache,
+		c.delegationOutboxEvents(ctx, circleId, voter),
+	)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return delegation, nil
+}
+
+// RevokeVoteDelegation of the user in the circle
+func (c *voteService) RevokeVoteDelegation(
+	ctx context.Context,
+	circleId int64,
+) (bool, error) {
+	authClaims, err := routerContext.ContextToAuthClaims(ctx)
+
+	if err != nil {
+		c.log.Errorf("error getting auth claims: %s", err)
+		return false, err
+	}
+
+	voter, err := c.delegatingCircleVoter(circleId, authClaims.Subject)
+
+	if err != nil {
+		return false, err
+	}
+
+	delegation, err := c.storage.VoteDelegationByVoterId(circleId, voter.ID)
+
+	if err != nil {
+		c.log.Errorf("getting delegation for voter %d for circle id %d: %s", voter.ID, circleId, err)
+		return false, err
+	}
+
+	_, err = c.storage.DeleteVoteDelegation(
+		ctx,
+		delegation,
+		c.upsertRankingCache,
+		c.removeRankingCache,
+		c.delegationOutboxEvents(ctx, circleId, voter),
+	)
+
+	if err != nil {
+		return false, err
+	}
+
+	return true, nil
+}
+
+// RemoveCircleVoter from the circle together with the delegations from and to
+// the voter. The weights, that were delegated through the voter, are recalculated
+// and the changed rankings are published without the removed voter.
+func (c *voteService) RemoveCircleVoter(
+	ctx context.Context,
+	circleId int64,
+	voter *model.CircleVoter,
+	outboxEvents model.OutboxEventsCallback,
+) error {
+	_, err := c.storage.DeleteCircleVoter(
+		ctx,
+		circleId,
+		voter.ID,
+		c.upsertRankingCache,
+		c.removeRankingCache,
+		outboxEvents,
+		c.delegationOutboxEvents(ctx, circleId, nil),
+	)
+
+	return err
+}
+
+// delegatingCircleVoter of the user in the circle, if the circle
+// allows delegation and is still editable.
+func (c *voteService) delegatingCircleVoter(
+	circleId int64,
+	voterId string,
+) (*model.CircleVoter, error) {
+	circle, err := c.storage.CircleById(circleId)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if !circle.Delegation {
+		c.log.Infof(
+			"tried to delegate in circle id %d without delegation by subject %s",
+			circleId,
+			voterId,
+		)
+		return nil, fmt.Errorf("circle does not allow delegation")
+	}
+
+	if !circle.IsEditable() {
+		c.log.Infof(
+			"tried to delegate in an ineditable circle with circle id %d and subject %s",
+			circleId,
+			voterId,
+		)
+		return nil, fmt.Errorf("circle is not editable")
+	}
+
+	voter, err := c.storage.CircleVoterByCircleId(circleId, voterId)
+
+	if err != nil {
+		c.log.Errorf("error voter id %s not in circle: %s", voterId, err)
+		return nil, err
+	}
+
+	return voter, nil
+}
+
+// delegationOutboxEvents of a changed delegation. Only if the delegation
+// changed the weights of votes, the changed rankings are published.
+func (c *voteService) delegationOutboxEvents(
+	ctx context.Context,
+	circleId int64,
+	voter *model.CircleVoter,
+) model.RankingChangesOutboxEventsCallback {
+	return func(
+		changes []*model.RankingChange,
+		rankings model.RankingsCallback,
+	) ([]*model.OutboxEvent, error) {
+		if len(changes) == 0 {
+			return nil, nil
+		}
+
+		return c.rankingChangesOutboxEvents(ctx, circleId, changes, rankings, voter)
+	}
+}
@@ -0,0 +1,693 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	logger "github.com/VerzCar/vyf-lib-logger"
+	"github.com/VerzCar/vyf-vote-circle/api/model"
+	"github.com/VerzCar/vyf-vote-circle/app/config"
+	routerContext "github.com/VerzCar/vyf-vote-circle/app/router/ctx"
+	"net"
+	"net/http"
+	"net/url"
+	"strconv"
+	"syscall"
+	"time"
+)
+
+const (
+	// defaultWebhookInterval is used if no dispatch interval is configured.
+	defaultWebhookInterval = time.Second
+	// defaultWebhookBatchSize is used if no batch size is configured.
+	defaultWebhookBatchSize = 20
+	// defaultWebhookMaxAttempts is used if no max attempts are configured.
+	defaultWebhookMaxAttempts = 8
+	// defaultWebhookBackoff is used if no backoff is configured.
+	defaultWebhookBackoff = 10 * time.Second
+	// defaultWebhookTimeout is used if no timeout is configured.
+	defaultWebhookTimeout = 5 * time.Second
+	// defaultWebhooksPerCircle is used if no max webhooks per circle are configured.
+	defaultWebhooksPerCircle = 5
+	// webhookMaxBackoff is the longest delay between two delivery attempts.
+	webhookMaxBackoff = time.Hour
+	// webhookDeliveriesLimit of the deliveries returned for a webhook
+	webhookDeliveriesLimit = 100
+	// webhookClaimLease is the time the claimed deliveries are reserved for
+	// the delivery, before another instance will claim them again.
+	webhookClaimLease = 5 * time.Minute
+)
+
+// sharedAddressSpace of the carrier-grade NAT, that is not covered by net.IP.IsPrivate
+var sharedAddressSpace = &net.IPNet{IP: net.IPv4(100, 64, 0, 0), Mask: net.CIDRMask(10, 32)}
+
+// Headers of a webhook request. The signature is the hex encoded HMAC-SHA256
+// of the timestamp and the body joined by a dot, signed with the secret of the webhook.
+const (
+	WebhookHeaderDelivery  = "X-Webhook-Delivery"
+	WebhookHeaderEvent     = "X-Webhook-Event"
+	WebhookHeaderTimestamp = "X-Webhook-Timestamp"
+	WebhookHeaderSignature = "X-Webhook-Signature"
+)
+
+// WebhookService manages the webhooks of the circles and delivers
+// the subscribed events of a circle to its webhooks. Only the owner
+// of the circle is eligible to manage the webhooks.
+type WebhookService interface {
+	Webhooks(
+		ctx context.Context,
+		circleId int64,
+	) ([]*model.Webhook, error)
+	CreateWebhook(
+		ctx context.Context,
+		circleId int64,
+		webhookCreateRequest *model.WebhookCreateRequest,
+	) (*model.Webhook, error)
+	UpdateWebhook(
+		ctx context.Context,
+		circleId int64,
+		webhookId int64,
+		webhookUpdateRequest *model.WebhookUpdateRequest,
+	) (*model.Webhook, error)
+	DeleteWebhook(
+		ctx context.Context,
+		circleId int64,
+		webhookId int64,
+	) error
+	WebhookDeliveries(
+		ctx context.Context,
+		circleId int64,
+		webhookId int64,
+	) ([]*model.WebhookDelivery, error)
+	CircleEvent(
+		ctx context.Context,
+		sourceId int64,
+		circleId int64,
+		payload interface{},
+	) error
+	Run(ctx context.Context)
+}
+
+type WebhookRepository interface {
+	CircleById(id int64) (*model.Circle, error)
+	RankingsByCircleId(circleId int64) ([]*model.Ranking, error)
+	CircleTurnout(circleId int64) (*model.CircleTurnout, error)
+	WebhookById(id int64) (*model.Webhook, error)
+	WebhooksByCircleId(circleId int64) ([]*model.Webhook, error)
+	CountWebhooksByCircleId(circleId int64) (int64, error)
+	CreateNewWebhook(webhook *model.Webhook) (*model.Webhook, error)
+	UpdateWebhook(webhook *model.Webhook) (*model.Webhook, error)
+	DeleteWebhook(webhookId int64) error
+	WebhookDeliveriesByWebhookId(
+		webhookId int64,
+		limit int,
+	) ([]*model.WebhookDelivery, error)
+	CreateWebhookDeliveries(
+		sourceId int64,
+		circleId int64,
+		event model.WebhookEvent,
+		payload string,
+	) error
+	ClaimWebhookDeliveries(
+		limit int,
+		lease time.Duration,
+	) ([]*model.WebhookDelivery, error)
+	UpdateWebhookDeliveries(deliveries []*model.WebhookDelivery) error
+}
+
+type WebhookOptionService interface {
+	UserOptionOfUser(
+		userIdentityId string,
+	) (*model.UserOptionResponse, error)
+}
+
+type webhookService struct {
+	storage           WebhookRepository
+	userOptionService WebhookOptionService
+	client            *http.Client
+	config            *config.Config
+	log               logger.Logger
+}
+
+func NewWebhookService(
+	webhookRepo WebhookRepository,
+	userOptionService WebhookOptionService,
+	config *config.Config,
+	log logger.Logger,
+) WebhookService {
+	timeout := time.Duration(config.Webhook.Timeout) * time.Second
+
+	if timeout <= 0 {
+		timeout = defaultWebhookTimeout
+	}
+
+	return &webhookService{
+		storage:           webhookRepo,
+		userOptionService: userOptionService,
+		client:            newWebhookClient(timeout),
+		config:            config,
+		log:               log,
+	}
+}
+
+func (c *webhookService) Webhooks(
+	ctx context.Context,
+	circleId int64,
+) ([]*model.Webhook, error) {
+	if _, err := c.ownedCircle(ctx, circleId); err != nil {
+		return nil, err
+	}
+
+	return c.storage.WebhooksByCircleId(circleId)
+}
+
+// CreateWebhook for the circle with a generated secret, that is used
+// to sign the requests to the webhook.
+func (c *webhookService) CreateWebhook(
+	ctx context.Context,
+	circleId int64,
+	webhookCreateRequest *model.WebhookCreateRequest,
+) (*model.Webhook, error) {
+	circle, err := c.ownedCircle(ctx, circleId)
+
+	if err != nil {
+		return nil, err
+	}
+
+	count, err := c.storage.CountWebhooksByCircleId(circleId)
+
+	if err != nil {
+		return nil, err
+	}
+
+	userOption, err := c.userOptionService.UserOptionOfUser(circle.CreatedFrom)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if count >= int64(userOption.MaxWebhooks) {
+		c.log.Infof("circle has reached the max webhooks: circle ID %d", circleId)
+		return nil, fmt.Errorf("max webhooks of circle reached")
+	}
+
+	if err := validateWebhookURL(ctx, webhookCreateRequest.URL); err != nil {
+		c.log.Infof("webhook url of circle ID %d is not allowed: %s", circleId, err)
+		return nil, fmt.Errorf("webhook url is not allowed")
+	}
+
+	secret, err := generateWebhookSecret()
+
+	if err != nil {
+		c.log.Errorf("could not generate webhook secret: %s", err)
+		return nil, err
+	}
+
+	webhook := &model.Webhook{
+		URL:         webhookCreateRequest.URL,
+		Secret:      secret,
+		CreatedFrom: circle.CreatedFrom,
+		Events:      webhookEvents(webhookCreateRequest.Events),
+		CircleID:    circleId,
+		Active:      true,
+	}
+
+	return c.storage.CreateNewWebhook(webhook)
+}
+
+func (c *webhookService) UpdateWebhook(
+	ctx context.Context,
+	circleId int64,
+	webhookId int64,
+	webhookUpdateRequest *model.WebhookUpdateRequest,
+) (*model.Webhook, error) {
+	webhook, err := c.ownedWebhook(ctx, circleId, webhookId)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if webhookUpdateRequest.URL != nil {
+		if err := validateWebhookURL(ctx, *webhookUpdateRequest.URL); err != nil {
+			c.log.Infof("webhook url of circle ID %d is not allowed: %s", circleId, err)
+			return nil, fmt.Errorf("webhook url is not allowed")
+		}
+
+		webhook.URL = *webhookUpdateRequest.URL
+	}
+
+	if len(webhookUpdateRequest.Events) > 0 {
+		webhook.Events = webhookEvents(webhookUpdateRequest.Events)
+	}
+
+	if webhookUpdateRequest.Active != nil {
+		webhook.Active = *webhookUpdateRequest.Active
+	}
+
+	return c.storage.UpdateWebhook(webhook)
+}
+
+func (c *webhookService) DeleteWebhook(
+	ctx context.Context,
+	circleId int64,
+	webhookId int64,
+) error {
+	webhook, err := c.ownedWebhook(ctx, circleId, webhookId)
+
+	if err != nil {
+		return err
+	}
+
+	return c.storage.DeleteWebhook(webhook.ID)
+}
+
+// WebhookDeliveries of the webhook with the latest deliveries first
+func (c *webhookService) WebhookDeliveries(
+	ctx context.Context,
+	circleId int64,
+	webhookId int64,
+) ([]*model.WebhookDelivery, error) {
+	webhook, err := c.ownedWebhook(ctx, circleId, webhookId)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return c.storage.WebhookDeliveriesByWebhookId(webhook.ID, webhookDeliveriesLimit)
+}
+
+// CircleEvent creates the deliveries of the webhook events, that originate
+// from the delivered outbox event of the circle. The source id is the id
+// of the outbox event, so that a redelivered outbox event does not create
+// the deliveries again.
+func (c *webhookService) CircleEvent(
+	ctx context.Context,
+	sourceId int64,
+	circleId int64,
+	payload interface{},
+) error {
+	events, err := c.circleWebhookEvents(circleId, payload)
+
+	if err != nil {
+		return err
+	}
+
+	for event, data := range events {
+		encodedData, err := json.Marshal(data)
+
+		if err != nil {
+			return err
+		}
+
+		encodedPayload, err := json.Marshal(
+			&model.WebhookPayload{
+				CreatedAt: time.Now(),
+				Event:     event,
+				Data:      encodedData,
+				CircleID:  circleId,
+			},
+		)
+
+		if err != nil {
+			return err
+		}
+
+		if err := c.storage.CreateWebhookDeliveries(sourceId, circleId, event, string(encodedPayload)); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Run delivers the pending webhook deliveries in the configured interval.
+// Blocks until the context is done.
+func (c *webhookService) Run(ctx context.Context) {
+	interval := time.Duration(c.config.Webhook.Interval) * time.Millisecond
+
+	if interval <= 0 {
+		interval = defaultWebhookInterval
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			c.dispatchPending(ctx)
+		}
+	}
+}
+
+// dispatchPending claims the due deliveries, sends them outside of
+// the claiming transaction and writes the result of the deliveries.
+func (c *webhookService) dispatchPending(ctx context.Context) {
+	deliveries, err := c.storage.ClaimWebhookDeliveries(c.batchSize(), webhookClaimLease)
+
+	if err != nil || len(deliveries) == 0 {
+		return
+	}
+
+	c.dispatch(ctx, deliveries)
+
+	_ = c.storage.UpdateWebhookDeliveries(deliveries)
+}
+
+// dispatch the deliveries to their webhooks. A failed delivery is retried
+// with an exponential backoff, until the max attempts are reached.
+// The deliveries of a deactivated webhook are skipped.
+func (c *webhookService) dispatch(ctx context.Context, deliveries []*model.WebhookDelivery) {
+	for _, delivery := range deliveries {
+		if delivery.Webhook == nil || !delivery.Webhook.Active {
+			delivery.Status = model.WebhookDeliveryStatusSkipped
+			delivery.LastError = "webhook is not active"
+			continue
+		}
+
+		delivery.Attempts++
+
+		err := c.deliver(ctx, delivery)
+
+		if err == nil {
+			deliveredAt := time.Now()
+			delivery.Status = model.WebhookDeliveryStatusDelivered
+			delivery.DeliveredAt = &deliveredAt
+			delivery.LastError = ""
+			continue
+		}
+
+		delivery.LastError = err.Error()
+
+		if delivery.Attempts >= c.maxAttempts() {
+			c.log.Warnf(
+				"webhook delivery id %d of webhook id %d is dead after %d attempts: %s",
+				delivery.ID,
+				delivery.WebhookID,
+				delivery.Attempts,
+				err,
+			)
+			delivery.Status = model.WebhookDeliveryStatusDead
+			continue
+		}
+
+		delivery.NextAttemptAt = time.Now().Add(c.backoff(delivery.Attempts))
+	}
+}
+
+// deliver the payload of the delivery signed to the webhook.
+// Any response other than 2xx is a failed delivery.
+func (c *webhookService) deliver(ctx context.Context, delivery *model.WebhookDelivery) error {
+	webhook := delivery.Webhook
+	body := []byte(delivery.Payload)
+	timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, webhook.URL, bytes.NewReader(body))
+
+	if err != nil {
+		return err
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(WebhookHeaderDelivery, strconv.FormatInt(delivery.ID, 10))
+	req.Header.Set(WebhookHeaderEvent, string(delivery.Event))
+	req.Header.Set(WebhookHeaderTimestamp, timestamp)
+	req.Header.Set(WebhookHeaderSignature, "sha256="+webhookSignature(webhook.Secret, timestamp, body))
+
+	res, err := c.client.Do(req)
+
+	if err != nil {
+		return err
+	}
+
+	defer res.Body.Close()
+
+	delivery.ResponseCode = res.StatusCode
+
+	// the response body is not kept, it could expose the content
+	// of the responding service to the owner of the circle
+	if res.StatusCode < 200 || res.StatusCode > 299 {
+		return fmt.Errorf("webhook responded with status %d", res.StatusCode)
+	}
+
+	return nil
+}
+
+// circleWebhookEvents of the outbox event with the data of each webhook event
+func (c *webhookService) circleWebhookEvents(
+	circleId int64,
+	payload interface{},
+) (map[model.WebhookEvent]interface{}, error) {
+	events := make(map[model.WebhookEvent]interface{})
+
+	switch event := payload.(type) {
+	case *model.CircleVoterChangedEvent:
+		if event.Operation == model.EventOperationUpdated && event.Voter.VotedFor != nil && *event.Voter.VotedFor != "" {
+			events[model.WebhookEventVoteCast] = event.Voter
+		}
+	case *model.CircleCandidateChangedEvent:
+		if event.Operation == model.EventOperationUpdated && event.Candidate.Commitment == model.CommitmentCommitted {
+			events[model.WebhookEventCandidateCommitted] = event.Candidate
+		}
+	case *model.CircleChangedEvent:
+		if event.Operation != model.EventOperationStageChanged {
+			break
+		}
+
+		events[model.WebhookEventStageChanged] = event.Circle
+
+		if event.Circle.Stage != model.CircleStageClosed {
+			break
+		}
+
+		rankings, err := c.storage.RankingsByCircleId(circleId)
+
+		if err != nil {
+			return nil, err
+		}
+
+		turnout, err := c.storage.CircleTurnout(circleId)
+
+		if err != nil {
+			return nil, err
+		}
+
+		events[model.WebhookEventCircleClosed] = &model.CircleClosedWebhookData{
+			Circle:   event.Circle,
+			Rankings: rankings,
+			Turnout:  turnout,
+			Quorum: model.NewCircleQuorum(
+				event.Circle.QuorumTurnout,
+				event.Circle.QuorumWinnerVotes,
+				turnout,
+				rankings,
+			),
+		}
+	}
+
+	return events, nil
+}
+
+// ownedCircle of the user, only the owner is eligible to manage the webhooks
+func (c *webhookService) ownedCircle(
+	ctx context.Context,
+	circleId int64,
+) (*model.Circle, error) {
+	authClaims, err := routerContext.ContextToAuthClaims(ctx)
+
+	if err != nil {
+		c.log.Errorf("error getting auth claims: %s", err)
+		return nil, err
+	}
+
+	circle, err := c.storage.CircleById(circleId)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if circle.CreatedFrom != authClaims.Subject {
+		c.log.Infof(
+			"user is not eligible to manage webhooks of circle: user %s, circle ID %d",
+			authClaims.Subject,
+			circle.ID,
+		)
+		return nil, fmt.Errorf("user is not eligible to manage webhooks of circle")
+	}
+
+	return circle, nil
+}
+
+// ownedWebhook of the circle of the user
+func (c *webhookService) ownedWebhook(
+	ctx context.Context,
+	circleId int64,
+	webhookId int64,
+) (*model.Webhook, error) {
+	if _, err := c.ownedCircle(ctx, circleId); err != nil {
+		return nil, err
+	}
+
+	webhook, err := c.storage.WebhookById(webhookId)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if webhook.CircleID != circleId {
+		c.log.Infof("webhook id %d does not belong to circle ID %d", webhookId, circleId)
+		return nil, fmt.Errorf("webhook not found")
+	}
+
+	return webhook, nil
+}
+
+// backoff before the next delivery attempt, that doubles with every attempt
+func (c *webhookService) backoff(attempts int) time.Duration {
+	backoff := time.Duration(c.config.Webhook.Backoff) * time.Millisecond
+
+	if backoff <= 0 {
+		backoff = defaultWebhookBackoff
+	}
+
+	for i := 1; i < attempts && backoff < webhookMaxBackoff; i++ {
+		backoff *= 2
+	}
+
+	if backoff > webhookMaxBackoff {
+		return webhookMaxBackoff
+	}
+
+	return backoff
+}
+
+func (c *webhookService) batchSize() int {
+	if c.config.Webhook.BatchSize > 0 {
+		return c.config.Webhook.BatchSize
+	}
+	return defaultWebhookBatchSize
+}
+
+func (c *webhookService) maxAttempts() int {
+	if c.config.Webhook.MaxAttempts > 0 {
+		return c.config.Webhook.MaxAttempts
+	}
+	return defaultWebhookMaxAttempts
+}
+
+// webhookSignature of the body with the timestamp signed with the secret
+func webhookSignature(secret string, timestamp string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(timestamp))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// newWebhookClient for the requests to the webhooks of the users. The dialer
+// rejects every address, that is not public, at the time of the connection,
+// so that a changed DNS record of a webhook cannot reach internal services.
+// Redirects are not followed.
+func newWebhookClient(timeout time.Duration) *http.Client {
+	dialer := &net.Dialer{Timeout: timeout, Control: webhookDialControl}
+
+	return &http.Client{
+		Transport: &http.Transport{
+			DialContext:         dialer.DialContext,
+			TLSHandshakeTimeout: timeout,
+		},
+		CheckRedirect: func(req *http.Request, via []*http.Request) error {
+			return http.ErrUseLastResponse
+		},
+		Timeout: timeout,
+	}
+}
+
+// validateWebhookURL ensures, that the url is an http url,
+// whose host resolves to public addresses only.
+func validateWebhookURL(ctx context.Context, rawURL string) error {
+	webhookURL, err := url.Parse(rawURL)
+
+	if err != nil {
+		return err
+	}
+
+	if webhookURL.Scheme != "http" && webhookURL.Scheme != "https" {
+		return fmt.Errorf("unsupported scheme %s", webhookURL.Scheme)
+	}
+
+	host := webhookURL.Hostname()
+
+	if host == "" {
+		return fmt.Errorf("missing host")
+	}
+
+	addrs, err := net.DefaultResolver.LookupIPAddr(ctx, host)
+
+	if err != nil {
+		return err
+	}
+
+	for _, addr := range addrs {
+		if !isPublicWebhookIP(addr.IP) {
+			return fmt.Errorf("host %s resolves to the non public address %s", host, addr.IP)
+		}
+	}
+
+	return nil
+}
+
+// webhookDialControl rejects the connection to an address, that is not public
+func webhookDialControl(network string, address string, _ syscall.RawConn) error {
+	host, _, err := net.SplitHostPort(address)
+
+	if err != nil {
+		return err
+	}
+
+	if ip := net.ParseIP(host); ip == nil || !isPublicWebhookIP(ip) {
+		return fmt.Errorf("dial to non public address %s is not allowed", host)
+	}
+
+	return nil
+}
+
+// isPublicWebhookIP reports whether the ip is neither a loopback, private,
+// link-local, shared, multicast nor an unspecified address.
+func isPublicWebhookIP(ip net.IP) bool {
+	return !ip.IsLoopback() &&
+		!ip.IsPrivate() &&
+		!ip.IsLinkLocalUnicast() &&
+		!ip.IsLinkLocalMulticast() &&
+		!ip.IsInterfaceLocalMulticast() &&
+		!ip.IsMulticast() &&
+		!ip.IsUnspecified() &&
+		!sharedAddressSpace.Contains(ip)
+}
+
+func generateWebhookSecret() (string, error) {
+	secret := make([]byte, 32)
+
+	if _, err := rand.Read(secret); err != nil {
+		return "", err
+	}
+
+	return hex.EncodeToString(secret), nil
+}
+
+func webhookEvents(events []model.WebhookEvent) []string {
+	names := make([]string, 0, len(events))
+
+	for _, event := range events {
+		names = append(names, string(event))
+	}
+
+	return names
+}
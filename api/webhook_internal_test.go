@@ -0,0 +1,94 @@
+package api
+
+import (
+	"github.com/VerzCar/vyf-vote-circle/app/config"
+	"testing"
+	"time"
+)
+
+func TestWebhookSignature(t *testing.T) {
+	tests := []struct {
+		name      string
+		secret    string
+		timestamp string
+		body      []byte
+		expected  string
+	}{
+		{
+			name:      "Test webhookSignature of the timestamp and body",
+			secret:    "secret",
+			timestamp: "1700000000",
+			body:      []byte(`{"event":"VOTE_CREATED"}`),
+			expected:  "87facbba507e99e4c0f0067a6126c5153a3f4d42628e5ef82919a7eb5f0f80c6",
+		},
+		{
+			name:      "Test webhookSignature with an empty body",
+			secret:    "secret",
+			timestamp: "1700000000",
+			expected:  "4bc5f74d868b97888288889c5d9d65df02526f94c1592a79fdf4fe8b26e311e5",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(
+			tt.name, func(t *testing.T) {
+				result := webhookSignature(tt.secret, tt.timestamp, tt.body)
+				if result != tt.expected {
+					t.Errorf("Expected: %v, but got: %v", tt.expected, result)
+				}
+			},
+		)
+	}
+}
+
+func TestWebhookService_backoff(t *testing.T) {
+	tests := []struct {
+		name     string
+		backoff  int
+		attempts int
+		expected time.Duration
+	}{
+		{
+			name:     "Test backoff with the default backoff",
+			attempts: 1,
+			expected: defaultWebhookBackoff,
+		},
+		{
+			name:     "Test backoff doubles the default backoff per attempt",
+			attempts: 3,
+			expected: 4 * defaultWebhookBackoff,
+		},
+		{
+			name:     "Test backoff with the configured backoff",
+			backoff:  500,
+			attempts: 2,
+			expected: time.Second,
+		},
+		{
+			name:     "Test backoff is capped at the max backoff",
+			attempts: 20,
+			expected: webhookMaxBackoff,
+		},
+		{
+			name:     "Test backoff is capped at the max backoff with many attempts",
+			backoff:  1,
+			attempts: 1000,
+			expected: webhookMaxBackoff,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(
+			tt.name, func(t *testing.T) {
+				conf := &config.Config{}
+				conf.Webhook.Backoff = tt.backoff
+				service := &webhookService{config: conf}
+
+				result := service.backoff(tt.attempts)
+				if result != tt.expected {
+					t.Errorf("Expected: %v, but got: %v", tt.expected, result)
+				}
+			},
+		)
+	}
+}
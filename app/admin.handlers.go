@@ -0,0 +1,153 @@
+package app
+
+import (
+	"github.com/VerzCar/vyf-vote-circle/api/model"
+	"github.com/gin-gonic/gin"
+	"net/http"
+)
+
+func (s *Server) AdminUserOption() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		errResponse := model.Response{
+			Status: model.ResponseError,
+			Msg:    "cannot find user option",
+			Data:   nil,
+		}
+
+		userOptionReq := &model.UserOptionUriRequest{}
+
+		err := ctx.ShouldBindUri(userOptionReq)
+
+		if err != nil {
+			s.log.Error(err)
+			ctx.JSON(http.StatusBadRequest, errResponse)
+			return
+		}
+
+		if err := s.validate.Struct(userOptionReq); err != nil {
+			s.log.Warn(err)
+			ctx.JSON(http.StatusBadRequest, errResponse)
+			return
+		}
+
+		optionResponse, err := s.adminService.UserOption(ctx.Request.Context(), userOptionReq.IdentityID)
+
+		if err != nil {
+			s.log.Errorf("service error: %v", err)
+			ctx.JSON(http.StatusInternalServerError, errResponse)
+			return
+		}
+
+		response := model.Response{
+			Status: model.ResponseSuccess,
+			Msg:    "",
+			Data:   optionResponse,
+		}
+
+		ctx.JSON(http.StatusOK, response)
+	}
+}
+
+func (s *Server) AdminUpdateUserOption() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		errResponse := model.Response{
+			Status: model.ResponseError,
+			Msg:    "cannot update user option",
+			Data:   nil,
+		}
+
+		userOptionReq := &model.UserOptionUriRequest{}
+
+		err := ctx.ShouldBindUri(userOptionReq)
+
+		if err != nil {
+			s.log.Error(err)
+			ctx.JSON(http.StatusBadRequest, errResponse)
+			return
+		}
+
+		if err := s.validate.Struct(userOptionReq); err != nil {
+			s.log.Warn(err)
+			ctx.JSON(http.StatusBadRequest, errResponse)
+			return
+		}
+
+		userOptionUpdateReq := &model.UserOptionUpdateRequest{}
+
+		err = ctx.ShouldBindJSON(userOptionUpdateReq)
+
+		if err != nil {
+			s.log.Error(err)
+			ctx.JSON(http.StatusBadRequest, errResponse)
+			return
+		}
+
+		if err := s.validate.Struct(userOptionUpdateReq); err != nil {
+			s.log.Warn(err)
+			ctx.JSON(http.StatusBadRequest, errResponse)
+			return
+		}
+
+		optionResponse, err := s.adminService.UpdateUserOption(
+			ctx.Request.Context(),
+			userOptionReq.IdentityID,
+			userOptionUpdateReq,
+		)
+
+		if err != nil {
+			s.log.Errorf("service error: %v", err)
+			ctx.JSON(http.StatusInternalServerError, errResponse)
+			return
+		}
+
+		response := model.Response{
+			Status: model.ResponseSuccess,
+			Msg:    "",
+			Data:   optionResponse,
+		}
+
+		ctx.JSON(http.StatusOK, response)
+	}
+}
+
+func (s *Server) AdminUserOptionAudits() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		errResponse := model.Response{
+			Status: model.ResponseError,
+			Msg:    "cannot find audits of user option",
+			Data:   nil,
+		}
+
+		userOptionReq := &model.UserOptionUriRequest{}
+
+		err := ctx.ShouldBindUri(userOptionReq)
+
+		if err != nil {
+			s.log.Error(err)
+			ctx.JSON(http.StatusBadRequest, errResponse)
+			return
+		}
+
+		if err := s.validate.Struct(userOptionReq); err != nil {
+			s.log.Warn(err)
+			ctx.JSON(http.StatusBadRequest, errResponse)
+			return
+		}
+
+		audits, err := s.adminService.UserOptionAudits(ctx.Request.Context(), userOptionReq.IdentityID)
+
+		if err != nil {
+			s.log.Errorf("service error: %v", err)
+			ctx.JSON(http.StatusInternalServerError, errResponse)
+			return
+		}
+
+		response := model.Response{
+			Status: model.ResponseSuccess,
+			Msg:    "",
+			Data:   audits,
+		}
+
+		ctx.JSON(http.StatusOK, response)
+	}
+}
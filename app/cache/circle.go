@@ -0,0 +1,149 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/VerzCar/vyf-vote-circle/api/model"
+	"github.com/go-redis/redis/v8"
+	"time"
+)
+
+// circleExpiration of the circle and the memberships of a circle
+const circleExpiration = time.Duration(10) * time.Minute
+
+// CircleMember kind of the membership in a circle
+type CircleMember string
+
+const (
+	CircleMemberVoter     CircleMember = "voters"
+	CircleMemberCandidate CircleMember = "candidates"
+)
+
+const (
+	circleMembershipMember    = "1"
+	circleMembershipNonMember = "0"
+)
+
+// Circle of the given circle id from the cache.
+// Returns false if the circle is not cached.
+func (c *redisCache) Circle(
+	ctx context.Context,
+	circleId int64,
+) (*model.Circle, bool, error) {
+	circle := &model.Circle{}
+	err := c.getJson(ctx, circleKey(circleId), circle)
+
+	switch {
+	case errors.Is(err, redis.Nil):
+		return nil, false, nil
+	case err != nil:
+		c.log.Errorf("could not read circle for circle id %d: %s", circleId, err)
+		return nil, false, err
+	}
+
+	return circle, true, nil
+}
+
+// SetCircle in the cache with the expiration of the circle.
+func (c *redisCache) SetCircle(
+	ctx context.Context,
+	circle *model.Circle,
+) error {
+	err := c.setJson(ctx, circleKey(circle.ID), circle, circleExpiration)
+
+	if err != nil {
+		c.log.Errorf("could not set circle for circle id %d: %s", circle.ID, err)
+		return err
+	}
+
+	return nil
+}
+
+// RemoveCircle from the cache.
+func (c *redisCache) RemoveCircle(
+	ctx context.Context,
+	circleId int64,
+) error {
+	err := c.redis.Del(ctx, circleKey(circleId)).Err()
+
+	if err != nil {
+		c.log.Errorf("could not remove circle for circle id %d: %s", circleId, err)
+		return err
+	}
+
+	return nil
+}
+
+// CircleMembership of the user in the circle.
+// Returns whether the user is a member and false for exists,
+// if the membership of the user is not cached.
+func (c *redisCache) CircleMembership(
+	ctx context.Context,
+	circleId int64,
+	member CircleMember,
+	userIdentityId string,
+) (bool, bool, error) {
+	val, err := c.redis.HGet(ctx, circleMembersKey(circleId, member), userIdentityId).Result()
+
+	switch {
+	case errors.Is(err, redis.Nil):
+		return false, false, nil
+	case err != nil:
+		c.log.Errorf("could not read %s membership for circle id %d: %s", member, circleId, err)
+		return false, false, err
+	}
+
+	return val == circleMembershipMember, true, nil
+}
+
+// SetCircleMembership of the user in the circle
+// with the expiration of the circle.
+// If onlyIfAbsent is set, an already cached membership will not be overwritten,
+// so that a membership that is read from the database does not overwrite
+// a concurrent change of the membership.
+func (c *redisCache) SetCircleMembership(
+	ctx context.Context,
+	circleId int64,
+	member CircleMember,
+	userIdentityId string,
+	isMember bool,
+	onlyIfAbsent bool,
+) error {
+	key := circleMembersKey(circleId, member)
+	val := circleMembershipValue(isMember)
+
+	_, err := c.redis.Pipelined(
+		ctx, func(pipe redis.Pipeliner) error {
+			if onlyIfAbsent {
+				pipe.HSetNX(ctx, key, userIdentityId, val)
+			} else {
+				pipe.HSet(ctx, key, userIdentityId, val)
+			}
+			pipe.Expire(ctx, key, circleExpiration)
+			return nil
+		},
+	)
+
+	if err != nil {
+		c.log.Errorf("could not set %s membership for circle id %d: %s", member, circleId, err)
+		return err
+	}
+
+	return nil
+}
+
+func circleMembershipValue(isMember bool) string {
+	if isMember {
+		return circleMembershipMember
+	}
+	return circleMembershipNonMember
+}
+
+func circleKey(circleId int64) string {
+	return fmt.Sprintf("circle:%d", circleId)
+}
+
+func circleMembersKey(circleId int64, member CircleMember) string {
+	return fmt.Sprintf("circle:%d:members:%s", circleId, member)
+}
@@ -0,0 +1,123 @@
+package cache
+
+import (
+	"context"
+	"github.com/VerzCar/vyf-vote-circle/api/model"
+	"github.com/go-redis/redis/v8"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"testing"
+	"time"
+)
+
+func TestMemoryCache_Circle(t *testing.T) {
+	runCircleCacheSuite(t, NewMemoryCache(testConfig, testLog))
+}
+
+// TestRedisCache_Circle runs the same suite against the configured redis
+// server and is skipped if the server is not reachable.
+func TestRedisCache_Circle(t *testing.T) {
+	opt, err := redis.ParseURL(redisUrl(testConfig))
+	require.NoError(t, err)
+
+	opt.DialTimeout = time.Second
+	rdb := redis.NewClient(opt)
+	defer rdb.Close()
+
+	if err := rdb.Ping(context.Background()).Err(); err != nil {
+		t.Skipf("redis not reachable: %s", err)
+	}
+
+	runCircleCacheSuite(t, NewRedisCache(rdb, testConfig, testLog))
+}
+
+// runCircleCacheSuite verifies the behaviour every implementation
+// of the RedisCache must fulfill for the circle and its memberships.
+func runCircleCacheSuite(t *testing.T, c RedisCache) {
+	ctx := context.Background()
+	circleId := time.Now().UnixNano()
+	validUntil := time.Date(2024, 2, 1, 0, 0, 0, 0, time.UTC)
+
+	circle := &model.Circle{
+		ID:          circleId,
+		Name:        "circle",
+		CreatedFrom: "owner",
+		ValidFrom:   time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC),
+		ValidUntil:  &validUntil,
+		Stage:       model.CircleStageHot,
+		Private:     true,
+		Active:      true,
+	}
+
+	t.Cleanup(
+		func() {
+			_ = c.RemoveCircle(ctx, circleId)
+		},
+	)
+
+	t.Run(
+		"should not contain an uncached circle", func(t *testing.T) {
+			cached, exists, err := c.Circle(ctx, circleId)
+			require.NoError(t, err)
+			assert.False(t, exists)
+			assert.Nil(t, cached)
+		},
+	)
+
+	t.Run(
+		"should set and remove the circle", func(t *testing.T) {
+			require.NoError(t, c.SetCircle(ctx, circle))
+
+			cached, exists, err := c.Circle(ctx, circleId)
+			require.NoError(t, err)
+			assert.True(t, exists)
+			assert.Equal(t, circle.Name, cached.Name)
+			assert.Equal(t, circle.Stage, cached.Stage)
+			assert.True(t, circle.ValidUntil.Equal(*cached.ValidUntil))
+			assert.True(t, cached.Private)
+
+			require.NoError(t, c.RemoveCircle(ctx, circleId))
+
+			_, exists, err = c.Circle(ctx, circleId)
+			require.NoError(t, err)
+			assert.False(t, exists)
+		},
+	)
+
+	t.Run(
+		"should cache the memberships per member kind", func(t *testing.T) {
+			_, exists, err := c.CircleMembership(ctx, circleId, CircleMemberVoter, "voter")
+			require.NoError(t, err)
+			assert.False(t, exists)
+
+			require.NoError(t, c.SetCircleMembership(ctx, circleId, CircleMemberVoter, "voter", true, true))
+			require.NoError(t, c.SetCircleMembership(ctx, circleId, CircleMemberCandidate, "voter", false, true))
+
+			isMember, exists, err := c.CircleMembership(ctx, circleId, CircleMemberVoter, "voter")
+			require.NoError(t, err)
+			assert.True(t, exists)
+			assert.True(t, isMember)
+
+			isMember, exists, err = c.CircleMembership(ctx, circleId, CircleMemberCandidate, "voter")
+			require.NoError(t, err)
+			assert.True(t, exists)
+			assert.False(t, isMember)
+		},
+	)
+
+	t.Run(
+		"should only overwrite a cached membership if requested", func(t *testing.T) {
+			require.NoError(t, c.SetCircleMembership(ctx, circleId, CircleMemberVoter, "voter", false, true))
+
+			isMember, _, err := c.CircleMembership(ctx, circleId, CircleMemberVoter, "voter")
+			require.NoError(t, err)
+			assert.True(t, isMember)
+
+			require.NoError(t, c.SetCircleMembership(ctx, circleId, CircleMemberVoter, "voter", false, false))
+
+			isMember, _, err = c.CircleMembership(ctx, circleId, CircleMemberVoter, "voter")
+			require.NoError(t, err)
+			assert.False(t, isMember)
+		},
+	)
+}
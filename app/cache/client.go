@@ -11,10 +11,14 @@ type Client interface {
 	Get(ctx context.Context, key string) *redis.StringCmd
 	HSet(ctx context.Context, key string, values ...interface{}) *redis.IntCmd
 	HGet(ctx context.Context, key string, field string) *redis.StringCmd
+	HSetNX(ctx context.Context, key, field string, value interface{}) *redis.BoolCmd
 	HGetAll(ctx context.Context, key string) *redis.StringStringMapCmd
 	HDel(ctx context.Context, key string, fields ...string) *redis.IntCmd
+	Del(ctx context.Context, keys ...string) *redis.IntCmd
 	Expire(ctx context.Context, key string, expiration time.Duration) *redis.BoolCmd
 	FlushDB(ctx context.Context) *redis.StatusCmd
+	Ping(ctx context.Context) *redis.StatusCmd
+	IncrBy(ctx context.Context, key string, value int64) *redis.IntCmd
 	Exists(ctx context.Context, keys ...string) *redis.IntCmd
 	ZAdd(ctx context.Context, key string, members ...*redis.Z) *redis.IntCmd
 	ZRem(ctx context.Context, key string, members ...interface{}) *redis.IntCmd
@@ -22,6 +26,11 @@ type Client interface {
 	ZScore(ctx context.Context, key string, member string) *redis.FloatCmd
 	ZRevRank(ctx context.Context, key string, member string) *redis.IntCmd
 	ZRevRange(ctx context.Context, key string, start int64, stop int64) *redis.StringSliceCmd
+	ZRangeByScore(ctx context.Context, key string, opt *redis.ZRangeBy) *redis.StringSliceCmd
+	ZRemRangeByRank(ctx context.Context, key string, start int64, stop int64) *redis.IntCmd
+	ZRemRangeByScore(ctx context.Context, key, min, max string) *redis.IntCmd
+	ZCard(ctx context.Context, key string) *redis.IntCmd
 	ZRangeArgs(ctx context.Context, z redis.ZRangeArgs) *redis.StringSliceCmd
 	Pipelined(ctx context.Context, fn func(redis.Pipeliner) error) ([]redis.Cmder, error)
+	Subscribe(ctx context.Context, channels ...string) *redis.PubSub
 }
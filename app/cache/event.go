@@ -0,0 +1,157 @@
+package cache
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/VerzCar/vyf-vote-circle/api/model"
+	"github.com/go-redis/redis/v8"
+	"time"
+)
+
+// eventLogExpiration of the event log and the event sequence of a circle
+const eventLogExpiration = time.Duration(72) * time.Hour
+
+// defaultEventLogSize is used if no event log size is configured.
+const defaultEventLogSize = int64(500)
+
+// ReserveEventSequences of the circle for the given count of events.
+// Returns the last reserved sequence number. The reserved sequence numbers
+// are the count of numbers up to and including the last reserved one.
+func (c *redisCache) ReserveEventSequences(
+	ctx context.Context,
+	circleId int64,
+	count int64,
+) (int64, error) {
+	key := circleEventSequenceKey(circleId)
+	var incr *redis.IntCmd
+
+	_, err := c.redis.Pipelined(
+		ctx, func(pipe redis.Pipeliner) error {
+			incr = pipe.IncrBy(ctx, key, count)
+			pipe.Expire(ctx, key, eventLogExpiration)
+			return nil
+		},
+	)
+
+	if err != nil {
+		c.log.Errorf("could not reserve event sequences for circle key %s: %s", key, err)
+		return 0, err
+	}
+
+	return incr.Val(), nil
+}
+
+// AppendEventLog of the circle with the given entries.
+// The event log is bounded to the configured size, the oldest
+// entries will be removed.
+func (c *redisCache) AppendEventLog(
+	ctx context.Context,
+	circleId int64,
+	entries []*model.EventLogEntry,
+) error {
+	if len(entries) == 0 {
+		return nil
+	}
+
+	key := circleEventLogKey(circleId)
+	members := make([]*redis.Z, 0, len(entries))
+
+	for _, entry := range entries {
+		member, err := json.Marshal(entry)
+
+		if err != nil {
+			return err
+		}
+
+		members = append(members, &redis.Z{Score: float64(entry.Sequence), Member: member})
+	}
+
+	_, err := c.redis.Pipelined(
+		ctx, func(pipe redis.Pipeliner) error {
+			pipe.ZAdd(ctx, key, members...)
+			pipe.ZRemRangeByRank(ctx, key, 0, -c.eventLogSize()-1)
+			pipe.Expire(ctx, key, eventLogExpiration)
+			return nil
+		},
+	)
+
+	if err != nil {
+		c.log.Errorf("could not append event log for circle key %s: %s", key, err)
+		return err
+	}
+
+	return nil
+}
+
+// EventLog of the circle with all entries after the since sequence number
+// ordered by the sequence number.
+// Returns the entries and the latest sequence number of the circle.
+func (c *redisCache) EventLog(
+	ctx context.Context,
+	circleId int64,
+	since int64,
+) ([]*model.EventLogEntry, int64, error) {
+	key := circleEventLogKey(circleId)
+
+	members, err := c.redis.ZRangeByScore(
+		ctx, key, &redis.ZRangeBy{
+			Min: fmt.Sprintf("(%d", since),
+			Max: "+inf",
+		},
+	).Result()
+
+	if err != nil && !errors.Is(err, redis.Nil) {
+		c.log.Errorf("could not read event log for circle key %s: %s", key, err)
+		return nil, 0, err
+	}
+
+	sequence, err := c.redis.Get(ctx, circleEventSequenceKey(circleId)).Int64()
+
+	if err != nil && !errors.Is(err, redis.Nil) {
+		c.log.Errorf("could not read event sequence for circle key %s: %s", key, err)
+		return nil, 0, err
+	}
+
+	entries, err := eventLogEntries(members)
+
+	if err != nil {
+		c.log.Errorf("could not read event log for circle key %s: %s", key, err)
+		return nil, 0, err
+	}
+
+	return entries, sequence, nil
+}
+
+func (c *redisCache) eventLogSize() int64 {
+	if c.config.Cache.EventLogSize > 0 {
+		return c.config.Cache.EventLogSize
+	}
+	return defaultEventLogSize
+}
+
+// eventLogEntries of the JSON encoded members of the event log
+func eventLogEntries(members []string) ([]*model.EventLogEntry, error) {
+	entries := make([]*model.EventLogEntry, 0, len(members))
+
+	for _, member := range members {
+		entry := &model.EventLogEntry{}
+
+		if err := json.Unmarshal([]byte(member), entry); err != nil {
+			return nil, err
+		}
+
+		entries = append(entries, entry)
+	}
+
+	return entries, nil
+}
+
+func circleEventSequenceKey(circleId int64) string {
+	return fmt.Sprintf("circle:%d:events:sequence", circleId)
+}
+
+func circleEventLogKey(circleId int64) string {
+	return fmt.Sprintf("circle:%d:events", circleId)
+}
@@ -0,0 +1,118 @@
+package cache
+
+import (
+	"context"
+	"encoding/json"
+	"github.com/VerzCar/vyf-vote-circle/api/model"
+	appConfig "github.com/VerzCar/vyf-vote-circle/app/config"
+	"github.com/go-redis/redis/v8"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"testing"
+	"time"
+)
+
+func TestMemoryCache_EventLog(t *testing.T) {
+	runEventLogCacheSuite(t, NewMemoryCache(eventLogTestConfig(), testLog))
+}
+
+// TestRedisCache_EventLog runs the same suite against the configured redis
+// server and is skipped if the server is not reachable.
+func TestRedisCache_EventLog(t *testing.T) {
+	opt, err := redis.ParseURL(redisUrl(testConfig))
+	require.NoError(t, err)
+
+	opt.DialTimeout = time.Second
+	rdb := redis.NewClient(opt)
+	defer rdb.Close()
+
+	if err := rdb.Ping(context.Background()).Err(); err != nil {
+		t.Skipf("redis not reachable: %s", err)
+	}
+
+	runEventLogCacheSuite(t, NewRedisCache(rdb, eventLogTestConfig(), testLog))
+}
+
+// eventLogTestConfig with a small event log size
+func eventLogTestConfig() *appConfig.Config {
+	conf := *testConfig
+	conf.Cache.EventLogSize = 3
+	return &conf
+}
+
+// runEventLogCacheSuite verifies the behaviour every implementation
+// of the RedisCache must fulfill for the event log.
+func runEventLogCacheSuite(t *testing.T, c RedisCache) {
+	ctx := context.Background()
+	circleId := time.Now().UnixNano()
+
+	t.Run(
+		"should not contain any events for an unknown circle", func(t *testing.T) {
+			entries, sequence, err := c.EventLog(ctx, circleId, 0)
+			require.NoError(t, err)
+			assert.Empty(t, entries)
+			assert.Equal(t, int64(0), sequence)
+		},
+	)
+
+	t.Run(
+		"should reserve increasing sequences", func(t *testing.T) {
+			sequence, err := c.ReserveEventSequences(ctx, circleId, 2)
+			require.NoError(t, err)
+			assert.Equal(t, int64(2), sequence)
+
+			sequence, err = c.ReserveEventSequences(ctx, circleId, 1)
+			require.NoError(t, err)
+			assert.Equal(t, int64(3), sequence)
+		},
+	)
+
+	t.Run(
+		"should return the events after the sequence", func(t *testing.T) {
+			require.NoError(t, c.AppendEventLog(ctx, circleId, testEventLogEntries(1, 2, 3)))
+
+			entries, sequence, err := c.EventLog(ctx, circleId, 1)
+			require.NoError(t, err)
+			assert.Equal(t, int64(3), sequence)
+			assert.Equal(t, []int64{2, 3}, sequences(entries))
+			assert.Equal(t, model.EventKindRanking, entries[0].Kind)
+			assert.JSONEq(t, `{"sequence":2}`, string(entries[0].Event))
+		},
+	)
+
+	t.Run(
+		"should bound the event log to the configured size", func(t *testing.T) {
+			_, err := c.ReserveEventSequences(ctx, circleId, 2)
+			require.NoError(t, err)
+			require.NoError(t, c.AppendEventLog(ctx, circleId, testEventLogEntries(4, 5)))
+
+			entries, sequence, err := c.EventLog(ctx, circleId, 0)
+			require.NoError(t, err)
+			assert.Equal(t, int64(5), sequence)
+			assert.Equal(t, []int64{3, 4, 5}, sequences(entries))
+		},
+	)
+}
+
+func testEventLogEntries(sequenceNumbers ...int64) []*model.EventLogEntry {
+	var entries []*model.EventLogEntry
+	for _, sequence := range sequenceNumbers {
+		event, _ := json.Marshal(map[string]int64{"sequence": sequence})
+		entries = append(
+			entries, &model.EventLogEntry{
+				Event:    event,
+				Kind:     model.EventKindRanking,
+				Sequence: sequence,
+			},
+		)
+	}
+	return entries
+}
+
+func sequences(entries []*model.EventLogEntry) []int64 {
+	var n []int64
+	for _, entry := range entries {
+		n = append(n, entry.Sequence)
+	}
+	return n
+}
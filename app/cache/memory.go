@@ -0,0 +1,261 @@
+package cache
+
+import (
+	"context"
+	logger "github.com/VerzCar/vyf-lib-logger"
+	"github.com/VerzCar/vyf-vote-circle/app/config"
+	"sort"
+	"sync"
+	"time"
+)
+
+// memorySweepInterval in which expired keys of the memory cache
+// are removed, even if they are not accessed anymore.
+const memorySweepInterval = time.Minute
+
+type memoryCache struct {
+	mu        sync.Mutex
+	values    map[string]string
+	sets      map[string]map[string]float64
+	hashes    map[string]map[string]string
+	expires   map[string]time.Time
+	lastSweep time.Time
+	now       func() time.Time
+	config    *config.Config
+	log       logger.Logger
+}
+
+// NewMemoryCache creates an in-process implementation of the RedisCache.
+// It provides the same sorted set, hash and expiration semantics as
+// the redis implementation, but without the need of a redis server.
+// As the entries are not shared, it must only be used for a single instance
+// of the service, e.g. for local development and tests.
+func NewMemoryCache(
+	config *config.Config,
+	log logger.Logger,
+) RedisCache {
+	return &memoryCache{
+		values:  make(map[string]string),
+		sets:    make(map[string]map[string]float64),
+		hashes:  make(map[string]map[string]string),
+		expires: make(map[string]time.Time),
+		now:     time.Now,
+		config:  config,
+		log:     log,
+	}
+}
+
+// memoryZ is a member of a sorted set with its score
+type memoryZ struct {
+	member string
+	score  float64
+}
+
+// Ping the cache. The memory cache is always reachable.
+func (c *memoryCache) Ping(ctx context.Context) error {
+	return nil
+}
+
+// FlushAll entries of the cache
+func (c *memoryCache) FlushAll() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.values = make(map[string]string)
+	c.sets = make(map[string]map[string]float64)
+	c.hashes = make(map[string]map[string]string)
+	c.expires = make(map[string]time.Time)
+
+	return nil
+}
+
+// exists checks whether the key exists and has not expired yet.
+// An expired key will be removed.
+// The caller must hold the lock.
+func (c *memoryCache) exists(key string) bool {
+	c.sweep()
+
+	if expiresAt, ok := c.expires[key]; ok && !c.now().Before(expiresAt) {
+		c.del(key)
+		return false
+	}
+
+	_, isValue := c.values[key]
+	_, isSet := c.sets[key]
+	_, isHash := c.hashes[key]
+
+	return isValue || isSet || isHash
+}
+
+// del removes the key with all its entries.
+// The caller must hold the lock.
+func (c *memoryCache) del(key string) {
+	delete(c.values, key)
+	delete(c.sets, key)
+	delete(c.hashes, key)
+	delete(c.expires, key)
+}
+
+// expire sets the expiration of the key, if the key exists.
+// The caller must hold the lock.
+func (c *memoryCache) expire(key string, expiration time.Duration) {
+	if !c.exists(key) {
+		return
+	}
+
+	c.expires[key] = c.now().Add(expiration)
+}
+
+// get the value of the key.
+// Returns false if the key does not exist.
+// The caller must hold the lock.
+func (c *memoryCache) get(key string) (string, bool) {
+	if !c.exists(key) {
+		return "", false
+	}
+
+	val, ok := c.values[key]
+
+	return val, ok
+}
+
+// set the value of the key with the expiration.
+// The caller must hold the lock.
+func (c *memoryCache) set(key string, value string, expiration time.Duration) {
+	c.del(key)
+	c.values[key] = value
+	c.expire(key, expiration)
+}
+
+// zAdd adds or updates the member with the score in the sorted set of the key.
+// The caller must hold the lock.
+func (c *memoryCache) zAdd(key string, members ...memoryZ) {
+	if !c.exists(key) {
+		c.sets[key] = make(map[string]float64)
+	}
+
+	for _, z := range members {
+		c.sets[key][z.member] = z.score
+	}
+}
+
+// zRem removes the members from the sorted set of the key.
+// As in redis, an empty sorted set will be removed.
+// The caller must hold the lock.
+func (c *memoryCache) zRem(key string, members ...string) {
+	if !c.exists(key) {
+		return
+	}
+
+	for _, member := range members {
+		delete(c.sets[key], member)
+	}
+
+	if len(c.sets[key]) == 0 {
+		c.del(key)
+	}
+}
+
+// zRevRange of the sorted set of the key ordered by
+// the score from high to low. Members with the same score are
+// ordered reverse lexicographically, as redis does.
+// The caller must hold the lock.
+func (c *memoryCache) zRevRange(key string) []memoryZ {
+	if !c.exists(key) {
+		return nil
+	}
+
+	members := make([]memoryZ, 0, len(c.sets[key]))
+
+	for member, score := range c.sets[key] {
+		members = append(members, memoryZ{member: member, score: score})
+	}
+
+	sort.Slice(
+		members, func(i, j int) bool {
+			if members[i].score != members[j].score {
+				return members[i].score > members[j].score
+			}
+			return members[i].member > members[j].member
+		},
+	)
+
+	return members
+}
+
+// hSet sets the fields of the hash of the key.
+// The caller must hold the lock.
+func (c *memoryCache) hSet(key string, fields map[string]string) {
+	if !c.exists(key) {
+		c.hashes[key] = make(map[string]string)
+	}
+
+	for field, value := range fields {
+		c.hashes[key][field] = value
+	}
+}
+
+// hGetAll fields of the hash of the key.
+// Returns an empty map if the key does not exist.
+// The caller must hold the lock.
+func (c *memoryCache) hGetAll(key string) map[string]string {
+	fields := make(map[string]string)
+
+	if !c.exists(key) {
+		return fields
+	}
+
+	for field, value := range c.hashes[key] {
+		fields[field] = value
+	}
+
+	return fields
+}
+
+// hGet the field of the hash of the key.
+// Returns false if the key or the field does not exist.
+// The caller must hold the lock.
+func (c *memoryCache) hGet(key string, field string) (string, bool) {
+	if !c.exists(key) {
+		return "", false
+	}
+
+	val, ok := c.hashes[key][field]
+
+	return val, ok
+}
+
+// hDel removes the fields from the hash of the key.
+// As in redis, an empty hash will be removed.
+// The caller must hold the lock.
+func (c *memoryCache) hDel(key string, fields ...string) {
+	if !c.exists(key) {
+		return
+	}
+
+	for _, field := range fields {
+		delete(c.hashes[key], field)
+	}
+
+	if len(c.hashes[key]) == 0 {
+		c.del(key)
+	}
+}
+
+// sweep removes all expired keys, at most once in the memorySweepInterval.
+// The caller must hold the lock.
+func (c *memoryCache) sweep() {
+	now := c.now()
+
+	if now.Sub(c.lastSweep) < memorySweepInterval {
+		return
+	}
+
+	c.lastSweep = now
+
+	for key, expiresAt := range c.expires {
+		if !now.Before(expiresAt) {
+			c.del(key)
+		}
+	}
+}
@@ -0,0 +1,111 @@
+package cache
+
+import (
+	"context"
+	"encoding/json"
+	"github.com/VerzCar/vyf-vote-circle/api/model"
+)
+
+// Circle of the given circle id from the cache.
+// Returns false if the circle is not cached.
+func (c *memoryCache) Circle(
+	ctx context.Context,
+	circleId int64,
+) (*model.Circle, bool, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	val, ok := c.get(circleKey(circleId))
+
+	if !ok {
+		return nil, false, nil
+	}
+
+	circle := &model.Circle{}
+
+	if err := json.Unmarshal([]byte(val), circle); err != nil {
+		c.log.Errorf("could not read circle for circle id %d: %s", circleId, err)
+		return nil, false, err
+	}
+
+	return circle, true, nil
+}
+
+// SetCircle in the cache with the expiration of the circle.
+func (c *memoryCache) SetCircle(
+	ctx context.Context,
+	circle *model.Circle,
+) error {
+	encodedData, err := json.Marshal(circle)
+
+	if err != nil {
+		c.log.Errorf("could not set circle for circle id %d: %s", circle.ID, err)
+		return err
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.set(circleKey(circle.ID), string(encodedData), circleExpiration)
+
+	return nil
+}
+
+// RemoveCircle from the cache.
+func (c *memoryCache) RemoveCircle(
+	ctx context.Context,
+	circleId int64,
+) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.del(circleKey(circleId))
+
+	return nil
+}
+
+// CircleMembership of the user in the circle.
+// Returns whether the user is a member and false for exists,
+// if the membership of the user is not cached.
+func (c *memoryCache) CircleMembership(
+	ctx context.Context,
+	circleId int64,
+	member CircleMember,
+	userIdentityId string,
+) (bool, bool, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	val, ok := c.hGet(circleMembersKey(circleId, member), userIdentityId)
+
+	if !ok {
+		return false, false, nil
+	}
+
+	return val == circleMembershipMember, true, nil
+}
+
+// SetCircleMembership of the user in the circle
+// with the expiration of the circle.
+// If onlyIfAbsent is set, an already cached membership will not be overwritten.
+func (c *memoryCache) SetCircleMembership(
+	ctx context.Context,
+	circleId int64,
+	member CircleMember,
+	userIdentityId string,
+	isMember bool,
+	onlyIfAbsent bool,
+) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	key := circleMembersKey(circleId, member)
+
+	if _, exists := c.hGet(key, userIdentityId); !exists || !onlyIfAbsent {
+		c.hSet(key, map[string]string{userIdentityId: circleMembershipValue(isMember)})
+	}
+
+	c.expire(key, circleExpiration)
+
+	return nil
+}
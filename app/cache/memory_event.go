@@ -0,0 +1,111 @@
+package cache
+
+import (
+	"context"
+	"encoding/json"
+	"github.com/VerzCar/vyf-vote-circle/api/model"
+	"strconv"
+)
+
+// ReserveEventSequences of the circle for the given count of events.
+// Returns the last reserved sequence number. The reserved sequence numbers
+// are the count of numbers up to and including the last reserved one.
+func (c *memoryCache) ReserveEventSequences(
+	ctx context.Context,
+	circleId int64,
+	count int64,
+) (int64, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	key := circleEventSequenceKey(circleId)
+	val, _ := c.get(key)
+	sequence, _ := strconv.ParseInt(val, 10, 64)
+	sequence += count
+
+	c.set(key, strconv.FormatInt(sequence, 10), eventLogExpiration)
+
+	return sequence, nil
+}
+
+// AppendEventLog of the circle with the given entries.
+// The event log is bounded to the configured size, the oldest
+// entries will be removed.
+func (c *memoryCache) AppendEventLog(
+	ctx context.Context,
+	circleId int64,
+	entries []*model.EventLogEntry,
+) error {
+	if len(entries) == 0 {
+		return nil
+	}
+
+	members := make([]memoryZ, 0, len(entries))
+
+	for _, entry := range entries {
+		member, err := json.Marshal(entry)
+
+		if err != nil {
+			return err
+		}
+
+		members = append(members, memoryZ{member: string(member), score: float64(entry.Sequence)})
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	key := circleEventLogKey(circleId)
+	c.zAdd(key, members...)
+
+	size := c.eventLogSize()
+	logged := c.zRevRange(key)
+
+	for index := size; index < int64(len(logged)); index++ {
+		c.zRem(key, logged[index].member)
+	}
+
+	c.expire(key, eventLogExpiration)
+
+	return nil
+}
+
+// EventLog of the circle with all entries after the since sequence number
+// ordered by the sequence number.
+// Returns the entries and the latest sequence number of the circle.
+func (c *memoryCache) EventLog(
+	ctx context.Context,
+	circleId int64,
+	since int64,
+) ([]*model.EventLogEntry, int64, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	logged := c.zRevRange(circleEventLogKey(circleId))
+	members := make([]string, 0)
+
+	for index := len(logged) - 1; index >= 0; index-- {
+		if logged[index].score > float64(since) {
+			members = append(members, logged[index].member)
+		}
+	}
+
+	val, _ := c.get(circleEventSequenceKey(circleId))
+	sequence, _ := strconv.ParseInt(val, 10, 64)
+
+	entries, err := eventLogEntries(members)
+
+	if err != nil {
+		c.log.Errorf("could not read event log for circle id %d: %s", circleId, err)
+		return nil, 0, err
+	}
+
+	return entries, sequence, nil
+}
+
+func (c *memoryCache) eventLogSize() int64 {
+	if c.config.Cache.EventLogSize > 0 {
+		return c.config.Cache.EventLogSize
+	}
+	return defaultEventLogSize
+}
@@ -0,0 +1,92 @@
+package cache
+
+import (
+	"context"
+	"time"
+)
+
+// TouchCirclePresence of the viewer in the circle until the given expiry.
+// The viewers, whose presence has expired, will be removed.
+// Returns the count of the current viewers and whether the viewers changed.
+func (c *memoryCache) TouchCirclePresence(
+	ctx context.Context,
+	circleId int64,
+	userIdentityId string,
+	expiresAt time.Time,
+) (int64, bool, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	key := circlePresenceKey(circleId)
+	changed := c.removeExpiredPresence(key)
+	_, present := c.sets[key][userIdentityId]
+
+	c.zAdd(key, memoryZ{member: userIdentityId, score: float64(expiresAt.UnixMilli())})
+	c.expire(key, presenceExpiration)
+
+	return int64(len(c.sets[key])), changed || !present, nil
+}
+
+// RemoveCirclePresence of the viewer in the circle, together with the
+// viewers, whose presence has expired.
+// Returns the count of the current viewers and whether the viewers changed.
+func (c *memoryCache) RemoveCirclePresence(
+	ctx context.Context,
+	circleId int64,
+	userIdentityId string,
+) (int64, bool, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	key := circlePresenceKey(circleId)
+	changed := c.removeExpiredPresence(key)
+	_, present := c.sets[key][userIdentityId]
+
+	c.zRem(key, userIdentityId)
+
+	return int64(len(c.sets[key])), changed || present, nil
+}
+
+// CirclePresence of the circle with the identities of the current viewers
+// ordered by the time their presence expires.
+func (c *memoryCache) CirclePresence(
+	ctx context.Context,
+	circleId int64,
+) ([]string, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	now := float64(c.now().UnixMilli())
+	members := c.zRevRange(circlePresenceKey(circleId))
+	viewers := make([]string, 0, len(members))
+
+	for index := len(members) - 1; index >= 0; index-- {
+		if members[index].score > now {
+			viewers = append(viewers, members[index].member)
+		}
+	}
+
+	return viewers, nil
+}
+
+// removeExpiredPresence of the viewers of the presence key.
+// Returns whether a viewer has been removed.
+// The caller must hold the lock.
+func (c *memoryCache) removeExpiredPresence(key string) bool {
+	if !c.exists(key) {
+		return false
+	}
+
+	now := float64(c.now().UnixMilli())
+	expired := make([]string, 0)
+
+	for member, score := range c.sets[key] {
+		if score <= now {
+			expired = append(expired, member)
+		}
+	}
+
+	c.zRem(key, expired...)
+
+	return len(expired) > 0
+}
@@ -0,0 +1,201 @@
+package cache
+
+import (
+	"context"
+	"github.com/VerzCar/vyf-vote-circle/api/model"
+	"strconv"
+	"time"
+)
+
+// UpsertRanking of the candidate with the score and the tally of the given ranking
+func (c *memoryCache) UpsertRanking(
+	ctx context.Context,
+	circleId int64,
+	candidate *model.CircleCandidate,
+	ranking *model.Ranking,
+) (*model.RankingResponse, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	rankingScore := c.setRankingScore(circleId, candidate, ranking)
+
+	rankingPlacementIndex := int64(0)
+	highestVotedMemberIndex := int64(-1)
+
+	for index, z := range c.zRevRange(circleRankingKey(circleId)) {
+		if z.member == rankingScore.UserIdentityId {
+			rankingPlacementIndex = int64(index)
+		}
+		if highestVotedMemberIndex < 0 && z.score == rankingScore.Score {
+			highestVotedMemberIndex = int64(index)
+		}
+	}
+
+	placementNumber := highestVotedMemberIndex + 1
+
+	rankingRes := populateRanking(
+		ranking.ID,
+		circleId,
+		candidate.ID,
+		rankingScore,
+		&model.RankingTally{Total: ranking.Total, Ballots: ranking.Ballots},
+		rankingPlacementIndex,
+		placementNumber,
+		ranking.CreatedAt,
+		ranking.UpdatedAt,
+	)
+
+	return rankingRes, nil
+}
+
+func (c *memoryCache) RemoveRanking(
+	ctx context.Context,
+	circleId int64,
+	candidate *model.CircleCandidate,
+) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.zRem(circleRankingKey(circleId), candidate.Candidate)
+	c.hDel(circleUserCandidateKey(circleId, candidate.Candidate), userCandidateFields...)
+
+	return nil
+}
+
+// RankingList of the current cached ranking for the circle
+func (c *memoryCache) RankingList(
+	ctx context.Context,
+	circleId int64,
+	fromRanking *model.RankingResponse,
+) ([]*model.RankingResponse, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	fromIndex := 0
+
+	if fromRanking != nil {
+		fromIndex = int(fromRanking.IndexedOrder + 1)
+	}
+
+	members := c.zRevRange(circleRankingKey(circleId))
+
+	if fromIndex > len(members) {
+		fromIndex = len(members)
+	}
+
+	rankingScores := make([]*model.RankingScore, 0)
+	rankingUserCandidates := make([]*model.RankingUserCandidate, 0)
+
+	for _, z := range members[fromIndex:] {
+		rankingScores = append(
+			rankingScores, &model.RankingScore{
+				Score:          z.score,
+				UserIdentityId: z.member,
+			},
+		)
+
+		fields := c.hGetAll(circleUserCandidateKey(circleId, z.member))
+		rankingUserCandidates = append(rankingUserCandidates, rankingUserCandidateFromFields(fields))
+	}
+
+	return populateRankingList(circleId, rankingScores, rankingUserCandidates, fromRanking), nil
+}
+
+// ExistsRankingListForCircle with given circle id checks whether a
+// ranking list for this circle is in cache.
+// Returns true if exists in cache, otherwise false.
+func (c *memoryCache) ExistsRankingListForCircle(
+	ctx context.Context,
+	circleId int64,
+) (bool, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.exists(circleRankingKey(circleId)), nil
+}
+
+// BuildRankingList from the aggregated ranking cache items of the circle id.
+func (c *memoryCache) BuildRankingList(
+	ctx context.Context,
+	circleId int64,
+	rankingCacheItems []*model.RankingCacheItem,
+) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for _, item := range rankingCacheItems {
+		c.setRankingScore(circleId, item.Candidate, item.Ranking)
+	}
+
+	return nil
+}
+
+// RemoveRankingList of the circle id with all the user candidates
+// of the ranking list.
+func (c *memoryCache) RemoveRankingList(
+	ctx context.Context,
+	circleId int64,
+) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	key := circleRankingKey(circleId)
+
+	for _, z := range c.zRevRange(key) {
+		c.del(circleUserCandidateKey(circleId, z.member))
+	}
+
+	c.del(key)
+
+	return nil
+}
+
+// setRankingScore of the candidate and the associated user candidate
+// with the expiration of the ranking.
+// The caller must hold the lock.
+func (c *memoryCache) setRankingScore(
+	circleId int64,
+	candidate *model.CircleCandidate,
+	ranking *model.Ranking,
+) *model.RankingScore {
+	key := circleRankingKey(circleId)
+	rankingScore := &model.RankingScore{
+		Score:          ranking.Score,
+		UserIdentityId: candidate.Candidate,
+	}
+
+	c.zAdd(key, memoryZ{member: rankingScore.UserIdentityId, score: rankingScore.Score})
+	c.expire(key, rankingExpiration)
+
+	candidateKey := circleUserCandidateKey(circleId, candidate.Candidate)
+	c.hSet(
+		candidateKey, map[string]string{
+			"candidateId": strconv.FormatInt(candidate.ID, 10),
+			"rankingId":   strconv.FormatInt(ranking.ID, 10),
+			"total":       strconv.FormatInt(ranking.Total, 10),
+			"ballots":     strconv.FormatInt(ranking.Ballots, 10),
+			"createdAt":   ranking.CreatedAt.Format(time.RFC3339Nano),
+			"updatedAt":   ranking.UpdatedAt.Format(time.RFC3339Nano),
+		},
+	)
+	c.expire(candidateKey, rankingExpiration)
+
+	return rankingScore
+}
+
+// rankingUserCandidateFromFields of the user candidate hash.
+// Only the fields are read, that are also scanned from the redis hash
+// into the model.RankingUserCandidate.
+func rankingUserCandidateFromFields(fields map[string]string) *model.RankingUserCandidate {
+	candidateId, _ := strconv.ParseInt(fields["candidateId"], 10, 64)
+	rankingId, _ := strconv.ParseInt(fields["rankingId"], 10, 64)
+	total, _ := strconv.ParseInt(fields["total"], 10, 64)
+	ballots, _ := strconv.ParseInt(fields["ballots"], 10, 64)
+
+	return &model.RankingUserCandidate{
+		CandidateID: candidateId,
+		RankingID:   rankingId,
+		Total:       total,
+		Ballots:     ballots,
+	}
+}
@@ -0,0 +1,103 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/go-redis/redis/v8"
+	"strconv"
+	"time"
+)
+
+// presenceExpiration of the presence of a circle without any heartbeat
+const presenceExpiration = time.Duration(1) * time.Hour
+
+// TouchCirclePresence of the viewer in the circle until the given expiry.
+// The viewers, whose presence has expired, will be removed.
+// Returns the count of the current viewers and whether the viewers changed.
+func (c *redisCache) TouchCirclePresence(
+	ctx context.Context,
+	circleId int64,
+	userIdentityId string,
+	expiresAt time.Time,
+) (int64, bool, error) {
+	key := circlePresenceKey(circleId)
+	var removed, added, count *redis.IntCmd
+
+	_, err := c.redis.Pipelined(
+		ctx, func(pipe redis.Pipeliner) error {
+			removed = pipe.ZRemRangeByScore(ctx, key, "-inf", presenceScore(time.Now()))
+			added = pipe.ZAdd(ctx, key, &redis.Z{Score: float64(expiresAt.UnixMilli()), Member: userIdentityId})
+			count = pipe.ZCard(ctx, key)
+			pipe.Expire(ctx, key, presenceExpiration)
+			return nil
+		},
+	)
+
+	if err != nil {
+		c.log.Errorf("could not touch presence for circle key %s: %s", key, err)
+		return 0, false, err
+	}
+
+	return count.Val(), removed.Val() > 0 || added.Val() > 0, nil
+}
+
+// RemoveCirclePresence of the viewer in the circle, together with the
+// viewers, whose presence has expired.
+// Returns the count of the current viewers and whether the viewers changed.
+func (c *redisCache) RemoveCirclePresence(
+	ctx context.Context,
+	circleId int64,
+	userIdentityId string,
+) (int64, bool, error) {
+	key := circlePresenceKey(circleId)
+	var expired, removed, count *redis.IntCmd
+
+	_, err := c.redis.Pipelined(
+		ctx, func(pipe redis.Pipeliner) error {
+			expired = pipe.ZRemRangeByScore(ctx, key, "-inf", presenceScore(time.Now()))
+			removed = pipe.ZRem(ctx, key, userIdentityId)
+			count = pipe.ZCard(ctx, key)
+			return nil
+		},
+	)
+
+	if err != nil {
+		c.log.Errorf("could not remove presence for circle key %s: %s", key, err)
+		return 0, false, err
+	}
+
+	return count.Val(), expired.Val() > 0 || removed.Val() > 0, nil
+}
+
+// CirclePresence of the circle with the identities of the current viewers
+// ordered by the time their presence expires.
+func (c *redisCache) CirclePresence(
+	ctx context.Context,
+	circleId int64,
+) ([]string, error) {
+	key := circlePresenceKey(circleId)
+
+	viewers, err := c.redis.ZRangeByScore(
+		ctx, key, &redis.ZRangeBy{
+			Min: "(" + presenceScore(time.Now()),
+			Max: "+inf",
+		},
+	).Result()
+
+	if err != nil && !errors.Is(err, redis.Nil) {
+		c.log.Errorf("could not read presence for circle key %s: %s", key, err)
+		return nil, err
+	}
+
+	return viewers, nil
+}
+
+// presenceScore of the time, that is the time in milliseconds
+func presenceScore(t time.Time) string {
+	return strconv.FormatInt(t.UnixMilli(), 10)
+}
+
+func circlePresenceKey(circleId int64) string {
+	return fmt.Sprintf("circle:%d:presence", circleId)
+}
@@ -0,0 +1,102 @@
+package cache
+
+import (
+	"context"
+	"github.com/go-redis/redis/v8"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"testing"
+	"time"
+)
+
+func TestMemoryCache_CirclePresence(t *testing.T) {
+	runCirclePresenceCacheSuite(t, NewMemoryCache(testConfig, testLog))
+}
+
+// TestRedisCache_CirclePresence runs the same suite against the configured redis
+// server and is skipped if the server is not reachable.
+func TestRedisCache_CirclePresence(t *testing.T) {
+	opt, err := redis.ParseURL(redisUrl(testConfig))
+	require.NoError(t, err)
+
+	opt.DialTimeout = time.Second
+	rdb := redis.NewClient(opt)
+	defer rdb.Close()
+
+	if err := rdb.Ping(context.Background()).Err(); err != nil {
+		t.Skipf("redis not reachable: %s", err)
+	}
+
+	runCirclePresenceCacheSuite(t, NewRedisCache(rdb, testConfig, testLog))
+}
+
+// runCirclePresenceCacheSuite verifies the behaviour every implementation
+// of the RedisCache must fulfill for the presence of a circle.
+func runCirclePresenceCacheSuite(t *testing.T, c RedisCache) {
+	ctx := context.Background()
+	circleId := time.Now().UnixNano()
+	expiresAt := time.Now().Add(time.Minute)
+
+	t.Run(
+		"should not contain any viewers for an unknown circle", func(t *testing.T) {
+			viewers, err := c.CirclePresence(ctx, circleId)
+			require.NoError(t, err)
+			assert.Empty(t, viewers)
+		},
+	)
+
+	t.Run(
+		"should count the viewers once", func(t *testing.T) {
+			count, changed, err := c.TouchCirclePresence(ctx, circleId, "viewer-1", expiresAt)
+			require.NoError(t, err)
+			assert.Equal(t, int64(1), count)
+			assert.True(t, changed)
+
+			count, changed, err = c.TouchCirclePresence(ctx, circleId, "viewer-2", expiresAt.Add(time.Second))
+			require.NoError(t, err)
+			assert.Equal(t, int64(2), count)
+			assert.True(t, changed)
+
+			count, changed, err = c.TouchCirclePresence(ctx, circleId, "viewer-1", expiresAt.Add(2*time.Second))
+			require.NoError(t, err)
+			assert.Equal(t, int64(2), count)
+			assert.False(t, changed)
+
+			viewers, err := c.CirclePresence(ctx, circleId)
+			require.NoError(t, err)
+			assert.Equal(t, []string{"viewer-2", "viewer-1"}, viewers)
+		},
+	)
+
+	t.Run(
+		"should remove the expired viewers", func(t *testing.T) {
+			count, changed, err := c.TouchCirclePresence(ctx, circleId, "viewer-3", time.Now().Add(-time.Second))
+			require.NoError(t, err)
+			assert.Equal(t, int64(3), count)
+			assert.True(t, changed)
+
+			viewers, err := c.CirclePresence(ctx, circleId)
+			require.NoError(t, err)
+			assert.Equal(t, []string{"viewer-2", "viewer-1"}, viewers)
+
+			count, changed, err = c.TouchCirclePresence(ctx, circleId, "viewer-1", expiresAt)
+			require.NoError(t, err)
+			assert.Equal(t, int64(2), count)
+			assert.True(t, changed)
+		},
+	)
+
+	t.Run(
+		"should remove the viewer", func(t *testing.T) {
+			count, changed, err := c.RemoveCirclePresence(ctx, circleId, "viewer-2")
+			require.NoError(t, err)
+			assert.Equal(t, int64(1), count)
+			assert.True(t, changed)
+
+			count, changed, err = c.RemoveCirclePresence(ctx, circleId, "viewer-2")
+			require.NoError(t, err)
+			assert.Equal(t, int64(1), count)
+			assert.False(t, changed)
+		},
+	)
+}
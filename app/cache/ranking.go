@@ -9,25 +9,28 @@ import (
 	"time"
 )
 
+// rankingExpiration of the ranking list and the user candidates of a circle
+const rankingExpiration = time.Duration(72) * time.Hour
+
+// UpsertRanking of the candidate with the score and the tally of the given ranking
 func (c *redisCache) UpsertRanking(
 	ctx context.Context,
 	circleId int64,
 	candidate *model.CircleCandidate,
 	ranking *model.Ranking,
-	votes int64,
 ) (*model.RankingResponse, error) {
 	// TODO: put this in transaction
-	rankingScore, err := c.setRankingScore(ctx, circleId, candidate, ranking, votes)
+	rankingScore, err := c.setRankingScore(ctx, circleId, candidate, ranking)
 
 	if err != nil {
 		return nil, err
 	}
 
-	rankingPlacementIndex, highestVotedMember, err := c.rankingIndexWithLastestVoteCountMember(
+	rankingPlacementIndex, highestVotedMember, err := c.rankingIndexWithLatestScoreMember(
 		ctx,
 		circleId,
 		rankingScore.UserIdentityId,
-		votes,
+		rankingScore.Score,
 	)
 
 	if err != nil {
@@ -54,6 +57,7 @@ func (c *redisCache) UpsertRanking(
 		circleId,
 		candidate.ID,
 		rankingScore,
+		&model.RankingTally{Total: ranking.Total, Ballots: ranking.Ballots},
 		rankingPlacementIndex,
 		placementNumber,
 		ranking.CreatedAt,
@@ -128,23 +132,83 @@ func (c *redisCache) ExistsRankingListForCircle(
 	return result.Val() > 0, nil
 }
 
-// BuildRankingList from votes for the circle id.
+// BuildRankingList from the aggregated ranking cache items of the circle id.
+// All ranking scores and user candidates are written in one single pipeline.
 func (c *redisCache) BuildRankingList(
 	ctx context.Context,
 	circleId int64,
 	rankingCacheItems []*model.RankingCacheItem,
 ) error {
-	for _, item := range rankingCacheItems {
-		_, err := c.setRankingScore(ctx, circleId, item.Candidate, item.Ranking, item.VoteCount)
-
-		if err != nil {
-			return err
-		}
+	if len(rankingCacheItems) == 0 {
+		return nil
 	}
 
+	key := circleRankingKey(circleId)
 	// TODO: set expiration based on circle inactive time
-	expirationDuration := time.Duration(72) * time.Hour
-	_ = c.setExpiration(ctx, circleRankingKey(circleId), expirationDuration)
+	expirationDuration := rankingExpiration
+
+	_, err := c.redis.Pipelined(
+		ctx, func(pipe redis.Pipeliner) error {
+			members := make([]*redis.Z, 0, len(rankingCacheItems))
+
+			for _, item := range rankingCacheItems {
+				members = append(
+					members, &redis.Z{
+						Score:  item.Ranking.Score,
+						Member: item.Candidate.Candidate,
+					},
+				)
+
+				candidateKey := circleUserCandidateKey(circleId, item.Candidate.Candidate)
+				pipeSetUserCandidate(ctx, pipe, candidateKey, item.Candidate, item.Ranking)
+				pipeExpire(ctx, pipe, candidateKey, expirationDuration)
+			}
+
+			pipe.ZAdd(ctx, key, members...)
+			pipeExpire(ctx, pipe, key, expirationDuration)
+			return nil
+		},
+	)
+
+	if err != nil {
+		c.log.Errorf(
+			"could not build ranking list in pipeline for circle key %s: %s",
+			key,
+			err,
+		)
+		return err
+	}
+
+	return nil
+}
+
+// RemoveRankingList of the circle id with all the user candidates
+// of the ranking list.
+func (c *redisCache) RemoveRankingList(
+	ctx context.Context,
+	circleId int64,
+) error {
+	key := circleRankingKey(circleId)
+
+	members, err := c.redis.ZRevRange(ctx, key, 0, -1).Result()
+
+	if err != nil && !errors.Is(err, redis.Nil) {
+		c.log.Errorf("could not read ranking members for circle key %s: %s", key, err)
+		return err
+	}
+
+	keys := []string{key}
+
+	for _, member := range members {
+		keys = append(keys, circleUserCandidateKey(circleId, member))
+	}
+
+	err = c.redis.Del(ctx, keys...).Err()
+
+	if err != nil {
+		c.log.Errorf("could not remove ranking list for circle key %s: %s", key, err)
+		return err
+	}
 
 	return nil
 }
@@ -154,15 +218,14 @@ func (c *redisCache) setRankingScore(
 	circleId int64,
 	candidate *model.CircleCandidate,
 	ranking *model.Ranking,
-	votes int64,
 ) (*model.RankingScore, error) {
 	key := circleRankingKey(circleId)
 	rankingScore := &model.RankingScore{
-		VoteCount:      votes,
+		Score:          ranking.Score,
 		UserIdentityId: candidate.Candidate,
 	}
 
-	expirationDuration := time.Duration(72) * time.Hour
+	expirationDuration := rankingExpiration
 
 	_, err := c.redis.Pipelined(
 		ctx, func(pipe redis.Pipeliner) error {
@@ -214,16 +277,7 @@ func (c *redisCache) rankingList(
 		return nil, err
 	}
 
-	rankingList := make([]*model.RankingResponse, 0)
-	placementNumber := int64(0)
-	fromIndex := int64(0)
-	voteCount := int64(0)
-
-	if fromRanking != nil {
-		placementNumber = fromRanking.Number
-		fromIndex = fromRanking.IndexedOrder + 1
-		voteCount = fromRanking.Votes
-	}
+	rankingUserCandidates := make([]*model.RankingUserCandidate, 0, len(rankingScores))
 
 	for placementIndex, rankingScore := range rankingScores {
 		var rankingUserCandidate model.RankingUserCandidate
@@ -240,27 +294,10 @@ func (c *redisCache) rankingList(
 			return nil, err
 		}
 
-		if voteCount != rankingScore.VoteCount {
-			voteCount = rankingScore.VoteCount
-			placementNumber++
-		}
-
-		rankingList = append(
-			rankingList,
-			populateRanking(
-				rankingUserCandidate.RankingID,
-				circleId,
-				rankingUserCandidate.CandidateID,
-				rankingScore,
-				int64(placementIndex)+fromIndex,
-				placementNumber,
-				rankingUserCandidate.CreatedAt,
-				rankingUserCandidate.UpdatedAt,
-			),
-		)
+		rankingUserCandidates = append(rankingUserCandidates, &rankingUserCandidate)
 	}
 
-	return rankingList, nil
+	return populateRankingList(circleId, rankingScores, rankingUserCandidates, fromRanking), nil
 }
 
 func (c *redisCache) removeRanking(
@@ -291,11 +328,13 @@ func (c *redisCache) removeRanking(
 	return nil
 }
 
-func (c *redisCache) rankingIndexWithLastestVoteCountMember(
+// rankingIndexWithLatestScoreMember returns the index of the member and the
+// first member with the same score, that determines the placement number.
+func (c *redisCache) rankingIndexWithLatestScoreMember(
 	ctx context.Context,
 	circleId int64,
 	member string,
-	voteCount int64,
+	score float64,
 ) (int64, string, error) {
 	key := circleRankingKey(circleId)
 
@@ -304,8 +343,8 @@ func (c *redisCache) rankingIndexWithLastestVoteCountMember(
 			pipe.ZRevRank(ctx, key, member)
 			rangeArgs := redis.ZRangeArgs{
 				Key:     key,
-				Start:   voteCount,
-				Stop:    voteCount,
+				Start:   score,
+				Stop:    score,
 				ByScore: true,
 				ByLex:   false,
 				Rev:     true,
@@ -350,7 +389,7 @@ func (c *redisCache) rankingScores(
 		var rankingScores []*model.RankingScore
 		for _, z := range result.Val() {
 			rankingScore := &model.RankingScore{
-				VoteCount:      int64(z.Score),
+				Score:          z.Score,
 				UserIdentityId: z.Member.(string),
 			}
 			rankingScores = append(rankingScores, rankingScore)
@@ -365,7 +404,7 @@ func (c *redisCache) rankingScore(
 	ctx context.Context,
 	key string,
 	member string,
-) (int64, error) {
+) (float64, error) {
 	result := c.redis.ZScore(ctx, key, member)
 
 	switch {
@@ -374,7 +413,7 @@ func (c *redisCache) rankingScore(
 	case result.Err() != nil:
 		return 0, result.Err()
 	default:
-		return int64(result.Val()), nil
+		return result.Val(), nil
 	}
 }
 
@@ -435,7 +474,7 @@ func pipeSetRankingScore(
 	rankingScore *model.RankingScore,
 ) {
 	members := &redis.Z{
-		Score:  float64(rankingScore.VoteCount),
+		Score:  rankingScore.Score,
 		Member: rankingScore.UserIdentityId,
 	}
 	pipe.ZAdd(ctx, key, members)
@@ -462,6 +501,9 @@ func pipeExpire(
 	pipe.Expire(ctx, key, expiration)
 }
 
+// userCandidateFields of the user candidate hash of a ranking
+var userCandidateFields = []string{"candidateId", "rankingId", "total", "ballots", "createdAt", "updatedAt"}
+
 func pipeSetUserCandidate(
 	ctx context.Context,
 	pipe redis.Pipeliner,
@@ -471,6 +513,8 @@ func pipeSetUserCandidate(
 ) {
 	pipe.HSet(ctx, key, "candidateId", candidate.ID)
 	pipe.HSet(ctx, key, "rankingId", ranking.ID)
+	pipe.HSet(ctx, key, "total", ranking.Total)
+	pipe.HSet(ctx, key, "ballots", ranking.Ballots)
 	pipe.HSet(ctx, key, "createdAt", ranking.CreatedAt)
 	pipe.HSet(ctx, key, "updatedAt", ranking.UpdatedAt)
 }
@@ -480,7 +524,7 @@ func pipeRemoveUserCandidate(
 	pipe redis.Pipeliner,
 	key string,
 ) {
-	pipe.HDel(ctx, key, "candidateId", "rankingId", "createdAt", "updatedAt")
+	pipe.HDel(ctx, key, userCandidateFields...)
 }
 
 func circleRankingKey(circleId int64) string {
@@ -491,26 +535,81 @@ func circleUserCandidateKey(circleId int64, identityId string) string {
 	return fmt.Sprintf("circle:%d:%s", circleId, identityId)
 }
 
+// populateRankingList of the given ranking scores in descending order
+// with the associated user candidates at the same index.
+// If a ranking is given where the list should start from, the placement
+// number and index will be continued from it.
+func populateRankingList(
+	circleId int64,
+	rankingScores []*model.RankingScore,
+	rankingUserCandidates []*model.RankingUserCandidate,
+	fromRanking *model.RankingResponse,
+) []*model.RankingResponse {
+	rankingList := make([]*model.RankingResponse, 0)
+	placementNumber := int64(0)
+	fromIndex := int64(0)
+	score := float64(0)
+
+	if fromRanking != nil {
+		placementNumber = fromRanking.Number
+		fromIndex = fromRanking.IndexedOrder + 1
+		score = fromRanking.Score
+	}
+
+	for placementIndex, rankingScore := range rankingScores {
+		rankingUserCandidate := rankingUserCandidates[placementIndex]
+
+		// a score of 0 is possible in score circles, the first
+		// ranking of the list has always the first placement
+		if (fromRanking == nil && placementIndex == 0) || score != rankingScore.Score {
+			score = rankingScore.Score
+			placementNumber++
+		}
+
+		rankingList = append(
+			rankingList,
+			populateRanking(
+				rankingUserCandidate.RankingID,
+				circleId,
+				rankingUserCandidate.CandidateID,
+				rankingScore,
+				&model.RankingTally{Total: rankingUserCandidate.Total, Ballots: rankingUserCandidate.Ballots},
+				int64(placementIndex)+fromIndex,
+				placementNumber,
+				rankingUserCandidate.CreatedAt,
+				rankingUserCandidate.UpdatedAt,
+			),
+		)
+	}
+
+	return rankingList
+}
+
 func populateRanking(
 	id int64,
 	circleId int64,
 	candidateId int64,
 	rankingScore *model.RankingScore,
+	tally *model.RankingTally,
 	placementIndex int64,
 	placementNumber int64,
 	createdAt time.Time,
 	updatedAt time.Time,
 ) *model.RankingResponse {
 	return &model.RankingResponse{
-		ID:           id,
-		CandidateID:  candidateId,
-		IdentityID:   rankingScore.UserIdentityId,
-		Number:       placementNumber,
-		Votes:        rankingScore.VoteCount,
-		IndexedOrder: placementIndex,
-		Placement:    model.PlacementNeutral,
-		CircleID:     circleId,
-		CreatedAt:    createdAt,
-		UpdatedAt:    updatedAt,
+		ID:             id,
+		CandidateID:    candidateId,
+		IdentityID:     rankingScore.UserIdentityId,
+		NoneOfTheAbove: rankingScore.UserIdentityId == model.NoneOfTheAboveCandidate,
+		Number:         placementNumber,
+		Total:          tally.Total,
+		Average:        tally.Average(),
+		Ballots:        tally.Ballots,
+		Score:          rankingScore.Score,
+		IndexedOrder:   placementIndex,
+		Placement:      model.PlacementNeutral,
+		CircleID:       circleId,
+		CreatedAt:      createdAt,
+		UpdatedAt:      updatedAt,
 	}
 }
@@ -0,0 +1,325 @@
+package cache
+
+import (
+	"context"
+	logger "github.com/VerzCar/vyf-lib-logger"
+	"github.com/VerzCar/vyf-vote-circle/api/model"
+	appConfig "github.com/VerzCar/vyf-vote-circle/app/config"
+	"github.com/VerzCar/vyf-vote-circle/utils"
+	"github.com/go-redis/redis/v8"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"testing"
+	"time"
+)
+
+var (
+	testConfig = appConfig.NewConfig(utils.FromBase("app/config/"))
+	testLog    = logger.NewLogger(utils.FromBase("app/config/"))
+)
+
+func TestMemoryCache_Ranking(t *testing.T) {
+	runRankingCacheSuite(t, NewMemoryCache(testConfig, testLog))
+}
+
+// TestRedisCache_Ranking runs the same suite against the configured redis
+// server and is skipped if the server is not reachable.
+func TestRedisCache_Ranking(t *testing.T) {
+	opt, err := redis.ParseURL(redisUrl(testConfig))
+	require.NoError(t, err)
+
+	opt.DialTimeout = time.Second
+	rdb := redis.NewClient(opt)
+	defer rdb.Close()
+
+	if err := rdb.Ping(context.Background()).Err(); err != nil {
+		t.Skipf("redis not reachable: %s", err)
+	}
+
+	runRankingCacheSuite(t, NewRedisCache(rdb, testConfig, testLog))
+}
+
+func TestMemoryCache_Expiration(t *testing.T) {
+	c := NewMemoryCache(testConfig, testLog).(*memoryCache)
+	ctx := context.Background()
+	now := time.Now()
+	c.now = func() time.Time { return now }
+
+	circleId := int64(1)
+	err := c.BuildRankingList(ctx, circleId, []*model.RankingCacheItem{rankingCacheItem(circleId, 1, "a", 1)})
+	require.NoError(t, err)
+
+	exists, _ := c.ExistsRankingListForCircle(ctx, circleId)
+	assert.True(t, exists)
+
+	now = now.Add(rankingExpiration)
+
+	exists, _ = c.ExistsRankingListForCircle(ctx, circleId)
+	assert.False(t, exists)
+
+	rankings, err := c.RankingList(ctx, circleId, nil)
+	require.NoError(t, err)
+	assert.Empty(t, rankings)
+}
+
+// runRankingCacheSuite verifies the behaviour every implementation
+// of the RedisCache must fulfill for the ranking list.
+func runRankingCacheSuite(t *testing.T, c RedisCache) {
+	ctx := context.Background()
+	// use a circle id per run, so that parallel runs against
+	// a shared redis do not interfere
+	circleId := time.Now().UnixNano()
+
+	items := []*model.RankingCacheItem{
+		rankingCacheItem(circleId, 1, "alpha", 3),
+		rankingCacheItem(circleId, 2, "bravo", 5),
+		rankingCacheItem(circleId, 3, "charlie", 3),
+	}
+
+	scoreItems := []*model.RankingCacheItem{
+		scoreRankingCacheItem(circleId, 5, "echo", 9, 2),
+		scoreRankingCacheItem(circleId, 6, "foxtrot", 12, 4),
+		scoreRankingCacheItem(circleId, 7, "golf", 0, 1),
+	}
+
+	t.Cleanup(
+		func() {
+			for _, item := range append(items, scoreItems...) {
+				_ = c.RemoveRanking(ctx, circleId, item.Candidate)
+			}
+			_ = c.RemoveRanking(ctx, circleId, &model.CircleCandidate{Candidate: "delta"})
+			_ = c.RemoveRanking(ctx, circleId, &model.CircleCandidate{Candidate: model.NoneOfTheAboveCandidate})
+		},
+	)
+
+	t.Run(
+		"should not contain a ranking list for an unknown circle", func(t *testing.T) {
+			exists, err := c.ExistsRankingListForCircle(ctx, circleId)
+			require.NoError(t, err)
+			assert.False(t, exists)
+
+			rankings, err := c.RankingList(ctx, circleId, nil)
+			require.NoError(t, err)
+			assert.Empty(t, rankings)
+		},
+	)
+
+	t.Run(
+		"should build the ranking list ordered by votes", func(t *testing.T) {
+			require.NoError(t, c.BuildRankingList(ctx, circleId, items))
+
+			exists, err := c.ExistsRankingListForCircle(ctx, circleId)
+			require.NoError(t, err)
+			assert.True(t, exists)
+
+			rankings, err := c.RankingList(ctx, circleId, nil)
+			require.NoError(t, err)
+
+			assert.Equal(t, []string{"bravo", "charlie", "alpha"}, identities(rankings))
+			assert.Equal(t, []int64{1, 2, 2}, numbers(rankings))
+			assert.Equal(t, []int64{0, 1, 2}, indexes(rankings))
+			assert.Equal(t, []int64{5, 3, 3}, totals(rankings))
+			assert.Equal(t, int64(2), rankings[0].CandidateID)
+			assert.Equal(t, int64(102), rankings[0].ID)
+			assert.Equal(t, circleId, rankings[0].CircleID)
+		},
+	)
+
+	t.Run(
+		"should upsert a ranking and return its placement", func(t *testing.T) {
+			item := rankingCacheItem(circleId, 1, "alpha", 6)
+
+			ranking, err := c.UpsertRanking(ctx, circleId, item.Candidate, item.Ranking)
+			require.NoError(t, err)
+
+			assert.Equal(t, "alpha", ranking.IdentityID)
+			assert.Equal(t, int64(6), ranking.Total)
+			assert.Equal(t, int64(6), ranking.Ballots)
+			assert.Equal(t, int64(0), ranking.IndexedOrder)
+			assert.Equal(t, int64(1), ranking.Number)
+			assert.Equal(t, item.Ranking.CreatedAt, ranking.CreatedAt)
+
+			item = rankingCacheItem(circleId, 4, "delta", 5)
+
+			ranking, err = c.UpsertRanking(ctx, circleId, item.Candidate, item.Ranking)
+			require.NoError(t, err)
+
+			// equal scores are ordered reverse lexicographically
+			assert.Equal(t, int64(1), ranking.IndexedOrder)
+			assert.Equal(t, int64(2), ranking.Number)
+		},
+	)
+
+	t.Run(
+		"should continue the ranking list from a given ranking", func(t *testing.T) {
+			rankings, err := c.RankingList(ctx, circleId, nil)
+			require.NoError(t, err)
+			assert.Equal(t, []string{"alpha", "delta", "bravo", "charlie"}, identities(rankings))
+
+			following, err := c.RankingList(ctx, circleId, rankings[1])
+			require.NoError(t, err)
+
+			assert.Equal(t, []string{"bravo", "charlie"}, identities(following))
+			assert.Equal(t, []int64{2, 3}, indexes(following))
+			assert.Equal(t, []int64{2, 3}, numbers(following))
+		},
+	)
+
+	t.Run(
+		"should remove a ranking", func(t *testing.T) {
+			require.NoError(t, c.RemoveRanking(ctx, circleId, &model.CircleCandidate{Candidate: "delta"}))
+
+			rankings, err := c.RankingList(ctx, circleId, nil)
+			require.NoError(t, err)
+			assert.Equal(t, []string{"alpha", "bravo", "charlie"}, identities(rankings))
+		},
+	)
+
+	t.Run(
+		"should remove the whole ranking list", func(t *testing.T) {
+			require.NoError(t, c.BuildRankingList(ctx, circleId, items))
+			require.NoError(t, c.RemoveRankingList(ctx, circleId))
+
+			exists, err := c.ExistsRankingListForCircle(ctx, circleId)
+			require.NoError(t, err)
+			assert.False(t, exists)
+
+			require.NoError(t, c.BuildRankingList(ctx, circleId, items[:1]))
+
+			rankings, err := c.RankingList(ctx, circleId, nil)
+			require.NoError(t, err)
+			assert.Equal(t, []string{"alpha"}, identities(rankings))
+			assert.Equal(t, int64(1), rankings[0].CandidateID)
+		},
+	)
+
+	t.Run(
+		"should not contain a ranking list if all rankings are removed", func(t *testing.T) {
+			for _, item := range items {
+				require.NoError(t, c.RemoveRanking(ctx, circleId, item.Candidate))
+			}
+
+			exists, err := c.ExistsRankingListForCircle(ctx, circleId)
+			require.NoError(t, err)
+			assert.False(t, exists)
+		},
+	)
+
+	t.Run(
+		"should order the ranking list by the average score", func(t *testing.T) {
+			require.NoError(t, c.BuildRankingList(ctx, circleId, scoreItems[2:]))
+
+			rankings, err := c.RankingList(ctx, circleId, nil)
+			require.NoError(t, err)
+			assert.Equal(t, []int64{1}, numbers(rankings))
+
+			require.NoError(t, c.BuildRankingList(ctx, circleId, scoreItems[:2]))
+
+			rankings, err = c.RankingList(ctx, circleId, nil)
+			require.NoError(t, err)
+
+			assert.Equal(t, []string{"echo", "foxtrot", "golf"}, identities(rankings))
+			assert.Equal(t, []int64{1, 2, 3}, numbers(rankings))
+			assert.Equal(t, []int64{9, 12, 0}, totals(rankings))
+			assert.Equal(t, int64(4), rankings[1].Ballots)
+			assert.Equal(t, 4.5, rankings[0].Average)
+
+			item := scoreRankingCacheItem(circleId, 7, "golf", 10, 2)
+
+			ranking, err := c.UpsertRanking(ctx, circleId, item.Candidate, item.Ranking)
+			require.NoError(t, err)
+
+			assert.Equal(t, int64(1), ranking.Number)
+			assert.Equal(t, int64(0), ranking.IndexedOrder)
+			assert.Equal(t, float64(5), ranking.Average)
+		},
+	)
+
+	t.Run(
+		"should mark the ranking of none of the above", func(t *testing.T) {
+			item := rankingCacheItem(circleId, 8, model.NoneOfTheAboveCandidate, 12)
+
+			ranking, err := c.UpsertRanking(ctx, circleId, item.Candidate, item.Ranking)
+			require.NoError(t, err)
+
+			assert.True(t, ranking.NoneOfTheAbove)
+			assert.Equal(t, int64(1), ranking.Number)
+
+			rankings, err := c.RankingList(ctx, circleId, nil)
+			require.NoError(t, err)
+
+			assert.True(t, rankings[0].NoneOfTheAbove)
+			assert.False(t, rankings[1].NoneOfTheAbove)
+		},
+	)
+}
+
+func rankingCacheItem(circleId int64, id int64, identityId string, voteCount int64) *model.RankingCacheItem {
+	createdAt := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	return &model.RankingCacheItem{
+		Candidate: &model.CircleCandidate{
+			ID:         id,
+			Candidate:  identityId,
+			CircleID:   circleId,
+			Commitment: model.CommitmentCommitted,
+		},
+		Ranking: &model.Ranking{
+			ID:         id + 100,
+			IdentityID: identityId,
+			CircleID:   circleId,
+			Total:      voteCount,
+			Ballots:    voteCount,
+			Average:    1,
+			Score:      float64(voteCount),
+			CreatedAt:  createdAt,
+			UpdatedAt:  createdAt,
+		},
+	}
+}
+
+// scoreRankingCacheItem of a score circle, that is ordered by the average score
+func scoreRankingCacheItem(
+	circleId int64,
+	id int64,
+	identityId string,
+	total int64,
+	ballots int64,
+) *model.RankingCacheItem {
+	item := rankingCacheItem(circleId, id, identityId, 0)
+	item.Ranking.SetTally(&model.RankingTally{Total: total, Ballots: ballots}, model.RankingOrderAverage)
+
+	return item
+}
+
+func identities(rankings []*model.RankingResponse) []string {
+	var ids []string
+	for _, ranking := range rankings {
+		ids = append(ids, ranking.IdentityID)
+	}
+	return ids
+}
+
+func numbers(rankings []*model.RankingResponse) []int64 {
+	var n []int64
+	for _, ranking := range rankings {
+		n = append(n, ranking.Number)
+	}
+	return n
+}
+
+func indexes(rankings []*model.RankingResponse) []int64 {
+	var n []int64
+	for _, ranking := range rankings {
+		n = append(n, ranking.IndexedOrder)
+	}
+	return n
+}
+
+func totals(rankings []*model.RankingResponse) []int64 {
+	var n []int64
+	for _, ranking := range rankings {
+		n = append(n, ranking.Total)
+	}
+	return n
+}
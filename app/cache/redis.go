@@ -16,7 +16,6 @@ type UpsertRankingCacheCallback func(
 	int64,
 	*model.CircleCandidate,
 	*model.Ranking,
-	int64,
 ) (*model.RankingResponse, error)
 
 type RemoveRankingCacheCallback func(
@@ -31,7 +30,6 @@ type RedisCache interface {
 		circleId int64,
 		candidate *model.CircleCandidate,
 		ranking *model.Ranking,
-		votes int64,
 	) (*model.RankingResponse, error)
 	RemoveRanking(
 		ctx context.Context,
@@ -52,6 +50,67 @@ type RedisCache interface {
 		circleId int64,
 		rankingCacheItems []*model.RankingCacheItem,
 	) error
+	RemoveRankingList(
+		ctx context.Context,
+		circleId int64,
+	) error
+	Circle(
+		ctx context.Context,
+		circleId int64,
+	) (*model.Circle, bool, error)
+	SetCircle(
+		ctx context.Context,
+		circle *model.Circle,
+	) error
+	RemoveCircle(
+		ctx context.Context,
+		circleId int64,
+	) error
+	CircleMembership(
+		ctx context.Context,
+		circleId int64,
+		member CircleMember,
+		userIdentityId string,
+	) (bool, bool, error)
+	SetCircleMembership(
+		ctx context.Context,
+		circleId int64,
+		member CircleMember,
+		userIdentityId string,
+		isMember bool,
+		onlyIfAbsent bool,
+	) error
+	ReserveEventSequences(
+		ctx context.Context,
+		circleId int64,
+		count int64,
+	) (int64, error)
+	AppendEventLog(
+		ctx context.Context,
+		circleId int64,
+		entries []*model.EventLogEntry,
+	) error
+	EventLog(
+		ctx context.Context,
+		circleId int64,
+		since int64,
+	) ([]*model.EventLogEntry, int64, error)
+	TouchCirclePresence(
+		ctx context.Context,
+		circleId int64,
+		userIdentityId string,
+		expiresAt time.Time,
+	) (int64, bool, error)
+	RemoveCirclePresence(
+		ctx context.Context,
+		circleId int64,
+		userIdentityId string,
+	) (int64, bool, error)
+	CirclePresence(
+		ctx context.Context,
+		circleId int64,
+	) ([]string, error)
+	Ping(ctx context.Context) error
 }
 
 type redisCache struct {
@@ -143,6 +202,11 @@ func (c *redisCache) set(ctx context.Context, key string, value interface{}, t t
 	return err
 }
 
+// Ping the cache to check whether it is reachable
+func (c *redisCache) Ping(ctx context.Context) error {
+	return c.redis.Ping(ctx).Err()
+}
+
 // FlushAll the cache and flush the db
 func (c *redisCache) FlushAll() error {
 	ctx := context.Background()
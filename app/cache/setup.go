@@ -10,6 +10,19 @@ import (
 	"github.com/go-redis/redis/v8"
 )
 
+// NewCache creates the cache of the configured cache provider.
+// If the provider is memory, no connection to redis will be established
+// and the entries are kept in process.
+func NewCache(log logger.Logger, conf *config.Config) RedisCache {
+	switch conf.Cache.Provider {
+	case config.CacheProviderMemory:
+		log.Infof("Use in memory cache.")
+		return NewMemoryCache(conf, log)
+	default:
+		return NewRedisCache(Connect(log, conf), conf, log)
+	}
+}
+
 // Connect the cache database
 func Connect(log logger.Logger, conf *config.Config) Client {
 	log.Infof("Connect to redis via: %s", redisUrl(conf))
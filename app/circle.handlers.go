@@ -33,18 +33,31 @@ func (s *Server) Circle() gin.HandlerFunc {
 		}
 
 		circleResponse := &model.CircleResponse{
-			ID:          circle.ID,
-			Name:        circle.Name,
-			Description: circle.Description,
-			ImageSrc:    circle.ImageSrc,
-			Private:     circle.Private,
-			Active:      circle.Active,
-			Stage:       circle.Stage,
-			CreatedFrom: circle.CreatedFrom,
-			ValidFrom:   circle.ValidFrom,
-			ValidUntil:  circle.ValidUntil,
-			CreatedAt:   circle.CreatedAt,
-			UpdatedAt:   circle.UpdatedAt,
+			ID:                  circle.ID,
+			Name:                circle.Name,
+			Description:         circle.Description,
+			ImageSrc:            circle.ImageSrc,
+			Private:             circle.Private,
+			Active:              circle.Active,
+			Stage:               circle.Stage,
+			VotingMode:          circle.VotingMode,
+			RankingOrder:        circle.RankingOrder,
+			NoneOfTheAbove:      circle.NoneOfTheAbove,
+			Delegation:          circle.Delegation,
+			QuorumTurnout:       circle.QuorumTurnout,
+			QuorumWinnerVotes:   circle.QuorumWinnerVotes,
+			Result:              circle.Result,
+			FirstRoundID:        circle.FirstRoundID,
+			Round:               circle.Round,
+			Nomination:          circle.Nomination,
+			NominationsPerVoter: circle.NominationsPerVoter,
+			NominationSeconds:   circle.NominationSeconds,
+			CommitmentDeadline:  circle.CommitmentDeadline,
+			CreatedFrom:         circle.CreatedFrom,
+			ValidFrom:           circle.ValidFrom,
+			ValidUntil:          circle.ValidUntil,
+			CreatedAt:           circle.CreatedAt,
+			UpdatedAt:           circle.UpdatedAt,
 		}
 
 		response := model.Response{
@@ -53,6 +66,46 @@ func (s *Server) Circle() gin.HandlerFunc {
 			Data:   circleResponse,
 		}
 
+		if circle.Stage == model.CircleStageHot {
+			response.Meta = s.quorumMeta(ctx, circle.ID)
+		}
+
+		ctx.JSON(http.StatusOK, response)
+	}
+}
+
+func (s *Server) CircleRounds() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		errResponse := model.Response{
+			Status: model.ResponseError,
+			Msg:    "cannot find circle rounds",
+			Data:   nil,
+		}
+
+		circleReq := &model.CircleUriRequest{}
+
+		err := ctx.ShouldBindUri(circleReq)
+
+		if err != nil {
+			s.log.Error(err)
+			ctx.JSON(http.StatusBadRequest, errResponse)
+			return
+		}
+
+		rounds, err := s.circleService.CircleRounds(ctx.Request.Context(), circleReq.CircleID)
+
+		if err != nil {
+			s.log.Errorf("service error: %v", err)
+			ctx.JSON(http.StatusInternalServerError, errResponse)
+			return
+		}
+
+		response := model.Response{
+			Status: model.ResponseSuccess,
+			Msg:    "",
+			Data:   rounds,
+		}
+
 		ctx.JSON(http.StatusOK, response)
 	}
 }
@@ -128,18 +181,31 @@ func (s *Server) Circles() gin.HandlerFunc {
 
 		for _, circle := range circles {
 			circleResponse := &model.CircleResponse{
-				ID:          circle.ID,
-				Name:        circle.Name,
-				Description: circle.Description,
-				ImageSrc:    circle.ImageSrc,
-				Private:     circle.Private,
-				Active:      circle.Active,
-				Stage:       circle.Stage,
-				CreatedFrom: circle.CreatedFrom,
-				ValidFrom:   circle.ValidFrom,
-				ValidUntil:  circle.ValidUntil,
-				CreatedAt:   circle.CreatedAt,
-				UpdatedAt:   circle.UpdatedAt,
+				ID:                  circle.ID,
+				Name:                circle.Name,
+				Description:         circle.Description,
+				ImageSrc:            circle.ImageSrc,
+				Private:             circle.Private,
+				Active:              circle.Active,
+				Stage:               circle.Stage,
+				VotingMode:          circle.VotingMode,
+				RankingOrder:        circle.RankingOrder,
+				NoneOfTheAbove:      circle.NoneOfTheAbove,
+				Delegation:          circle.Delegation,
+				QuorumTurnout:       circle.QuorumTurnout,
+				QuorumWinnerVotes:   circle.QuorumWinnerVotes,
+				Result:              circle.Result,
+				FirstRoundID:        circle.FirstRoundID,
+				Round:               circle.Round,
+				Nomination:          circle.Nomination,
+				NominationsPerVoter: circle.NominationsPerVoter,
+				NominationSeconds:   circle.NominationSeconds,
+				CommitmentDeadline:  circle.CommitmentDeadline,
+				CreatedFrom:         circle.CreatedFrom,
+				ValidFrom:           circle.ValidFrom,
+				ValidUntil:          circle.ValidUntil,
+				CreatedAt:           circle.CreatedAt,
+				UpdatedAt:           circle.UpdatedAt,
 			}
 
 			circlesResponse = append(circlesResponse, circleResponse)
@@ -328,18 +394,31 @@ func (s *Server) CreateCircle() gin.HandlerFunc {
 		}
 
 		circleResponse := &model.CircleResponse{
-			ID:          circle.ID,
-			Name:        circle.Name,
-			Description: circle.Description,
-			ImageSrc:    circle.ImageSrc,
-			Private:     circle.Private,
-			Active:      circle.Active,
-			Stage:       circle.Stage,
-			CreatedFrom: circle.CreatedFrom,
-			ValidFrom:   circle.ValidFrom,
-			ValidUntil:  circle.ValidUntil,
-			CreatedAt:   circle.CreatedAt,
-			UpdatedAt:   circle.UpdatedAt,
+			ID:                  circle.ID,
+			Name:                circle.Name,
+			Description:         circle.Description,
+			ImageSrc:            circle.ImageSrc,
+			Private:             circle.Private,
+			Active:              circle.Active,
+			Stage:               circle.Stage,
+			VotingMode:          circle.VotingMode,
+			RankingOrder:        circle.RankingOrder,
+			NoneOfTheAbove:      circle.NoneOfTheAbove,
+			Delegation:          circle.Delegation,
+			QuorumTurnout:       circle.QuorumTurnout,
+			QuorumWinnerVotes:   circle.QuorumWinnerVotes,
+			Result:              circle.Result,
+			FirstRoundID:        circle.FirstRoundID,
+			Round:               circle.Round,
+			Nomination:          circle.Nomination,
+			NominationsPerVoter: circle.NominationsPerVoter,
+			NominationSeconds:   circle.NominationSeconds,
+			CommitmentDeadline:  circle.CommitmentDeadline,
+			CreatedFrom:         circle.CreatedFrom,
+			ValidFrom:           circle.ValidFrom,
+			ValidUntil:          circle.ValidUntil,
+			CreatedAt:           circle.CreatedAt,
+			UpdatedAt:           circle.UpdatedAt,
 		}
 
 		response := model.Response{
@@ -395,18 +474,31 @@ func (s *Server) UpdateCircle() gin.HandlerFunc {
 		}
 
 		circleResponse := &model.CircleResponse{
-			ID:          circle.ID,
-			Name:        circle.Name,
-			Description: circle.Description,
-			ImageSrc:    circle.ImageSrc,
-			Private:     circle.Private,
-			Active:      circle.Active,
-			Stage:       circle.Stage,
-			CreatedFrom: circle.CreatedFrom,
-			ValidFrom:   circle.ValidFrom,
-			ValidUntil:  circle.ValidUntil,
-			CreatedAt:   circle.CreatedAt,
-			UpdatedAt:   circle.UpdatedAt,
+			ID:                  circle.ID,
+			Name:                circle.Name,
+			Description:         circle.Description,
+			ImageSrc:            circle.ImageSrc,
+			Private:             circle.Private,
+			Active:              circle.Active,
+			Stage:               circle.Stage,
+			VotingMode:          circle.VotingMode,
+			RankingOrder:        circle.RankingOrder,
+			NoneOfTheAbove:      circle.NoneOfTheAbove,
+			Delegation:          circle.Delegation,
+			QuorumTurnout:       circle.QuorumTurnout,
+			QuorumWinnerVotes:   circle.QuorumWinnerVotes,
+			Result:              circle.Result,
+			FirstRoundID:        circle.FirstRoundID,
+			Round:               circle.Round,
+			Nomination:          circle.Nomination,
+			NominationsPerVoter: circle.NominationsPerVoter,
+			NominationSeconds:   circle.NominationSeconds,
+			CommitmentDeadline:  circle.CommitmentDeadline,
+			CreatedFrom:         circle.CreatedFrom,
+			ValidFrom:           circle.ValidFrom,
+			ValidUntil:          circle.ValidUntil,
+			CreatedAt:           circle.CreatedAt,
+			UpdatedAt:           circle.UpdatedAt,
 		}
 
 		response := model.Response{
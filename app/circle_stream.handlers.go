@@ -0,0 +1,89 @@
+package app
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"github.com/VerzCar/vyf-vote-circle/api/model"
+	"github.com/gin-gonic/gin"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+func (s *Server) CircleStream() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		errResponse := model.Response{
+			Status: model.ResponseError,
+			Msg:    "cannot stream circle",
+			Data:   nil,
+		}
+
+		circleReq := &model.CircleUriRequest{}
+
+		err := ctx.ShouldBindUri(circleReq)
+
+		if err != nil {
+			s.log.Error(err)
+			ctx.JSON(http.StatusBadRequest, errResponse)
+			return
+		}
+
+		// resume after the last received event, if the client reconnects
+		lastEventId, _ := strconv.ParseInt(ctx.GetHeader("Last-Event-ID"), 10, 64)
+
+		events, err := s.circleStreamService.Subscribe(
+			ctx.Request.Context(),
+			circleReq.CircleID,
+			lastEventId,
+		)
+
+		if err != nil {
+			s.log.Errorf("service error: %v", err)
+			ctx.JSON(http.StatusInternalServerError, errResponse)
+			return
+		}
+
+		heartbeat := time.NewTicker(s.circleStreamService.HeartbeatInterval())
+		defer heartbeat.Stop()
+
+		ctx.Status(http.StatusOK)
+		ctx.Stream(
+			func(w io.Writer) bool {
+				select {
+				case event, ok := <-events:
+					if !ok {
+						return false
+					}
+					return writeServerSentEvent(w, event) == nil
+				case <-heartbeat.C:
+					_, err := io.WriteString(w, ": heartbeat\n\n")
+					return err == nil
+				case <-ctx.Request.Context().Done():
+					return false
+				}
+			},
+		)
+	}
+}
+
+// writeServerSentEvent in the text/event-stream format.
+// The sequence of the event is sent as id, if the event has a sequence.
+func writeServerSentEvent(w io.Writer, event *model.CircleStreamEvent) error {
+	data := &bytes.Buffer{}
+
+	if err := json.Compact(data, event.Data); err != nil {
+		return err
+	}
+
+	if event.Sequence > 0 {
+		if _, err := fmt.Fprintf(w, "id: %d\n", event.Sequence); err != nil {
+			return err
+		}
+	}
+
+	_, err := fmt.Fprintf(w, "event: %s\ndata: %s\n\n", event.Name, data.String())
+
+	return err
+}
@@ -64,6 +64,7 @@ func (s *Server) CircleVoters() gin.HandlerFunc {
 				Voter:      voter.Voter,
 				Commitment: voter.Commitment,
 				VotedFor:   voter.VotedFor,
+				Abstained:  voter.Abstained,
 				CreatedAt:  voter.CreatedAt,
 				UpdatedAt:  voter.UpdatedAt,
 			}
@@ -78,6 +79,7 @@ func (s *Server) CircleVoters() gin.HandlerFunc {
 				Voter:      userVoter.Voter,
 				Commitment: userVoter.Commitment,
 				VotedFor:   userVoter.VotedFor,
+				Abstained:  userVoter.Abstained,
 				CreatedAt:  userVoter.CreatedAt,
 				UpdatedAt:  userVoter.UpdatedAt,
 			}
@@ -132,6 +134,7 @@ func (s *Server) CircleVoterJoinCircle() gin.HandlerFunc {
 			Voter:      voter.Voter,
 			Commitment: voter.Commitment,
 			VotedFor:   voter.VotedFor,
+			Abstained:  voter.Abstained,
 			CreatedAt:  voter.CreatedAt,
 			UpdatedAt:  voter.UpdatedAt,
 		}
@@ -233,6 +236,7 @@ func (s *Server) CircleVotersAddToCircle() gin.HandlerFunc {
 				Voter:      voter.Voter,
 				Commitment: voter.Commitment,
 				VotedFor:   voter.VotedFor,
+				Abstained:  voter.Abstained,
 				CreatedAt:  voter.CreatedAt,
 				UpdatedAt:  voter.UpdatedAt,
 			}
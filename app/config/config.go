@@ -18,12 +18,19 @@ type Config struct {
 		MaxAmountPerUser int64
 		MaxVoters        int
 		MaxCandidates    int
+		StageInterval    int
 		Private          struct {
 			MaxVoters     int
 			MaxCandidates int
 		}
 	}
 
+	Plans struct {
+		S Plan
+		M Plan
+		L Plan
+	}
+
 	Aws struct {
 		Auth struct {
 			ClientId         string
@@ -73,9 +80,65 @@ type Config struct {
 		Password string
 	}
 
+	Cache struct {
+		Provider         string
+		RecoveryInterval int
+		EventLogSize     int64
+	}
+
+	Outbox struct {
+		Interval    int
+		BatchSize   int
+		MaxAttempts int
+		Backoff     int
+	}
+
+	PubSub struct {
+		Provider         string
+		CoalescingWindow int
+	}
+
+	Presence struct {
+		TTL int
+	}
+
+	Webhook struct {
+		Interval     int
+		BatchSize    int
+		MaxAttempts  int
+		Backoff      int
+		Timeout      int
+		MaxPerCircle int
+	}
+
+	Notification struct {
+		Interval               int
+		BatchSize              int
+		MaxAttempts            int
+		Backoff                int
+		Timeout                int
+		ClosingSoon            int
+		ClosingSoonInterval    int
+		CommitmentDeadlineSoon int
+		Smtp                   struct {
+			Host     string
+			Port     int
+			Username string
+			Password string
+			From     string
+		}
+	}
+
+	Stream struct {
+		HeartbeatInterval int
+		BufferSize        int
+		MaxSubscriptions  int
+	}
+
 	Ably struct {
 		Apikey   string
 		ClientId string
+		TokenTTL int
 	}
 
 	Security struct {
@@ -85,9 +148,24 @@ type Config struct {
 		Secrets struct {
 			Key string
 		}
+		Admin struct {
+			Scope string
+		}
 	}
 }
 
+// Plan of the quotas of a subscription package.
+// Quotas, that are not set, fall back to the circle and webhook settings.
+type Plan struct {
+	MaxCircles           int
+	MaxVoters            int
+	MaxCandidates        int
+	MaxPrivateVoters     int
+	MaxPrivateCandidates int
+	MaxImageUploads      int
+	MaxWebhooks          int
+}
+
 const (
 	EnvironmentDev   = "development"
 	EnvironmentProd  = "production"
@@ -96,6 +174,16 @@ const (
 	overrideFileName = "config.service.override"
 )
 
+const (
+	CacheProviderRedis  = "redis"
+	CacheProviderMemory = "memory"
+)
+
+const (
+	PubSubProviderAbly  = "ably"
+	PubSubProviderRedis = "redis"
+)
+
 func NewConfig(configPath string) *Config {
 	c := &Config{}
 	c.load(configPath)
@@ -189,8 +277,18 @@ func (c *Config) checkEnvironment() {
 		c.Redis.Db = uint16(redisDb)
 		c.Redis.Password = redisPasswort
 
+		if cacheProvider := os.Getenv("CACHE_PROVIDER"); cacheProvider != "" {
+			c.Cache.Provider = cacheProvider
+		}
+
 		c.Ably.Apikey = os.Getenv("ABLY_API_KEY")
 
+		if smtpHost := os.Getenv("SMTP_HOST"); smtpHost != "" {
+			c.Notification.Smtp.Host = smtpHost
+			c.Notification.Smtp.Username = os.Getenv("SMTP_USERNAME")
+			c.Notification.Smtp.Password = os.Getenv("SMTP_PASSWORD")
+		}
+
 		c.Port = os.Getenv("PORT")
 
 		c.Security.Cors.Origins = strings.Split(os.Getenv("SECURITY_CORS_ORIGINS"), ",")
@@ -6,7 +6,10 @@ import (
 	routerContext "github.com/VerzCar/vyf-vote-circle/app/router/ctx"
 	"github.com/VerzCar/vyf-vote-circle/app/router/header"
 	"github.com/gin-gonic/gin"
+	"github.com/lestrrat-go/jwx/v2/jwt"
 	"net/http"
+	"slices"
+	"strings"
 )
 
 // authGuard verifies the Authorization token against the SSO service.
@@ -24,19 +27,88 @@ func (s *Server) authGuard(authService awsx.AuthService) gin.HandlerFunc {
 			return
 		}
 
-		token, err := authService.DecodeAccessToken(ctx, accessToken)
+		s.authenticate(ctx, authService, accessToken)
+	}
+}
+
+// adminGuard verifies, that the access token authenticated by the authGuard
+// has the configured admin scope. If the scope is missing or no admin scope
+// is configured, the request will be aborted.
+func (s *Server) adminGuard() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		authClaims, err := routerContext.ContextToAuthClaims(ctx.Request.Context())
 
 		if err != nil {
-			ctx.String(http.StatusUnauthorized, fmt.Sprintf("error decoding token"))
+			ctx.String(http.StatusUnauthorized, fmt.Sprintf("error: %s", err))
 			ctx.AbortWithStatus(http.StatusUnauthorized)
 			return
 		}
 
-		routerContext.SetAuthClaimsContext(ctx, token)
+		adminScope := s.config.Security.Admin.Scope
+
+		if adminScope == "" || !slices.Contains(strings.Fields(authClaims.PrivateClaims.Scope), adminScope) {
+			s.log.Infof("user %s without admin scope tried to access admin api", authClaims.Subject)
+			ctx.String(http.StatusForbidden, "error: missing admin scope")
+			ctx.AbortWithStatus(http.StatusForbidden)
+			return
+		}
+
 		ctx.Next()
 	}
 }
 
+// webSocketAuthGuard verifies the access token of a WebSocket handshake the same way
+// as the authGuard. As browsers cannot set the Authorization header for a WebSocket,
+// the access token can be given as the protocol following the bearer protocol in the
+// Sec-WebSocket-Protocol header instead. The expiry of the token is saved in the context,
+// so that the connection can be closed as soon as the token expires.
+func (s *Server) webSocketAuthGuard(authService awsx.AuthService) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		accessToken, err := header.Authorization(ctx, "Bearer")
+
+		if err != nil {
+			accessToken, err = header.WebSocketProtocolToken(ctx, webSocketBearerProtocol)
+		}
+
+		if err != nil {
+			ctx.String(http.StatusUnauthorized, fmt.Sprintf("error: %s", err))
+			ctx.AbortWithStatus(http.StatusUnauthorized)
+			return
+		}
+
+		token, err := jwt.ParseString(accessToken, jwt.WithVerify(false), jwt.WithValidate(false))
+
+		if err != nil || token.Expiration().IsZero() {
+			ctx.String(http.StatusUnauthorized, fmt.Sprintf("error decoding token"))
+			ctx.AbortWithStatus(http.StatusUnauthorized)
+			return
+		}
+
+		ctx.Set(webSocketTokenExpiryKey, token.Expiration())
+
+		s.authenticate(ctx, authService, accessToken)
+	}
+}
+
+// authenticate the access token against the SSO service and save
+// the subject of the token in the context before serving the next request.
+func (s *Server) authenticate(
+	ctx *gin.Context,
+	authService awsx.AuthService,
+	accessToken string,
+) {
+	token, err := authService.DecodeAccessToken(ctx, accessToken)
+
+	if err != nil {
+		ctx.String(http.StatusUnauthorized, fmt.Sprintf("error decoding token"))
+		ctx.AbortWithStatus(http.StatusUnauthorized)
+		return
+	}
+
+	routerContext.SetAuthClaimsContext(ctx, token)
+	ctx.Next()
+}
+
 func (s *Server) serverSentHeaders() gin.HandlerFunc {
 	return func(ctx *gin.Context) {
 		ctx.Writer.Header().Add("Content-Type", "text/event-stream")
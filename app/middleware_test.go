@@ -0,0 +1,89 @@
+package app
+
+import (
+	awsx "github.com/VerzCar/vyf-lib-awsx"
+	logger "github.com/VerzCar/vyf-lib-logger"
+	"github.com/VerzCar/vyf-vote-circle/app/config"
+	routerContext "github.com/VerzCar/vyf-vote-circle/app/router/ctx"
+	"github.com/VerzCar/vyf-vote-circle/utils"
+	"github.com/gin-gonic/gin"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+var testLog = logger.NewLogger(utils.FromBase("app/config/"))
+
+func TestServer_adminGuard(t *testing.T) {
+	tests := []struct {
+		name       string
+		adminScope string
+		claims     *awsx.JWTToken
+		want       int
+	}{
+		{
+			name:       "should pass with the admin scope",
+			adminScope: "vyf/admin",
+			claims:     tokenWithScope("openid vyf/admin"),
+			want:       http.StatusOK,
+		},
+		{
+			name:       "should fail without the admin scope",
+			adminScope: "vyf/admin",
+			claims:     tokenWithScope("openid vyf/user"),
+			want:       http.StatusForbidden,
+		},
+		{
+			name:       "should fail with a scope, that only contains the admin scope",
+			adminScope: "vyf/admin",
+			claims:     tokenWithScope("vyf/administrator"),
+			want:       http.StatusForbidden,
+		},
+		{
+			name:   "should fail without a configured admin scope",
+			claims: tokenWithScope("openid vyf/admin"),
+			want:   http.StatusForbidden,
+		},
+		{
+			name:       "should fail without auth claims",
+			adminScope: "vyf/admin",
+			want:       http.StatusUnauthorized,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(
+			test.name, func(t *testing.T) {
+				conf := &config.Config{}
+				conf.Security.Admin.Scope = test.adminScope
+				s := &Server{config: conf, log: testLog}
+
+				router := gin.New()
+				router.GET(
+					"/admin", func(ctx *gin.Context) {
+						if test.claims != nil {
+							routerContext.SetAuthClaimsContext(ctx, test.claims)
+						}
+						ctx.Next()
+					}, s.adminGuard(), func(ctx *gin.Context) {
+						ctx.Status(http.StatusOK)
+					},
+				)
+
+				w := httptest.NewRecorder()
+				req, _ := http.NewRequest(http.MethodGet, "/admin", nil)
+				router.ServeHTTP(w, req)
+
+				if w.Code != test.want {
+					t.Errorf("test: %v failed. \ngot: %v \nwanted: %v", test.name, w.Code, test.want)
+				}
+			},
+		)
+	}
+}
+
+func tokenWithScope(scope string) *awsx.JWTToken {
+	token := &awsx.JWTToken{Subject: "subject"}
+	token.PrivateClaims.Scope = scope
+	return token
+}
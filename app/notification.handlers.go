@@ -0,0 +1,254 @@
+package app
+
+import (
+	"errors"
+	"github.com/VerzCar/vyf-vote-circle/api"
+	"github.com/VerzCar/vyf-vote-circle/api/model"
+	"github.com/gin-gonic/gin"
+	"net/http"
+)
+
+func (s *Server) Notifications() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		errResponse := model.Response{
+			Status: model.ResponseError,
+			Msg:    "cannot find notifications",
+			Data:   nil,
+		}
+
+		notificationsReq := &model.NotificationsRequest{}
+
+		err := ctx.ShouldBindQuery(notificationsReq)
+
+		if err != nil {
+			s.log.Error(err)
+			ctx.JSON(http.StatusBadRequest, errResponse)
+			return
+		}
+
+		notifications, err := s.notificationService.Notifications(ctx.Request.Context(), notificationsReq.Unread)
+
+		if err != nil {
+			s.log.Errorf("service error: %v", err)
+			ctx.JSON(http.StatusInternalServerError, errResponse)
+			return
+		}
+
+		response := model.Response{
+			Status: model.ResponseSuccess,
+			Msg:    "",
+			Data:   notifications,
+		}
+
+		ctx.JSON(http.StatusOK, response)
+	}
+}
+
+func (s *Server) MarkNotificationRead() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		errResponse := model.Response{
+			Status: model.ResponseError,
+			Msg:    "notification cannot be marked as read",
+			Data:   nil,
+		}
+
+		notificationReq := &model.NotificationUriRequest{}
+
+		err := ctx.ShouldBindUri(notificationReq)
+
+		if err != nil {
+			s.log.Error(err)
+			ctx.JSON(http.StatusBadRequest, errResponse)
+			return
+		}
+
+		if err := s.validate.Struct(notificationReq); err != nil {
+			s.log.Warn(err)
+			ctx.JSON(http.StatusBadRequest, errResponse)
+			return
+		}
+
+		err = s.notificationService.MarkNotificationRead(ctx.Request.Context(), notificationReq.NotificationID)
+
+		if err != nil {
+			s.log.Errorf("service error: %v", err)
+			ctx.JSON(http.StatusInternalServerError, errResponse)
+			return
+		}
+
+		response := model.Response{
+			Status: model.ResponseSuccess,
+			Msg:    "",
+			Data:   true,
+		}
+
+		ctx.JSON(http.StatusOK, response)
+	}
+}
+
+func (s *Server) MarkAllNotificationsRead() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		errResponse := model.Response{
+			Status: model.ResponseError,
+			Msg:    "notifications cannot be marked as read",
+			Data:   nil,
+		}
+
+		err := s.notificationService.MarkAllNotificationsRead(ctx.Request.Context())
+
+		if err != nil {
+			s.log.Errorf("service error: %v", err)
+			ctx.JSON(http.StatusInternalServerError, errResponse)
+			return
+		}
+
+		response := model.Response{
+			Status: model.ResponseSuccess,
+			Msg:    "",
+			Data:   true,
+		}
+
+		ctx.JSON(http.StatusOK, response)
+	}
+}
+
+func (s *Server) NotificationPreference() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		errResponse := model.Response{
+			Status: model.ResponseError,
+			Msg:    "cannot find notification preference",
+			Data:   nil,
+		}
+
+		preference, err := s.notificationService.NotificationPreference(ctx.Request.Context())
+
+		if err != nil {
+			s.log.Errorf("service error: %v", err)
+			ctx.JSON(http.StatusInternalServerError, errResponse)
+			return
+		}
+
+		response := model.Response{
+			Status: model.ResponseSuccess,
+			Msg:    "",
+			Data:   toNotificationPreferenceResponse(preference),
+		}
+
+		ctx.JSON(http.StatusOK, response)
+	}
+}
+
+func (s *Server) UpdateNotificationPreference() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		errResponse := model.Response{
+			Status: model.ResponseError,
+			Msg:    "notification preference cannot be updated",
+			Data:   nil,
+		}
+
+		preferenceUpdateReq := &model.NotificationPreferenceUpdateRequest{}
+
+		err := ctx.ShouldBindJSON(preferenceUpdateReq)
+
+		if err != nil {
+			s.log.Error(err)
+			ctx.JSON(http.StatusBadRequest, errResponse)
+			return
+		}
+
+		if err := s.validate.Struct(preferenceUpdateReq); err != nil {
+			s.log.Warn(err)
+			ctx.JSON(http.StatusBadRequest, errResponse)
+			return
+		}
+
+		preference, err := s.notificationService.UpdateNotificationPreference(
+			ctx.Request.Context(),
+			preferenceUpdateReq,
+		)
+
+		if err != nil {
+			s.log.Errorf("service error: %v", err)
+			ctx.JSON(http.StatusInternalServerError, errResponse)
+			return
+		}
+
+		response := model.Response{
+			Status: model.ResponseSuccess,
+			Msg:    "",
+			Data:   toNotificationPreferenceResponse(preference),
+		}
+
+		ctx.JSON(http.StatusOK, response)
+	}
+}
+
+func (s *Server) VerifyNotificationEmail() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		errResponse := model.Response{
+			Status: model.ResponseError,
+			Msg:    "notification email cannot be verified",
+			Data:   nil,
+		}
+
+		verifyReq := &model.NotificationEmailVerifyRequest{}
+
+		err := ctx.ShouldBindJSON(verifyReq)
+
+		if err != nil {
+			s.log.Error(err)
+			ctx.JSON(http.StatusBadRequest, errResponse)
+			return
+		}
+
+		if err := s.validate.Struct(verifyReq); err != nil {
+			s.log.Warn(err)
+			ctx.JSON(http.StatusBadRequest, errResponse)
+			return
+		}
+
+		preference, err := s.notificationService.VerifyNotificationEmail(
+			ctx.Request.Context(),
+			verifyReq,
+		)
+
+		if errors.Is(err, api.ErrEmailVerificationInvalid) {
+			ctx.JSON(http.StatusBadRequest, errResponse)
+			return
+		}
+
+		if err != nil {
+			s.log.Errorf("service error: %v", err)
+			ctx.JSON(http.StatusInternalServerError, errResponse)
+			return
+		}
+
+		response := model.Response{
+			Status: model.ResponseSuccess,
+			Msg:    "",
+			Data:   toNotificationPreferenceResponse(preference),
+		}
+
+		ctx.JSON(http.StatusOK, response)
+	}
+}
+
+func toNotificationPreferenceResponse(
+	preference *model.NotificationPreference,
+) *model.NotificationPreferenceResponse {
+	kinds := make([]model.NotificationKind, 0, len(preference.Kinds))
+
+	for _, kind := range preference.Kinds {
+		kinds = append(kinds, model.NotificationKind(kind))
+	}
+
+	return &model.NotificationPreferenceResponse{
+		Kinds:          kinds,
+		Email:          preference.Email,
+		WebhookURL:     preference.WebhookURL,
+		WebhookSecret:  preference.WebhookSecret,
+		EmailEnabled:   preference.EmailEnabled,
+		EmailVerified:  preference.EmailVerified(),
+		WebhookEnabled: preference.WebhookEnabled,
+	}
+}
@@ -0,0 +1,43 @@
+package app
+
+import (
+	"github.com/VerzCar/vyf-vote-circle/api/model"
+	"github.com/gin-gonic/gin"
+	"net/http"
+)
+
+func (s *Server) OutboxDeadLetters() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		errResponse := model.Response{
+			Status: model.ResponseError,
+			Msg:    "cannot find dead letters",
+			Data:   nil,
+		}
+
+		circleReq := &model.CircleUriRequest{}
+
+		err := ctx.ShouldBindUri(circleReq)
+
+		if err != nil {
+			s.log.Error(err)
+			ctx.JSON(http.StatusBadRequest, errResponse)
+			return
+		}
+
+		deadLetters, err := s.outboxService.DeadLetters(ctx.Request.Context(), circleReq.CircleID)
+
+		if err != nil {
+			s.log.Errorf("service error: %v", err)
+			ctx.JSON(http.StatusInternalServerError, errResponse)
+			return
+		}
+
+		response := model.Response{
+			Status: model.ResponseSuccess,
+			Msg:    "",
+			Data:   deadLetters,
+		}
+
+		ctx.JSON(http.StatusOK, response)
+	}
+}
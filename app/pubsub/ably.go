@@ -0,0 +1,89 @@
+package pubsub
+
+import (
+	"context"
+	"encoding/json"
+	logger "github.com/VerzCar/vyf-lib-logger"
+	"github.com/ably/ably-go/ably"
+)
+
+type ablyClient struct {
+	realtime *ably.Realtime
+	log      logger.Logger
+}
+
+func NewAblyClient(
+	realtime *ably.Realtime,
+	log logger.Logger,
+) Client {
+	return &ablyClient{
+		realtime: realtime,
+		log:      log,
+	}
+}
+
+// Publish the messages to the ably channel
+func (c *ablyClient) Publish(
+	ctx context.Context,
+	channel string,
+	messages ...*Message,
+) error {
+	ablyMessages := make([]*ably.Message, 0, len(messages))
+
+	for _, message := range messages {
+		ablyMessages = append(
+			ablyMessages, &ably.Message{
+				Name: message.Name,
+				Data: message.Data,
+			},
+		)
+	}
+
+	return c.realtime.Channels.Get(channel).PublishMultiple(ctx, ablyMessages)
+}
+
+// Subscribe to all messages of the ably channels
+func (c *ablyClient) Subscribe(
+	ctx context.Context,
+	channels []string,
+	handler MessageHandler,
+) (func(), error) {
+	unsubscribes := make([]func(), 0, len(channels))
+
+	unsubscribe := func() {
+		for _, unsubscribe := range unsubscribes {
+			unsubscribe()
+		}
+	}
+
+	for _, channel := range channels {
+		channel := channel
+
+		unsubscribeChannel, err := c.realtime.Channels.Get(channel).SubscribeAll(
+			ctx, func(message *ably.Message) {
+				data, err := json.Marshal(message.Data)
+
+				if err != nil {
+					c.log.Errorf("could not encode message of channel %s: %s", channel, err)
+					return
+				}
+
+				handler(channel, &ReceivedMessage{Name: message.Name, Data: data})
+			},
+		)
+
+		if err != nil {
+			unsubscribe()
+			return nil, err
+		}
+
+		unsubscribes = append(unsubscribes, unsubscribeChannel)
+	}
+
+	return unsubscribe, nil
+}
+
+// CreateTokenRequest for a client to subscribe to the ably channels
+func (c *ablyClient) CreateTokenRequest(params *ably.TokenParams) (*ably.TokenRequest, error) {
+	return c.realtime.Auth.CreateTokenRequest(params)
+}
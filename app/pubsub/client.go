@@ -0,0 +1,75 @@
+package pubsub
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	logger "github.com/VerzCar/vyf-lib-logger"
+	"github.com/VerzCar/vyf-vote-circle/app/cache"
+	"github.com/VerzCar/vyf-vote-circle/app/config"
+	"github.com/ably/ably-go/ably"
+)
+
+// ErrTokenRequestUnsupported is returned, if the pub sub backend
+// does not issue tokens to the clients.
+var ErrTokenRequestUnsupported = errors.New("token request not supported by pub sub provider")
+
+// Message published to a channel
+type Message struct {
+	Name string      `json:"name"`
+	Data interface{} `json:"data"`
+}
+
+// ReceivedMessage of a subscribed channel with the JSON encoded data
+type ReceivedMessage struct {
+	Name string          `json:"name"`
+	Data json.RawMessage `json:"data"`
+}
+
+// MessageHandler is called for each message received on a subscribed channel
+type MessageHandler func(channel string, message *ReceivedMessage)
+
+// Client of the pub sub backend, that publishes the messages
+// to the channels the clients are subscribed to.
+type Client interface {
+	Publish(
+		ctx context.Context,
+		channel string,
+		messages ...*Message,
+	) error
+	// Subscribe to the channels until the returned unsubscribe function is called.
+	Subscribe(
+		ctx context.Context,
+		channels []string,
+		handler MessageHandler,
+	) (func(), error)
+}
+
+// TokenIssuer issues the tokens, that allow the clients to subscribe
+// to the channels of the ably backend directly.
+type TokenIssuer interface {
+	CreateTokenRequest(params *ably.TokenParams) (*ably.TokenRequest, error)
+}
+
+// NewClient creates the client of the configured pub sub provider.
+// If the provider is redis, the messages are published via the redis
+// server of the cache and no ably key is required.
+func NewClient(log logger.Logger, conf *config.Config) Client {
+	switch conf.PubSub.Provider {
+	case config.PubSubProviderRedis:
+		log.Infof("Use redis pub sub.")
+		return NewRedisClient(cache.Connect(log, conf), log)
+	default:
+		return NewAblyClient(Connect(log, conf), log)
+	}
+}
+
+// NewTokenIssuer of the pub sub client. Returns nil, if the pub sub
+// backend does not issue tokens to the clients.
+func NewTokenIssuer(client Client) TokenIssuer {
+	if issuer, ok := client.(TokenIssuer); ok {
+		return issuer
+	}
+
+	return nil
+}
@@ -0,0 +1,93 @@
+package pubsub
+
+import (
+	"context"
+	"encoding/json"
+	logger "github.com/VerzCar/vyf-lib-logger"
+	"github.com/go-redis/redis/v8"
+)
+
+// RedisClient used to publish and subscribe to the messages
+type RedisClient interface {
+	Pipelined(ctx context.Context, fn func(redis.Pipeliner) error) ([]redis.Cmder, error)
+	Subscribe(ctx context.Context, channels ...string) *redis.PubSub
+}
+
+type redisClient struct {
+	redis RedisClient
+	log   logger.Logger
+}
+
+func NewRedisClient(
+	redis RedisClient,
+	log logger.Logger,
+) Client {
+	return &redisClient{
+		redis: redis,
+		log:   log,
+	}
+}
+
+// Publish the messages JSON encoded to the redis channel.
+// All messages are published in one pipeline in the given order.
+func (c *redisClient) Publish(
+	ctx context.Context,
+	channel string,
+	messages ...*Message,
+) error {
+	payloads := make([]string, 0, len(messages))
+
+	for _, message := range messages {
+		payload, err := json.Marshal(message)
+
+		if err != nil {
+			return err
+		}
+
+		payloads = append(payloads, string(payload))
+	}
+
+	_, err := c.redis.Pipelined(
+		ctx, func(pipe redis.Pipeliner) error {
+			for _, payload := range payloads {
+				pipe.Publish(ctx, channel, payload)
+			}
+			return nil
+		},
+	)
+
+	return err
+}
+
+// Subscribe to the redis channels. Messages that
+// are not JSON encoded messages will be skipped.
+func (c *redisClient) Subscribe(
+	ctx context.Context,
+	channels []string,
+	handler MessageHandler,
+) (func(), error) {
+	pubSub := c.redis.Subscribe(ctx, channels...)
+
+	// wait for the confirmation of the subscription
+	if _, err := pubSub.Receive(ctx); err != nil {
+		_ = pubSub.Close()
+		return nil, err
+	}
+
+	go func() {
+		for msg := range pubSub.Channel() {
+			message := &ReceivedMessage{}
+
+			if err := json.Unmarshal([]byte(msg.Payload), message); err != nil {
+				c.log.Warnf("skip message of channel %s: %s", msg.Channel, err)
+				continue
+			}
+
+			handler(msg.Channel, message)
+		}
+	}()
+
+	return func() {
+		_ = pubSub.Close()
+	}, nil
+}
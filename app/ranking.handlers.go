@@ -42,6 +42,49 @@ func (s *Server) Rankings() gin.HandlerFunc {
 			Status: model.ResponseSuccess,
 			Msg:    "",
 			Data:   rankings,
+			Meta:   s.quorumMeta(ctx, rankingsReq.CircleID),
+		}
+
+		ctx.JSON(http.StatusOK, response)
+	}
+}
+
+func (s *Server) RankingsTurnout() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		errResponse := model.Response{
+			Status: model.ResponseError,
+			Msg:    "cannot find turnout",
+			Data:   false,
+		}
+
+		rankingsReq := &model.RankingsUriRequest{}
+
+		err := ctx.ShouldBindUri(rankingsReq)
+
+		if err != nil {
+			s.log.Error(err)
+			ctx.JSON(http.StatusBadRequest, errResponse)
+			return
+		}
+
+		if err := s.validate.Struct(rankingsReq); err != nil {
+			s.log.Warn(err)
+			ctx.JSON(http.StatusBadRequest, errResponse)
+			return
+		}
+
+		turnout, err := s.rankingService.Turnout(ctx.Request.Context(), rankingsReq.CircleID)
+
+		if err != nil {
+			s.log.Errorf("service error: %v", err)
+			ctx.JSON(http.StatusInternalServerError, errResponse)
+			return
+		}
+
+		response := model.Response{
+			Status: model.ResponseSuccess,
+			Msg:    "",
+			Data:   turnout,
 		}
 
 		ctx.JSON(http.StatusOK, response)
@@ -73,3 +116,82 @@ func (s *Server) RankingsLastViewed() gin.HandlerFunc {
 		ctx.JSON(http.StatusOK, response)
 	}
 }
+
+func (s *Server) RankingChanges() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		errResponse := model.Response{
+			Status: model.ResponseError,
+			Msg:    "cannot find ranking changes",
+			Data:   nil,
+		}
+
+		rankingsReq := &model.RankingsUriRequest{}
+
+		err := ctx.ShouldBindUri(rankingsReq)
+
+		if err != nil {
+			s.log.Error(err)
+			ctx.JSON(http.StatusBadRequest, errResponse)
+			return
+		}
+
+		if err := s.validate.Struct(rankingsReq); err != nil {
+			s.log.Warn(err)
+			ctx.JSON(http.StatusBadRequest, errResponse)
+			return
+		}
+
+		changesReq := &model.EventChangesRequest{}
+
+		err = ctx.ShouldBind(changesReq)
+
+		if err != nil {
+			s.log.Error(err)
+			ctx.JSON(http.StatusBadRequest, errResponse)
+			return
+		}
+
+		if err := s.validate.Struct(changesReq); err != nil {
+			s.log.Warn(err)
+			ctx.JSON(http.StatusBadRequest, errResponse)
+			return
+		}
+
+		changes, err := s.rankingService.RankingChanges(
+			ctx.Request.Context(),
+			rankingsReq.CircleID,
+			changesReq.Since,
+		)
+
+		if err != nil {
+			s.log.Errorf("service error: %v", err)
+			ctx.JSON(http.StatusInternalServerError, errResponse)
+			return
+		}
+
+		response := model.Response{
+			Status: model.ResponseSuccess,
+			Msg:    "",
+			Data:   changes,
+		}
+
+		ctx.JSON(http.StatusOK, response)
+	}
+}
+
+// quorumMeta with the quorum progress of the circle while it is hot.
+// The response is still sent, if the quorum cannot be evaluated.
+func (s *Server) quorumMeta(ctx *gin.Context, circleId int64) interface{} {
+	quorum, err := s.rankingService.Quorum(ctx.Request.Context(), circleId)
+
+	if err != nil {
+		s.log.Errorf("service error: %v", err)
+		return nil
+	}
+
+	if quorum == nil {
+		return nil
+	}
+
+	return &model.CircleQuorumMeta{Quorum: quorum}
+}
@@ -36,6 +36,26 @@ func Authorization(c *gin.Context, headerType string) (string, error) {
 	return authToken, nil
 }
 
+// WebSocketProtocolToken gets the token, that is given as the protocol following the
+// protocol type in the HTTP: Sec-WebSocket-Protocol header from the gin context as string.
+func WebSocketProtocolToken(c *gin.Context, protocolType string) (string, error) {
+	var protocols []string
+
+	for _, value := range c.Request.Header.Values("Sec-WebSocket-Protocol") {
+		for _, protocol := range strings.Split(value, ",") {
+			protocols = append(protocols, strings.TrimSpace(protocol))
+		}
+	}
+
+	for i, protocol := range protocols {
+		if protocol == protocolType && i+1 < len(protocols) && protocols[i+1] != "" {
+			return protocols[i+1], nil
+		}
+	}
+
+	return "", fmt.Errorf("header [Sec-WebSocket-Protocol] does not contain a " + protocolType + " token")
+}
+
 // BearerToken prepares the given access token to with the Bearer prefix.
 // Returns the formatted access token with the bearer prefix.
 func BearerToken(accessToken string) string {
@@ -114,6 +114,65 @@ func TestAuthorization(t *testing.T) {
 	}
 }
 
+func TestWebSocketProtocolToken(t *testing.T) {
+	expectedToken := "ey234fft34r0434frfgtgb5t"
+
+	tests := []struct {
+		name      string
+		protocols []string
+		want      string
+		wantErr   bool
+	}{
+		{
+			name:      "should extract token following the protocol type successfully",
+			protocols: []string{"bearer, " + expectedToken},
+			want:      expectedToken,
+		},
+		{
+			name:      "should extract token from several header values successfully",
+			protocols: []string{"json", "bearer", expectedToken},
+			want:      expectedToken,
+		},
+		{
+			name:      "should fail because the protocol type is missing",
+			protocols: []string{expectedToken},
+			wantErr:   true,
+		},
+		{
+			name:      "should fail because no token follows the protocol type",
+			protocols: []string{"json, bearer"},
+			wantErr:   true,
+		},
+		{
+			name:    "should fail because the header does not exist",
+			wantErr: true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(
+			test.name, func(t *testing.T) {
+				ctx, _ := gin.CreateTestContext(httptest.NewRecorder())
+				ctx.Request, _ = http.NewRequest(http.MethodGet, "/ws", nil)
+
+				for _, protocol := range test.protocols {
+					ctx.Request.Header.Add("Sec-WebSocket-Protocol", protocol)
+				}
+
+				token, err := header.WebSocketProtocolToken(ctx, "bearer")
+
+				if (err != nil) != test.wantErr {
+					t.Errorf("test: %v failed. \ngot error: %v \nwanted error: %v", test.name, err, test.wantErr)
+				}
+
+				if !reflect.DeepEqual(token, test.want) {
+					t.Errorf("test: %v failed. \ngot: %v \nwanted: %v", test.name, token, test.want)
+				}
+			},
+		)
+	}
+}
+
 func TestBearerToken(t *testing.T) {
 
 	expectedBearerToken := "Bearer ey234fft34r0434frfgtgb5t"
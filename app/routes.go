@@ -1,11 +1,22 @@
 package app
 
+import (
+	"expvar"
+	"github.com/gin-gonic/gin"
+)
+
 func (s *Server) routes() {
 	router := s.router
 
 	// Service group
 	v1 := router.Group("/v1/api/vote-circle")
 
+	// WebSocket gateway, browsers cannot set the authorization header
+	// on the handshake, therefore the token is given in the Sec-WebSocket-Protocol
+	// header after the bearer protocol. Never accept it as query param, as the
+	// query ends up in the access logs.
+	v1.GET("/ws", s.webSocketAuthGuard(s.authService), s.WebSocket())
+
 	// Authorization group
 	authorized := v1.Group("")
 	authorized.Use(s.authGuard(s.authService))
@@ -14,10 +25,21 @@ func (s *Server) routes() {
 		circle := authorized.Group("/circle")
 		circle.GET("/:circleId", s.Circle())
 		circle.GET("/:circleId/eligible", s.EligibleToBeInCircle())
+		circle.GET("/:circleId/rounds", s.CircleRounds())
 		circle.POST("", s.CreateCircle())
 		circle.PUT("/:circleId", s.UpdateCircle())
 		circle.DELETE("/:circleId", s.DeleteCircle())
 		circle.PUT("/to-global", s.AddToGlobalCircle())
+		circle.GET("/:circleId/outbox/dead-letters", s.OutboxDeadLetters())
+		circle.GET("/:circleId/stream", s.serverSentHeaders(), s.CircleStream())
+		circle.GET("/:circleId/presence", s.CirclePresence())
+		circle.PUT("/:circleId/presence", s.CirclePresenceHeartbeat())
+		circle.DELETE("/:circleId/presence", s.CirclePresenceLeave())
+		circle.GET("/:circleId/webhooks", s.Webhooks())
+		circle.POST("/:circleId/webhooks", s.CreateWebhook())
+		circle.PUT("/:circleId/webhooks/:webhookId", s.UpdateWebhook())
+		circle.DELETE("/:circleId/webhooks/:webhookId", s.DeleteWebhook())
+		circle.GET("/:circleId/webhooks/:webhookId/deliveries", s.WebhookDeliveries())
 
 		// circles group
 		circles := authorized.Group("/circles")
@@ -43,22 +65,52 @@ func (s *Server) routes() {
 		circleCandidates.POST("/:circleId/add", s.CircleCandidatesAddToCircle())
 		circleCandidates.POST("/:circleId/remove", s.CircleCandidateRemoveFromCircle())
 		circleCandidates.GET("/:circleId/voted-by", s.CircleCandidateVotedBy())
+		circleCandidates.GET("/:circleId/nominations", s.CircleCandidateNominations())
+		circleCandidates.POST("/:circleId/nominate", s.CircleCandidateNominate())
 
 		// vote group
 		vote := authorized.Group("/vote")
 		vote.POST("/:circleId", s.CreateVote())
+		vote.POST("/:circleId/approval", s.CreateApprovalVote())
+		vote.POST("/:circleId/score", s.CreateScoreVote())
 		vote.POST("/revoke/:circleId", s.RevokeVote())
+		vote.GET("/:circleId/delegation", s.VoteDelegation())
+		vote.PUT("/:circleId/delegation", s.DelegateVote())
+		vote.DELETE("/:circleId/delegation", s.RevokeVoteDelegation())
 
 		// rankings group
 		rankings := authorized.Group("/rankings")
 		rankings.GET("/:circleId", s.Rankings())
+		rankings.GET("/:circleId/changes", s.RankingChanges())
+		rankings.GET("/:circleId/turnout", s.RankingsTurnout())
 		rankings.GET("/last-viewed", s.RankingsLastViewed())
 
+		// notifications group
+		notifications := authorized.Group("/notifications")
+		notifications.GET("", s.Notifications())
+		notifications.PUT("/read", s.MarkAllNotificationsRead())
+		notifications.PUT("/:notificationId/read", s.MarkNotificationRead())
+		notifications.GET("/preference", s.NotificationPreference())
+		notifications.PUT("/preference", s.UpdateNotificationPreference())
+		notifications.PUT("/preference/email/verify", s.VerifyNotificationEmail())
+
 		// user option
 		authorized.GET("/user-option", s.UserOption())
+		authorized.GET("/user-option/usage", s.UserOptionUsage())
+
+		// admin group
+		admin := authorized.Group("/admin")
+		admin.Use(s.adminGuard())
+		admin.GET("/user-option/:identityId", s.AdminUserOption())
+		admin.PUT("/user-option/:identityId", s.AdminUpdateUserOption())
+		admin.GET("/user-option/:identityId/audits", s.AdminUserOptionAudits())
+
+		// metrics
+		admin.GET("/debug/vars", gin.WrapH(expvar.Handler()))
 
 		// ably token
 		authorized.GET("/token/ably", s.TokenAbly())
+		authorized.POST("/token/ably/refresh", s.TokenAblyRefresh())
 
 		// Upload group
 		upload := authorized.Group("/upload")
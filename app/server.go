@@ -22,6 +22,12 @@ type Server struct {
 	circleCandidateService api.CircleCandidateService
 	userOptionService      api.UserOptionService
 	tokenService           api.TokenService
+	outboxService          api.OutboxService
+	circleStreamService    api.CircleStreamService
+	circlePresenceService  api.CirclePresenceService
+	webhookService         api.WebhookService
+	notificationService    api.NotificationService
+	adminService           api.AdminService
 	validate               sanitizer.Validator
 	config                 *config.Config
 	log                    logger.Logger
@@ -38,6 +44,12 @@ func NewServer(
 	circleCandidateService api.CircleCandidateService,
 	userOptionService api.UserOptionService,
 	tokenService api.TokenService,
+	outboxService api.OutboxService,
+	circleStreamService api.CircleStreamService,
+	circlePresenceService api.CirclePresenceService,
+	webhookService api.WebhookService,
+	notificationService api.NotificationService,
+	adminService api.AdminService,
 	validate sanitizer.Validator,
 	config *config.Config,
 	log logger.Logger,
@@ -53,6 +65,12 @@ func NewServer(
 		circleCandidateService: circleCandidateService,
 		userOptionService:      userOptionService,
 		tokenService:           tokenService,
+		outboxService:          outboxService,
+		circleStreamService:    circleStreamService,
+		circlePresenceService:  circlePresenceService,
+		webhookService:         webhookService,
+		notificationService:    notificationService,
+		adminService:           adminService,
 		validate:               validate,
 		config:                 config,
 		log:                    log,
@@ -1,20 +1,64 @@
 package app
 
 import (
+	"errors"
+	"github.com/VerzCar/vyf-vote-circle/api/model"
+	"github.com/VerzCar/vyf-vote-circle/app/pubsub"
 	"github.com/gin-gonic/gin"
 	"net/http"
 )
 
 func (s *Server) TokenAbly() gin.HandlerFunc {
 	return func(ctx *gin.Context) {
-		tokenRequest, err := s.tokenService.GenerateAblyToken(ctx.Request.Context())
+		tokenReq := &model.AblyTokenRequest{}
 
-		if err != nil {
-			s.log.Errorf("service error: %v", err)
-			ctx.AbortWithStatus(http.StatusInternalServerError)
+		if err := ctx.ShouldBindQuery(tokenReq); err != nil {
+			s.log.Warn(err)
+			ctx.AbortWithStatus(http.StatusBadRequest)
 			return
 		}
 
-		ctx.JSON(http.StatusOK, tokenRequest)
+		s.ablyTokenRequest(ctx, tokenReq)
 	}
 }
+
+// TokenAblyRefresh issues a new token with the capabilities recomputed,
+// that should be used by the client after joining or leaving a circle.
+func (s *Server) TokenAblyRefresh() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		tokenReq := &model.AblyTokenRequest{}
+
+		if ctx.Request.ContentLength > 0 {
+			if err := ctx.ShouldBindJSON(tokenReq); err != nil {
+				s.log.Warn(err)
+				ctx.AbortWithStatus(http.StatusBadRequest)
+				return
+			}
+		}
+
+		s.ablyTokenRequest(ctx, tokenReq)
+	}
+}
+
+func (s *Server) ablyTokenRequest(ctx *gin.Context, tokenReq *model.AblyTokenRequest) {
+	if err := s.validate.Struct(tokenReq); err != nil {
+		s.log.Warn(err)
+		ctx.AbortWithStatus(http.StatusBadRequest)
+		return
+	}
+
+	tokenRequest, err := s.tokenService.GenerateAblyToken(ctx.Request.Context(), tokenReq)
+
+	if errors.Is(err, pubsub.ErrTokenRequestUnsupported) {
+		ctx.AbortWithStatus(http.StatusNotImplemented)
+		return
+	}
+
+	if err != nil {
+		s.log.Errorf("service error: %v", err)
+		ctx.AbortWithStatus(http.StatusInternalServerError)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, tokenRequest)
+}
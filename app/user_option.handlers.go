@@ -31,3 +31,29 @@ func (s *Server) UserOption() gin.HandlerFunc {
 		ctx.JSON(http.StatusOK, response)
 	}
 }
+
+func (s *Server) UserOptionUsage() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		errResponse := model.Response{
+			Status: model.ResponseError,
+			Msg:    "cannot find usage of user option",
+			Data:   nil,
+		}
+
+		usageResponse, err := s.userOptionService.Usage(ctx.Request.Context())
+
+		if err != nil {
+			s.log.Errorf("service error: %v", err)
+			ctx.JSON(http.StatusInternalServerError, errResponse)
+			return
+		}
+
+		response := model.Response{
+			Status: model.ResponseSuccess,
+			Msg:    "",
+			Data:   usageResponse,
+		}
+
+		ctx.JSON(http.StatusOK, response)
+	}
+}
@@ -0,0 +1,146 @@
+package app
+
+import (
+	"github.com/VerzCar/vyf-vote-circle/api/model"
+	"github.com/gin-gonic/gin"
+	"net/http"
+)
+
+func (s *Server) VoteDelegation() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		errResponse := model.Response{
+			Status: model.ResponseError,
+			Msg:    "cannot find delegation",
+			Data:   nil,
+		}
+
+		circleReq := &model.CircleUriRequest{}
+
+		err := ctx.ShouldBindUri(circleReq)
+
+		if err != nil {
+			s.log.Error(err)
+			ctx.JSON(http.StatusBadRequest, errResponse)
+			return
+		}
+
+		delegation, err := s.voteService.VoteDelegation(ctx.Request.Context(), circleReq.CircleID)
+
+		if err != nil {
+			s.log.Errorf("service error: %v", err)
+			ctx.JSON(http.StatusInternalServerError, errResponse)
+			return
+		}
+
+		response := model.Response{
+			Status: model.ResponseSuccess,
+			Msg:    "",
+			Data:   toVoteDelegationResponse(delegation),
+		}
+
+		ctx.JSON(http.StatusOK, response)
+	}
+}
+
+func (s *Server) DelegateVote() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		errResponse := model.Response{
+			Status: model.ResponseError,
+			Msg:    "vote cannot be delegated",
+			Data:   nil,
+		}
+
+		circleReq := &model.CircleUriRequest{}
+
+		err := ctx.ShouldBindUri(circleReq)
+
+		if err != nil {
+			s.log.Error(err)
+			ctx.JSON(http.StatusBadRequest, errResponse)
+			return
+		}
+
+		delegationReq := &model.VoteDelegationCreateRequest{}
+
+		err = ctx.ShouldBindJSON(delegationReq)
+
+		if err != nil {
+			s.log.Error(err)
+			ctx.JSON(http.StatusBadRequest, errResponse)
+			return
+		}
+
+		if err := s.validate.Struct(delegationReq); err != nil {
+			s.log.Warn(err)
+			ctx.JSON(http.StatusBadRequest, errResponse)
+			return
+		}
+
+		delegation, err := s.voteService.DelegateVote(ctx.Request.Context(), circleReq.CircleID, delegationReq)
+
+		if err != nil {
+			s.log.Errorf("service error: %v", err)
+			ctx.JSON(http.StatusInternalServerError, errResponse)
+			return
+		}
+
+		response := model.Response{
+			Status: model.ResponseSuccess,
+			Msg:    "",
+			Data:   toVoteDelegationResponse(delegation),
+		}
+
+		ctx.JSON(http.StatusOK, response)
+	}
+}
+
+func (s *Server) RevokeVoteDelegation() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		errResponse := model.Response{
+			Status: model.ResponseError,
+			Msg:    "delegation cannot be revoked",
+			Data:   false,
+		}
+
+		circleReq := &model.CircleUriRequest{}
+
+		err := ctx.ShouldBindUri(circleReq)
+
+		if err != nil {
+			s.log.Error(err)
+			ctx.JSON(http.StatusBadRequest, errResponse)
+			return
+		}
+
+		result, err := s.voteService.RevokeVoteDelegation(ctx.Request.Context(), circleReq.CircleID)
+
+		if err != nil {
+			s.log.Errorf("service error: %v", err)
+			ctx.JSON(http.StatusInternalServerError, errResponse)
+			return
+		}
+
+		response := model.Response{
+			Status: model.ResponseSuccess,
+			Msg:    "",
+			Data:   result,
+		}
+
+		ctx.JSON(http.StatusOK, response)
+	}
+}
+
+func toVoteDelegationResponse(delegation *model.VoteDelegation) *model.VoteDelegationResponse {
+	if delegation == nil {
+		return nil
+	}
+
+	return &model.VoteDelegationResponse{
+		CreatedAt: delegation.CreatedAt,
+		UpdatedAt: delegation.UpdatedAt,
+		Delegator: delegation.Delegator.Voter,
+		Delegate:  delegation.Delegate.Voter,
+		ID:        delegation.ID,
+		CircleID:  delegation.CircleID,
+	}
+}
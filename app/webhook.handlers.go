@@ -0,0 +1,292 @@
+package app
+
+import (
+	"github.com/VerzCar/vyf-vote-circle/api/model"
+	"github.com/gin-gonic/gin"
+	"net/http"
+)
+
+func (s *Server) Webhooks() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		errResponse := model.Response{
+			Status: model.ResponseError,
+			Msg:    "cannot find webhooks",
+			Data:   nil,
+		}
+
+		circleReq := &model.CircleUriRequest{}
+
+		err := ctx.ShouldBindUri(circleReq)
+
+		if err != nil {
+			s.log.Error(err)
+			ctx.JSON(http.StatusBadRequest, errResponse)
+			return
+		}
+
+		webhooks, err := s.webhookService.Webhooks(ctx.Request.Context(), circleReq.CircleID)
+
+		if err != nil {
+			s.log.Errorf("service error: %v", err)
+			ctx.JSON(http.StatusInternalServerError, errResponse)
+			return
+		}
+
+		webhooksResponse := make([]*model.WebhookResponse, 0, len(webhooks))
+
+		for _, webhook := range webhooks {
+			webhooksResponse = append(webhooksResponse, toWebhookResponse(webhook))
+		}
+
+		response := model.Response{
+			Status: model.ResponseSuccess,
+			Msg:    "",
+			Data:   webhooksResponse,
+		}
+
+		ctx.JSON(http.StatusOK, response)
+	}
+}
+
+func (s *Server) CreateWebhook() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		errResponse := model.Response{
+			Status: model.ResponseError,
+			Msg:    "webhook cannot be created",
+			Data:   nil,
+		}
+
+		circleReq := &model.CircleUriRequest{}
+
+		err := ctx.ShouldBindUri(circleReq)
+
+		if err != nil {
+			s.log.Error(err)
+			ctx.JSON(http.StatusBadRequest, errResponse)
+			return
+		}
+
+		webhookCreateReq := &model.WebhookCreateRequest{}
+
+		err = ctx.ShouldBindJSON(webhookCreateReq)
+
+		if err != nil {
+			s.log.Error(err)
+			ctx.JSON(http.StatusBadRequest, errResponse)
+			return
+		}
+
+		if err := s.validate.Struct(webhookCreateReq); err != nil {
+			s.log.Warn(err)
+			ctx.JSON(http.StatusBadRequest, errResponse)
+			return
+		}
+
+		webhook, err := s.webhookService.CreateWebhook(ctx.Request.Context(), circleReq.CircleID, webhookCreateReq)
+
+		if err != nil {
+			s.log.Errorf("service error: %v", err)
+			ctx.JSON(http.StatusInternalServerError, errResponse)
+			return
+		}
+
+		// the secret is only revealed once, when the webhook has been created
+		webhookResponse := toWebhookResponse(webhook)
+		webhookResponse.Secret = webhook.Secret
+
+		response := model.Response{
+			Status: model.ResponseSuccess,
+			Msg:    "",
+			Data:   webhookResponse,
+		}
+
+		ctx.JSON(http.StatusOK, response)
+	}
+}
+
+func (s *Server) UpdateWebhook() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		errResponse := model.Response{
+			Status: model.ResponseError,
+			Msg:    "webhook cannot be updated",
+			Data:   nil,
+		}
+
+		webhookReq := &model.WebhookUriRequest{}
+
+		err := ctx.ShouldBindUri(webhookReq)
+
+		if err != nil {
+			s.log.Error(err)
+			ctx.JSON(http.StatusBadRequest, errResponse)
+			return
+		}
+
+		if err := s.validate.Struct(webhookReq); err != nil {
+			s.log.Warn(err)
+			ctx.JSON(http.StatusBadRequest, errResponse)
+			return
+		}
+
+		webhookUpdateReq := &model.WebhookUpdateRequest{}
+
+		err = ctx.ShouldBindJSON(webhookUpdateReq)
+
+		if err != nil {
+			s.log.Error(err)
+			ctx.JSON(http.StatusBadRequest, errResponse)
+			return
+		}
+
+		if err := s.validate.Struct(webhookUpdateReq); err != nil {
+			s.log.Warn(err)
+			ctx.JSON(http.StatusBadRequest, errResponse)
+			return
+		}
+
+		webhook, err := s.webhookService.UpdateWebhook(
+			ctx.Request.Context(),
+			webhookReq.CircleID,
+			webhookReq.WebhookID,
+			webhookUpdateReq,
+		)
+
+		if err != nil {
+			s.log.Errorf("service error: %v", err)
+			ctx.JSON(http.StatusInternalServerError, errResponse)
+			return
+		}
+
+		response := model.Response{
+			Status: model.ResponseSuccess,
+			Msg:    "",
+			Data:   toWebhookResponse(webhook),
+		}
+
+		ctx.JSON(http.StatusOK, response)
+	}
+}
+
+func (s *Server) DeleteWebhook() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		errResponse := model.Response{
+			Status: model.ResponseError,
+			Msg:    "webhook cannot be deleted",
+			Data:   nil,
+		}
+
+		webhookReq := &model.WebhookUriRequest{}
+
+		err := ctx.ShouldBindUri(webhookReq)
+
+		if err != nil {
+			s.log.Error(err)
+			ctx.JSON(http.StatusBadRequest, errResponse)
+			return
+		}
+
+		if err := s.validate.Struct(webhookReq); err != nil {
+			s.log.Warn(err)
+			ctx.JSON(http.StatusBadRequest, errResponse)
+			return
+		}
+
+		err = s.webhookService.DeleteWebhook(ctx.Request.Context(), webhookReq.CircleID, webhookReq.WebhookID)
+
+		if err != nil {
+			s.log.Errorf("service error: %v", err)
+			ctx.JSON(http.StatusInternalServerError, errResponse)
+			return
+		}
+
+		response := model.Response{
+			Status: model.ResponseSuccess,
+			Msg:    "",
+			Data:   true,
+		}
+
+		ctx.JSON(http.StatusOK, response)
+	}
+}
+
+func (s *Server) WebhookDeliveries() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		errResponse := model.Response{
+			Status: model.ResponseError,
+			Msg:    "cannot find webhook deliveries",
+			Data:   nil,
+		}
+
+		webhookReq := &model.WebhookUriRequest{}
+
+		err := ctx.ShouldBindUri(webhookReq)
+
+		if err != nil {
+			s.log.Error(err)
+			ctx.JSON(http.StatusBadRequest, errResponse)
+			return
+		}
+
+		if err := s.validate.Struct(webhookReq); err != nil {
+			s.log.Warn(err)
+			ctx.JSON(http.StatusBadRequest, errResponse)
+			return
+		}
+
+		deliveries, err := s.webhookService.WebhookDeliveries(
+			ctx.Request.Context(),
+			webhookReq.CircleID,
+			webhookReq.WebhookID,
+		)
+
+		if err != nil {
+			s.log.Errorf("service error: %v", err)
+			ctx.JSON(http.StatusInternalServerError, errResponse)
+			return
+		}
+
+		deliveriesResponse := make([]*model.WebhookDeliveryResponse, 0, len(deliveries))
+
+		for _, delivery := range deliveries {
+			deliveriesResponse = append(
+				deliveriesResponse, &model.WebhookDeliveryResponse{
+					CreatedAt:     delivery.CreatedAt,
+					NextAttemptAt: delivery.NextAttemptAt,
+					DeliveredAt:   delivery.DeliveredAt,
+					Event:         delivery.Event,
+					Status:        delivery.Status,
+					LastError:     delivery.LastError,
+					ID:            delivery.ID,
+					ResponseCode:  delivery.ResponseCode,
+					Attempts:      delivery.Attempts,
+				},
+			)
+		}
+
+		response := model.Response{
+			Status: model.ResponseSuccess,
+			Msg:    "",
+			Data:   deliveriesResponse,
+		}
+
+		ctx.JSON(http.StatusOK, response)
+	}
+}
+
+func toWebhookResponse(webhook *model.Webhook) *model.WebhookResponse {
+	events := make([]model.WebhookEvent, 0, len(webhook.Events))
+
+	for _, event := range webhook.Events {
+		events = append(events, model.WebhookEvent(event))
+	}
+
+	return &model.WebhookResponse{
+		CreatedAt: webhook.CreatedAt,
+		UpdatedAt: webhook.UpdatedAt,
+		URL:       webhook.URL,
+		Events:    events,
+		ID:        webhook.ID,
+		CircleID:  webhook.CircleID,
+		Active:    webhook.Active,
+	}
+}
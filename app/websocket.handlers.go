@@ -0,0 +1,371 @@
+package app
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"github.com/VerzCar/vyf-vote-circle/api/model"
+	"net/url"
+	"nhooyr.io/websocket"
+	"nhooyr.io/websocket/wsjson"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	// defaultWebSocketMaxSubscriptions is used if no max subscriptions are configured.
+	defaultWebSocketMaxSubscriptions = 20
+	// webSocketWriteTimeout of a single frame
+	webSocketWriteTimeout = 10 * time.Second
+	// webSocketBearerProtocol precedes the access token in the Sec-WebSocket-Protocol header
+	// and is the protocol the server agrees on.
+	webSocketBearerProtocol = "bearer"
+	// webSocketTokenExpiryKey of the access token expiry in the context
+	webSocketTokenExpiryKey = "webSocketTokenExpiry"
+)
+
+func (s *Server) WebSocket() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		conn, err := websocket.Accept(
+			ctx.Writer, ctx.Request, &websocket.AcceptOptions{
+				OriginPatterns: webSocketOriginPatterns(s.config.Security.Cors.Origins),
+				Subprotocols:   []string{webSocketBearerProtocol},
+			},
+		)
+
+		if err != nil {
+			s.log.Errorf("could not accept websocket: %s", err)
+			return
+		}
+
+		session := &webSocketSession{
+			server:         s,
+			conn:           conn,
+			tokenExpiresAt: ctx.GetTime(webSocketTokenExpiryKey),
+			subscriptions:  make(map[int64]context.CancelFunc),
+		}
+
+		session.serve(ctx.Request.Context())
+	}
+}
+
+// webSocketSession of a client, that can subscribe to several circles
+// and vote over the same connection.
+type webSocketSession struct {
+	server         *Server
+	conn           *websocket.Conn
+	tokenExpiresAt time.Time
+	writeMu        sync.Mutex
+	mu             sync.Mutex
+	subscriptions  map[int64]context.CancelFunc
+}
+
+// serve the requests of the client until the connection is closed
+func (w *webSocketSession) serve(ctx context.Context) {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	defer w.conn.Close(websocket.StatusNormalClosure, "")
+
+	go w.heartbeat(ctx, cancel)
+	go w.expire(ctx)
+
+	for {
+		req := &model.WebSocketRequest{}
+
+		if err := wsjson.Read(ctx, w.conn, req); err != nil {
+			return
+		}
+
+		w.handle(ctx, req)
+	}
+}
+
+func (w *webSocketSession) handle(ctx context.Context, req *model.WebSocketRequest) {
+	if err := w.server.validate.Struct(req); err != nil {
+		w.server.log.Warn(err)
+		w.writeError(ctx, req, "invalid request")
+		return
+	}
+
+	switch req.Type {
+	case model.WebSocketMessageSubscribe:
+		w.subscribe(ctx, req)
+	case model.WebSocketMessageUnsubscribe:
+		w.unsubscribe(req.CircleID)
+		w.write(
+			ctx, &model.WebSocketResponse{
+				Type:      model.WebSocketMessageUnsubscribed,
+				RequestID: req.RequestID,
+				CircleID:  req.CircleID,
+			},
+		)
+	case model.WebSocketMessageVote:
+		if len(req.CandidateIDs) > 0 {
+			w.approvalVote(ctx, req)
+			return
+		}
+
+		if len(req.Scores) > 0 {
+			w.scoreVote(ctx, req)
+			return
+		}
+
+		voteCreateReq := &model.VoteCreateRequest{CandidateID: req.CandidateID, Abstain: req.Abstain}
+
+		if err := w.server.validate.Struct(voteCreateReq); err != nil {
+			w.server.log.Warn(err)
+			w.writeError(ctx, req, "vote cannot be created")
+			return
+		}
+
+		result, err := w.server.voteService.CreateVote(ctx, req.CircleID, voteCreateReq)
+
+		if err != nil {
+			w.server.log.Errorf("service error: %v", err)
+			w.writeError(ctx, req, "vote cannot be created")
+			return
+		}
+
+		w.writeResult(ctx, req, result)
+	case model.WebSocketMessageRevokeVote:
+		result, err := w.server.voteService.RevokeVote(ctx, req.CircleID)
+
+		if err != nil {
+			w.server.log.Errorf("service error: %v", err)
+			w.writeError(ctx, req, "vote cannot be revoked")
+			return
+		}
+
+		w.writeResult(ctx, req, result)
+	}
+}
+
+// approvalVote approves the candidates of the request in an approval circle
+func (w *webSocketSession) approvalVote(ctx context.Context, req *model.WebSocketRequest) {
+	voteCreateReq := &model.ApprovalVoteCreateRequest{CandidateIDs: req.CandidateIDs}
+
+	if err := w.server.validate.Struct(voteCreateReq); err != nil {
+		w.server.log.Warn(err)
+		w.writeError(ctx, req, "vote cannot be created")
+		return
+	}
+
+	result, err := w.server.voteService.CreateApprovalVote(ctx, req.CircleID, voteCreateReq)
+
+	if err != nil {
+		w.server.log.Errorf("service error: %v", err)
+		w.writeError(ctx, req, "vote cannot be created")
+		return
+	}
+
+	w.writeResult(ctx, req, result)
+}
+
+// scoreVote scores the candidates of the request in a score circle
+func (w *webSocketSession) scoreVote(ctx context.Context, req *model.WebSocketRequest) {
+	voteCreateReq := &model.ScoreVoteCreateRequest{Scores: req.Scores}
+
+	if err := w.server.validate.Struct(voteCreateReq); err != nil {
+		w.server.log.Warn(err)
+		w.writeError(ctx, req, "vote cannot be created")
+		return
+	}
+
+	result, err := w.server.voteService.CreateScoreVote(ctx, req.CircleID, voteCreateReq)
+
+	if err != nil {
+		w.server.log.Errorf("service error: %v", err)
+		w.writeError(ctx, req, "vote cannot be created")
+		return
+	}
+
+	w.writeResult(ctx, req, result)
+}
+
+// subscribe to the events of the circle, if the user is eligible to see the circle
+func (w *webSocketSession) subscribe(ctx context.Context, req *model.WebSocketRequest) {
+	w.mu.Lock()
+	_, subscribed := w.subscriptions[req.CircleID]
+	subscriptionCount := len(w.subscriptions)
+	w.mu.Unlock()
+
+	if !subscribed && subscriptionCount >= w.maxSubscriptions() {
+		w.writeError(ctx, req, "too many subscriptions")
+		return
+	}
+
+	if !subscribed {
+		subscriptionCtx, cancel := context.WithCancel(ctx)
+
+		events, err := w.server.circleStreamService.Subscribe(subscriptionCtx, req.CircleID, req.LastSequence)
+
+		if err != nil {
+			cancel()
+			w.server.log.Errorf("service error: %v", err)
+			w.writeError(ctx, req, "cannot subscribe to circle")
+			return
+		}
+
+		w.mu.Lock()
+		w.subscriptions[req.CircleID] = cancel
+		w.mu.Unlock()
+
+		go w.forward(subscriptionCtx, req.CircleID, events)
+	}
+
+	w.write(
+		ctx, &model.WebSocketResponse{
+			Type:      model.WebSocketMessageSubscribed,
+			RequestID: req.RequestID,
+			CircleID:  req.CircleID,
+		},
+	)
+}
+
+func (w *webSocketSession) unsubscribe(circleId int64) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if cancel, ok := w.subscriptions[circleId]; ok {
+		cancel()
+		delete(w.subscriptions, circleId)
+	}
+}
+
+// forward the events of the circle to the client. If the subscription
+// ends without being unsubscribed, e.g. because the client could not keep up,
+// the client is notified to subscribe again with the last received sequence.
+func (w *webSocketSession) forward(
+	ctx context.Context,
+	circleId int64,
+	events <-chan *model.CircleStreamEvent,
+) {
+	for event := range events {
+		w.write(
+			ctx, &model.WebSocketResponse{
+				Type:     model.WebSocketMessageEvent,
+				CircleID: circleId,
+				Name:     event.Name,
+				Sequence: event.Sequence,
+				Data:     event.Data,
+			},
+		)
+	}
+
+	if ctx.Err() != nil {
+		return
+	}
+
+	w.unsubscribe(circleId)
+	w.write(
+		context.Background(), &model.WebSocketResponse{
+			Type:     model.WebSocketMessageUnsubscribed,
+			CircleID: circleId,
+			Msg:      "subscription ended",
+		},
+	)
+}
+
+// heartbeat pings the client in the heartbeat interval
+// and cancels the session if the client does not respond.
+func (w *webSocketSession) heartbeat(ctx context.Context, cancel context.CancelFunc) {
+	ticker := time.NewTicker(w.server.circleStreamService.HeartbeatInterval())
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			pingCtx, pingCancel := context.WithTimeout(ctx, webSocketWriteTimeout)
+			err := w.conn.Ping(pingCtx)
+			pingCancel()
+
+			if err != nil {
+				cancel()
+				return
+			}
+		}
+	}
+}
+
+// expire closes the connection as soon as the access token of the handshake expires.
+func (w *webSocketSession) expire(ctx context.Context) {
+	timer := time.NewTimer(time.Until(w.tokenExpiresAt))
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+	case <-timer.C:
+		w.conn.Close(websocket.StatusPolicyViolation, "access token expired")
+	}
+}
+
+func (w *webSocketSession) writeResult(ctx context.Context, req *model.WebSocketRequest, result interface{}) {
+	data, err := json.Marshal(result)
+
+	if err != nil {
+		w.writeError(ctx, req, "cannot encode result")
+		return
+	}
+
+	w.write(
+		ctx, &model.WebSocketResponse{
+			Type:      model.WebSocketMessageResult,
+			RequestID: req.RequestID,
+			CircleID:  req.CircleID,
+			Data:      data,
+		},
+	)
+}
+
+func (w *webSocketSession) writeError(ctx context.Context, req *model.WebSocketRequest, msg string) {
+	w.write(
+		ctx, &model.WebSocketResponse{
+			Type:      model.WebSocketMessageError,
+			RequestID: req.RequestID,
+			CircleID:  req.CircleID,
+			Msg:       msg,
+		},
+	)
+}
+
+// write the frame to the client, frames are written one at a time
+func (w *webSocketSession) write(ctx context.Context, res *model.WebSocketResponse) {
+	w.writeMu.Lock()
+	defer w.writeMu.Unlock()
+
+	ctx, cancel := context.WithTimeout(ctx, webSocketWriteTimeout)
+	defer cancel()
+
+	if err := wsjson.Write(ctx, w.conn, res); err != nil && !errors.Is(err, context.Canceled) {
+		w.server.log.Warnf("could not write websocket frame: %s", err)
+	}
+}
+
+func (w *webSocketSession) maxSubscriptions() int {
+	if w.server.config.Stream.MaxSubscriptions > 0 {
+		return w.server.config.Stream.MaxSubscriptions
+	}
+	return defaultWebSocketMaxSubscriptions
+}
+
+// webSocketOriginPatterns of the allowed CORS origins,
+// as the WebSocket handshake verifies the host of the origin only.
+func webSocketOriginPatterns(origins []string) []string {
+	patterns := make([]string, 0, len(origins))
+
+	for _, origin := range origins {
+		u, err := url.Parse(origin)
+
+		if err != nil || u.Host == "" {
+			continue
+		}
+
+		patterns = append(patterns, u.Host)
+	}
+
+	return patterns
+}
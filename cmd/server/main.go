@@ -1,6 +1,7 @@
 package main
 
 import (
+	"context"
 	"fmt"
 	"github.com/VerzCar/vyf-lib-awsx"
 	logger "github.com/VerzCar/vyf-lib-logger"
@@ -37,7 +38,9 @@ func run() error {
 
 	db := database.Connect(log, envConfig)
 
-	storage := repository.NewStorage(db, envConfig, log)
+	redis := cache.NewCache(log, envConfig)
+
+	storage := repository.NewCachedStorage(db, redis, envConfig, log)
 
 	sqlDb, _ := db.DB()
 	err := storage.RunMigrationsUp(sqlDb)
@@ -46,8 +49,9 @@ func run() error {
 		return err
 	}
 
-	redisCache := cache.Connect(log, envConfig)
-	redis := cache.NewRedisCache(redisCache, envConfig, log)
+	// rebuild the ranking cache, once it is available again
+	rankingCacheRecoveryService := api.NewRankingCacheRecoveryService(storage, redis, envConfig, log)
+	go rankingCacheRecoveryService.Run(context.Background())
 
 	// initialize auth service
 	authService, err := awsx.NewAuthService(
@@ -76,34 +80,86 @@ func run() error {
 	}
 
 	// initialize pub sub service
-	pubSubService := pubsub.Connect(log, envConfig)
+	pubSubService := pubsub.NewClient(log, envConfig)
 
 	// initialize api services
 	userOptionService := api.NewUserOptionService(storage, envConfig, log)
 	circleService := api.NewCircleService(storage, userOptionService, envConfig, log)
-	circleUploadService := api.NewCircleUploadService(circleService, s3Service, envConfig, log)
-	rankingService := api.NewRankingService(storage, redis, envConfig, log)
-	rankingSubService := api.NewRankingSubscriptionService(pubSubService, log)
-	circleVoterSubService := api.NewCircleVoterSubscriptionService(pubSubService, log)
-	circleCandidateSubService := api.NewCircleCandidateSubscriptionService(pubSubService, log)
-	voteService := api.NewVoteService(
+	circleUploadService := api.NewCircleUploadService(
+		storage,
+		circleService,
+		userOptionService,
+		s3Service,
+		envConfig,
+		log,
+	)
+	eventLogService := api.NewEventLogService(redis, envConfig, log)
+	rankingService := api.NewRankingService(
 		storage,
 		redis,
+		rankingCacheRecoveryService,
+		eventLogService,
+		envConfig,
+		log,
+	)
+	rankingSubService := api.NewRankingSubscriptionService(pubSubService, eventLogService, log)
+
+	if envConfig.PubSub.CoalescingWindow > 0 {
+		rankingSubService = api.NewRankingCoalescingSubscriptionService(pubSubService, eventLogService, log)
+	}
+
+	circleVoterSubService := api.NewCircleVoterSubscriptionService(pubSubService, eventLogService, log)
+	circleCandidateSubService := api.NewCircleCandidateSubscriptionService(pubSubService, eventLogService, log)
+	circleSubService := api.NewCircleSubscriptionService(pubSubService, eventLogService, log)
+	voteService := api.NewVoteService(storage, redis, rankingCacheRecoveryService, envConfig, log)
+	circleVoterService := api.NewCircleVoterService(storage, userOptionService, voteService, envConfig, log)
+	circleCandidateService := api.NewCircleCandidateService(storage, userOptionService, envConfig, log)
+	webhookService := api.NewWebhookService(storage, userOptionService, envConfig, log)
+	notificationChannels := []api.NotificationChannel{api.NewWebhookNotificationChannel(envConfig, log)}
+
+	if envConfig.Notification.Smtp.Host != "" {
+		notificationChannels = append(notificationChannels, api.NewSmtpNotificationChannel(envConfig, log))
+	}
+
+	notificationService := api.NewNotificationService(storage, notificationChannels, envConfig, log)
+	adminService := api.NewAdminService(storage, userOptionService, envConfig, log)
+	tokenService := api.NewTokenService(
+		storage,
+		circleService,
+		pubsub.NewTokenIssuer(pubSubService),
+		envConfig,
+		log,
+	)
+	outboxService := api.NewOutboxService(
+		storage,
 		rankingSubService,
 		circleVoterSubService,
 		circleCandidateSubService,
+		circleSubService,
+		webhookService,
+		notificationService,
 		envConfig,
 		log,
 	)
-	circleVoterService := api.NewCircleVoterService(storage, circleVoterSubService, userOptionService, envConfig, log)
-	circleCandidateService := api.NewCircleCandidateService(
-		storage,
-		circleCandidateSubService,
-		userOptionService,
+
+	circleStageService := api.NewCircleStageService(storage, envConfig, log)
+	circlePresenceService := api.NewCirclePresenceService(circleService, redis, pubSubService, envConfig, log)
+	circleStreamService := api.NewCircleStreamService(
+		circleService,
+		eventLogService,
+		pubSubService,
 		envConfig,
 		log,
 	)
-	tokenService := api.NewTokenService(pubSubService, envConfig, log)
+
+	// publish the realtime events written to the outbox
+	go outboxService.Run(context.Background())
+	// publish the stage changes of the circles
+	go circleStageService.Run(context.Background())
+	// deliver the circle events to the webhooks
+	go webhookService.Run(context.Background())
+	// deliver the notifications and notify the circles closing soon
+	go notificationService.Run(context.Background())
 
 	validate = validator.New()
 
@@ -119,6 +175,12 @@ func run() error {
 		circleCandidateService,
 		userOptionService,
 		tokenService,
+		outboxService,
+		circleStreamService,
+		circlePresenceService,
+		webhookService,
+		notificationService,
+		adminService,
 		validate,
 		envConfig,
 		log,
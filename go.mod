@@ -59,8 +59,10 @@ require (
 	github.com/VerzCar/vyf-lib-logger v1.1.0
 	github.com/ably/ably-go v1.2.17
 	github.com/go-redis/redis/v8 v8.11.5
+	github.com/lestrrat-go/jwx/v2 v2.1.0
 	github.com/rs/cors/wrapper/gin v0.0.0-20240515105523-1562b1715b35
 	golang.org/x/image v0.17.0
+	nhooyr.io/websocket v1.8.10
 )
 
 require (
@@ -92,7 +94,6 @@ require (
 	github.com/lestrrat-go/httpcc v1.0.1 // indirect
 	github.com/lestrrat-go/httprc v1.0.5 // indirect
 	github.com/lestrrat-go/iter v1.0.2 // indirect
-	github.com/lestrrat-go/jwx/v2 v2.1.0 // indirect
 	github.com/lestrrat-go/option v1.0.1 // indirect
 	github.com/pelletier/go-toml/v2 v2.2.2 // indirect
 	github.com/sagikazarmark/locafero v0.6.0 // indirect
@@ -103,5 +104,4 @@ require (
 	golang.org/x/arch v0.8.0 // indirect
 	golang.org/x/exp v0.0.0-20240613232115-7f521ea00fb8 // indirect
 	golang.org/x/sync v0.7.0 // indirect
-	nhooyr.io/websocket v1.8.10 // indirect
 )
@@ -0,0 +1,58 @@
+package repository
+
+import (
+	"github.com/VerzCar/vyf-vote-circle/api/model"
+	"github.com/VerzCar/vyf-vote-circle/app/database"
+	"gorm.io/gorm"
+)
+
+// SaveUserOption of a user together with the audits of the changes
+func (s *storage) SaveUserOption(
+	option *model.UserOption,
+	audits []*model.UserOptionAudit,
+) (*model.UserOption, error) {
+	err := s.db.Transaction(
+		func(tx *gorm.DB) error {
+			if err := tx.Save(option).Error; err != nil {
+				return err
+			}
+
+			if len(audits) == 0 {
+				return nil
+			}
+
+			return tx.Create(&audits).Error
+		},
+	)
+
+	if err != nil {
+		s.log.Errorf("error saving user option of user id %s: %s", option.IdentityID, err)
+		return nil, err
+	}
+
+	return option, nil
+}
+
+// UserOptionAuditsByUserIdentityId gets the latest changes of the option of the user first
+func (s *storage) UserOptionAuditsByUserIdentityId(
+	userIdentityId string,
+	limit int,
+) ([]*model.UserOptionAudit, error) {
+	var audits []*model.UserOptionAudit
+	err := s.db.Where(&model.UserOptionAudit{IdentityID: userIdentityId}).
+		Order("id desc").
+		Limit(limit).
+		Find(&audits).
+		Error
+
+	switch {
+	case err != nil && !database.RecordNotFound(err):
+		s.log.Errorf("error reading user option audits of user id %s: %s", userIdentityId, err)
+		return nil, err
+	case database.RecordNotFound(err):
+		s.log.Infof("user option audits of user id %s not found: %s", userIdentityId, err)
+		return nil, err
+	}
+
+	return audits, nil
+}
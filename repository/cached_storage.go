@@ -0,0 +1,166 @@
+package repository
+
+import (
+	"context"
+	logger "github.com/VerzCar/vyf-lib-logger"
+	"github.com/VerzCar/vyf-vote-circle/api/model"
+	"github.com/VerzCar/vyf-vote-circle/app/cache"
+	"github.com/VerzCar/vyf-vote-circle/app/config"
+	"github.com/VerzCar/vyf-vote-circle/app/database"
+)
+
+type CircleCache interface {
+	Circle(
+		ctx context.Context,
+		circleId int64,
+	) (*model.Circle, bool, error)
+	SetCircle(
+		ctx context.Context,
+		circle *model.Circle,
+	) error
+	RemoveCircle(
+		ctx context.Context,
+		circleId int64,
+	) error
+	CircleMembership(
+		ctx context.Context,
+		circleId int64,
+		member cache.CircleMember,
+		userIdentityId string,
+	) (bool, bool, error)
+	SetCircleMembership(
+		ctx context.Context,
+		circleId int64,
+		member cache.CircleMember,
+		userIdentityId string,
+		isMember bool,
+		onlyIfAbsent bool,
+	) error
+}
+
+// cachedStorage reads the circles and the memberships of the
+// users in the circles through the cache. All other operations
+// are passed to the storage.
+// Errors of the cache are logged and the storage is used instead.
+type cachedStorage struct {
+	*storage
+	cache CircleCache
+}
+
+// NewCachedStorage creates the storage with a read through cache
+// for the circle metadata and the membership checks.
+func NewCachedStorage(
+	db database.Client,
+	cache CircleCache,
+	config *config.Config,
+	log logger.Logger,
+) Storage {
+	return &cachedStorage{
+		storage: &storage{
+			db:              db,
+			membershipCache: cache,
+			config:          config,
+			log:             log,
+		},
+		cache: cache,
+	}
+}
+
+// CircleById gets the circle by id from the cache.
+// If the circle is not cached or the stage of the cached circle
+// is outdated, the circle is read from the storage and cached.
+func (s *cachedStorage) CircleById(id int64) (*model.Circle, error) {
+	ctx := context.Background()
+
+	circle, exists, err := s.cache.Circle(ctx, id)
+
+	if err == nil && exists && (!circle.IsEditable() || circle.CurrentStage() == circle.Stage) {
+		return circle, nil
+	}
+
+	circle, err = s.storage.CircleById(id)
+
+	if err != nil {
+		return nil, err
+	}
+
+	_ = s.cache.SetCircle(ctx, circle)
+
+	return circle, nil
+}
+
+// UpdateCircle update circle and removes it from the cache
+func (s *cachedStorage) UpdateCircle(
+	circle *model.Circle,
+	outboxEvents model.OutboxEventsCallback,
+) (*model.Circle, error) {
+	circle, err := s.storage.UpdateCircle(circle, outboxEvents)
+
+	if err != nil {
+		return nil, err
+	}
+
+	_ = s.cache.RemoveCircle(context.Background(), circle.ID)
+
+	return circle, nil
+}
+
+// UpdateCircleResult of the circle and removes it from the cache
+func (s *cachedStorage) UpdateCircleResult(
+	circle *model.Circle,
+	result model.CircleResult,
+	outboxEvents model.OutboxEventsCallback,
+) (*model.Circle, error) {
+	circle, err := s.storage.UpdateCircleResult(circle, result, outboxEvents)
+
+	if err != nil {
+		return nil, err
+	}
+
+	_ = s.cache.RemoveCircle(context.Background(), circle.ID)
+
+	return circle, nil
+}
+
+// IsVoterInCircle determines if the user exists in the circle voters list
+func (s *cachedStorage) IsVoterInCircle(
+	userIdentityId string,
+	circleId int64,
+) (bool, error) {
+	return s.isMemberInCircle(cache.CircleMemberVoter, userIdentityId, circleId, s.storage.IsVoterInCircle)
+}
+
+// IsCandidateInCircle determines if the user exists in the circle candidates list
+func (s *cachedStorage) IsCandidateInCircle(
+	userIdentityId string,
+	circleId int64,
+) (bool, error) {
+	return s.isMemberInCircle(cache.CircleMemberCandidate, userIdentityId, circleId, s.storage.IsCandidateInCircle)
+}
+
+// isMemberInCircle reads the membership of the user from the cache.
+// If the membership is not cached, it is read from the storage and cached.
+func (s *cachedStorage) isMemberInCircle(
+	member cache.CircleMember,
+	userIdentityId string,
+	circleId int64,
+	isInCircle func(userIdentityId string, circleId int64) (bool, error),
+) (bool, error) {
+	ctx := context.Background()
+
+	isMember, exists, err := s.cache.CircleMembership(ctx, circleId, member, userIdentityId)
+
+	if err == nil && exists {
+		return isMember, nil
+	}
+
+	isMember, err = isInCircle(userIdentityId, circleId)
+
+	if err != nil {
+		return false, err
+	}
+
+	_ = s.cache.SetCircleMembership(ctx, circleId, member, userIdentityId, isMember, true)
+
+	return isMember, nil
+}
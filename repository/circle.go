@@ -6,6 +6,7 @@ import (
 	"github.com/VerzCar/vyf-vote-circle/app/database"
 	"gorm.io/gorm"
 	"gorm.io/gorm/clause"
+	"time"
 )
 
 // CircleById gets the circle by id
@@ -204,8 +205,22 @@ func (s *storage) CirclesOfInterest(userIdentityId string) ([]*model.CirclePagin
 }
 
 // UpdateCircle update circle based on given circle model
-func (s *storage) UpdateCircle(circle *model.Circle) (*model.Circle, error) {
-	if err := s.db.Save(circle).Error; err != nil {
+func (s *storage) UpdateCircle(
+	circle *model.Circle,
+	outboxEvents model.OutboxEventsCallback,
+) (*model.Circle, error) {
+	err := s.db.Transaction(
+		func(tx *gorm.DB) error {
+			// the stage is only written by the refresh of the circle stages
+			if err := tx.Omit("stage").Save(circle).Error; err != nil {
+				return err
+			}
+
+			return s.txCreateOutboxEvents(tx, outboxEvents)
+		},
+	)
+
+	if err != nil {
 		s.log.Errorf("error updating circle: %s", err)
 		return nil, err
 	}
@@ -218,58 +233,85 @@ func (s *storage) UpdateCircle(circle *model.Circle) (*model.Circle, error) {
 func (s *storage) CreateNewCircle(circle *model.Circle) (*model.Circle, error) {
 	err := s.db.Transaction(
 		func(tx *gorm.DB) error {
-			err := tx.Model(circle).Omit(clause.Associations).Create(circle).Error
+			return s.txCreateCircle(tx, circle)
+		},
+	)
 
-			if err != nil {
-				s.log.Error("error creating circle entry: %s", err)
-				return err
-			}
+	if err != nil {
+		s.log.Error("error creating circle: %s", err)
+		return nil, err
+	}
 
-			circleVoters := circle.Voters
+	s.setCircleMemberships(
+		voterMemberships(true, circle.Voters...),
+		candidateMemberships(true, circle.Candidates...),
+	)
 
-			if len(circleVoters) > 0 {
-				for _, voter := range circleVoters {
-					voter.CircleID = circle.ID
-					voter.CircleRefer = &circle.ID
-				}
+	return circle, nil
+}
 
-				err = tx.Model(&model.CircleVoter{}).Create(circleVoters).Error
+// txCreateCircle with its voters, candidates and rounds in the given transaction
+func (s *storage) txCreateCircle(tx *gorm.DB, circle *model.Circle) error {
+	err := tx.Model(circle).Omit(clause.Associations).Create(circle).Error
 
-				if err != nil {
-					s.log.Error("error creating circle voters entry: %s", err)
-					return err
-				}
-			}
+	if err != nil {
+		s.log.Error("error creating circle entry: %s", err)
+		return err
+	}
 
-			circle.Voters = circleVoters
+	circleVoters := circle.Voters
 
-			circleCandidates := circle.Candidates
+	if len(circleVoters) > 0 {
+		for _, voter := range circleVoters {
+			voter.CircleID = circle.ID
+			voter.CircleRefer = &circle.ID
+		}
 
-			if len(circleCandidates) > 0 {
-				for _, candidate := range circleCandidates {
-					candidate.CircleID = circle.ID
-					candidate.CircleRefer = &circle.ID
-				}
+		err = tx.Model(&model.CircleVoter{}).Create(circleVoters).Error
 
-				err = tx.Model(&model.CircleCandidate{}).Create(circleCandidates).Error
+		if err != nil {
+			s.log.Error("error creating circle voters entry: %s", err)
+			return err
+		}
+	}
 
-				if err != nil {
-					s.log.Error("error creating circle candidates entry: %s", err)
-					return err
-				}
-			}
+	circle.Voters = circleVoters
 
-			circle.Candidates = circleCandidates
-			return nil
-		},
-	)
+	circleCandidates := circle.Candidates
 
-	if err != nil {
-		s.log.Error("error creating circle: %s", err)
-		return nil, err
+	if len(circleCandidates) > 0 {
+		for _, candidate := range circleCandidates {
+			candidate.CircleID = circle.ID
+			candidate.CircleRefer = &circle.ID
+		}
+
+		err = tx.Model(&model.CircleCandidate{}).Create(circleCandidates).Error
+
+		if err != nil {
+			s.log.Error("error creating circle candidates entry: %s", err)
+			return err
+		}
 	}
 
-	return circle, nil
+	circle.Candidates = circleCandidates
+
+	circleRounds := circle.Rounds
+
+	if len(circleRounds) > 0 {
+		for _, round := range circleRounds {
+			round.CircleID = circle.ID
+		}
+
+		err = tx.Model(&model.CircleRound{}).Omit(clause.Associations).Create(circleRounds).Error
+
+		if err != nil {
+			s.log.Error("error creating circle rounds entry: %s", err)
+			return err
+		}
+	}
+
+	circle.Rounds = circleRounds
+	return nil
 }
 
 // CountCirclesOfUser determines how many circles the user already obtains
@@ -292,3 +334,261 @@ func (s *storage) CountCirclesOfUser(
 
 	return count, nil
 }
+
+// CircleIdsOfUser gets the ids of all active circles the user
+// has created or is a voter or candidate of.
+func (s *storage) CircleIdsOfUser(
+	userIdentityId string,
+	limit int,
+) ([]int64, error) {
+	var circleIds []int64
+	err := s.db.Model(&model.Circle{}).Raw(
+		`SELECT circles.id
+			FROM circles
+			WHERE circles.active = ?
+			  AND (circles.created_from = ?
+				OR EXISTS(SELECT 1 FROM circle_voters voters WHERE voters.circle_id = circles.id AND voters.voter = ?)
+				OR EXISTS(SELECT 1 FROM circle_candidates candidates WHERE candidates.circle_id = circles.id AND candidates.candidate = ?))
+			ORDER BY circles.updated_at desc
+			LIMIT ?;`,
+		true,
+		userIdentityId,
+		userIdentityId,
+		userIdentityId,
+		limit,
+	).Scan(&circleIds).Error
+
+	switch {
+	case err != nil && !database.RecordNotFound(err):
+		s.log.Errorf("error reading circle ids of user id %s: %s", userIdentityId, err)
+		return nil, err
+	case database.RecordNotFound(err):
+		s.log.Infof("circles of user id %s not found: %s", userIdentityId, err)
+		return nil, err
+	}
+
+	return circleIds, nil
+}
+
+// RefreshCircleStages of the active circles, whose stage is outdated.
+// The stage of each circle is written with the outbox event of the stage
+// change in one transaction. Returns the refreshed circles.
+func (s *storage) RefreshCircleStages(limit int) ([]*model.Circle, error) {
+	var circles []*model.Circle
+	currentTime := time.Now().UTC()
+
+	err := s.db.Where("active = ?", true).
+		Where(
+			"(stage = ? AND valid_from <= ?) OR (stage <> ? AND valid_until <= ?)",
+			model.CircleStageCold,
+			currentTime,
+			model.CircleStageClosed,
+			currentTime,
+		).
+		Order("id").
+		Limit(limit).
+		Find(&circles).
+		Error
+
+	switch {
+	case err != nil && !database.RecordNotFound(err):
+		s.log.Errorf("error refreshing circle stages: %s", err)
+		return nil, err
+	case database.RecordNotFound(err):
+		s.log.Infof("circles with outdated stage not found: %s", err)
+		return nil, err
+	}
+
+	refreshedCircles := make([]*model.Circle, 0, len(circles))
+
+	for _, circle := range circles {
+		refreshed, err := s.updateCircleStage(circle)
+
+		if err != nil {
+			return nil, err
+		}
+
+		if refreshed {
+			refreshedCircles = append(refreshedCircles, circle)
+		}
+	}
+
+	return refreshedCircles, nil
+}
+
+// updateCircleStage writes the stage of the circle, that has been evaluated
+// on reading the circle, and the outbox event of the stage change.
+// Returns false, if the stage has already been written by another instance.
+func (s *storage) updateCircleStage(circle *model.Circle) (bool, error) {
+	refreshed := false
+
+	err := s.db.Transaction(
+		func(tx *gorm.DB) error {
+			result := tx.Model(&model.Circle{}).
+				Where("id = ? AND stage <> ?", circle.ID, circle.Stage).
+				UpdateColumn("stage", circle.Stage)
+
+			if result.Error != nil || result.RowsAffected == 0 {
+				return result.Error
+			}
+
+			refreshed = true
+
+			event, err := model.NewOutboxEvent(
+				circle.ID,
+				model.EventKindCircle,
+				model.NewCircleChangedEvent(model.EventOperationStageChanged, circle),
+			)
+
+			if err != nil {
+				return err
+			}
+
+			return s.txInsertOutboxEvents(tx, []*model.OutboxEvent{event})
+		},
+	)
+
+	if err != nil {
+		s.log.Errorf("error updating stage of circle id %d: %s", circle.ID, err)
+		return false, err
+	}
+
+	return refreshed, nil
+}
+
+// ClosedCirclesWithoutResult gets the active closed circles,
+// whose result has not been evaluated yet.
+func (s *storage) ClosedCirclesWithoutResult(limit int) ([]*model.Circle, error) {
+	var circles []*model.Circle
+
+	err := s.db.Where("active = ?", true).
+		Where("stage = ? AND result IS NULL", model.CircleStageClosed).
+		Order("id").
+		Limit(limit).
+		Find(&circles).
+		Error
+
+	switch {
+	case err != nil && !database.RecordNotFound(err):
+		s.log.Errorf("error reading closed circles without result: %s", err)
+		return nil, err
+	case database.RecordNotFound(err):
+		s.log.Infof("closed circles without result not found: %s", err)
+		return nil, err
+	}
+
+	return circles, nil
+}
+
+// UpdateCircleResult of the closed circle. Returns a record not found error,
+// if the result of the circle has already been evaluated.
+func (s *storage) UpdateCircleResult(
+	circle *model.Circle,
+	result model.CircleResult,
+	outboxEvents model.OutboxEventsCallback,
+) (*model.Circle, error) {
+	err := s.db.Transaction(
+		func(tx *gorm.DB) error {
+			updated := tx.Model(circle).
+				Where("result IS NULL").
+				Update("result", result)
+
+			if updated.Error != nil {
+				return updated.Error
+			}
+
+			if updated.RowsAffected == 0 {
+				return gorm.ErrRecordNotFound
+			}
+
+			return s.txCreateOutboxEvents(tx, outboxEvents)
+		},
+	)
+
+	if err != nil {
+		s.log.Errorf("error updating result of circle id %d: %s", circle.ID, err)
+		return nil, err
+	}
+
+	return circle, nil
+}
+
+// CirclesPastCommitmentDeadline gets the active circles, that are not closed
+// and whose commitment deadline passed while candidates or voters
+// have not answered their commitment yet.
+func (s *storage) CirclesPastCommitmentDeadline(limit int) ([]*model.Circle, error) {
+	var circles []*model.Circle
+
+	err := s.db.Where("active = ?", true).
+		Where("stage <> ? AND commitment_deadline <= ?", model.CircleStageClosed, time.Now().UTC()).
+		Where(
+			`EXISTS(SELECT 1 FROM circle_candidates WHERE circle_candidates.circle_id = circles.id AND circle_candidates.commitment = ?)
+			OR EXISTS(SELECT 1 FROM circle_voters WHERE circle_voters.circle_id = circles.id AND circle_voters.commitment = ?)`,
+			model.CommitmentOpen,
+			model.CommitmentOpen,
+		).
+		Order("id").
+		Limit(limit).
+		Find(&circles).
+		Error
+
+	switch {
+	case err != nil && !database.RecordNotFound(err):
+		s.log.Errorf("error reading circles past commitment deadline: %s", err)
+		return nil, err
+	case database.RecordNotFound(err):
+		s.log.Infof("circles past commitment deadline not found: %s", err)
+		return nil, err
+	}
+
+	return circles, nil
+}
+
+// RejectOpenCommitments of the candidates and voters of the circle, that have
+// not answered their commitment yet. The commitments, that have been answered
+// in the meantime, are kept.
+func (s *storage) RejectOpenCommitments(
+	circleId int64,
+	outboxEvents model.RejectedCommitmentsOutboxEventsCallback,
+) ([]*model.CircleCandidate, []*model.CircleVoter, error) {
+	var candidates []*model.CircleCandidate
+	var voters []*model.CircleVoter
+
+	err := s.db.Transaction(
+		func(tx *gorm.DB) error {
+			err := tx.Model(&candidates).
+				Clauses(clause.Returning{}).
+				Where(&model.CircleCandidate{CircleID: circleId, Commitment: model.CommitmentOpen}).
+				Update("commitment", model.CommitmentRejected).
+				Error
+
+			if err != nil {
+				return err
+			}
+
+			err = tx.Model(&voters).
+				Clauses(clause.Returning{}).
+				Where(&model.CircleVoter{CircleID: circleId, Commitment: model.CommitmentOpen}).
+				Update("commitment", model.CommitmentRejected).
+				Error
+
+			if err != nil {
+				return err
+			}
+
+			return s.txCreateRejectedCommitmentsOutboxEvents(tx, circleId, candidates, voters, outboxEvents)
+		},
+	)
+
+	if err != nil {
+		s.log.Errorf("error rejecting open commitments of circle id %d: %s", circleId, err)
+		return nil, nil, err
+	}
+
+	s.setCircleMemberships(
+		voterMemberships(true, voters...),
+		candidateMemberships(true, candidates...),
+	)
+
+	return candidates, voters, nil
+}
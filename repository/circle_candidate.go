@@ -3,35 +3,87 @@ package repository
 import (
 	"github.com/VerzCar/vyf-vote-circle/api/model"
 	"github.com/VerzCar/vyf-vote-circle/app/database"
+	"gorm.io/gorm"
 )
 
 // based on given CircleCandidate model
-func (s *storage) CreateNewCircleCandidate(candidate *model.CircleCandidate) (*model.CircleCandidate, error) {
-	if err := s.db.Create(candidate).Error; err != nil {
+func (s *storage) CreateNewCircleCandidate(
+	candidate *model.CircleCandidate,
+	outboxEvents model.OutboxEventsCallback,
+) (*model.CircleCandidate, error) {
+	err := s.db.Transaction(
+		func(tx *gorm.DB) error {
+			if err := tx.Create(candidate).Error; err != nil {
+				return err
+			}
+
+			return s.txCreateOutboxEvents(tx, outboxEvents)
+		},
+	)
+
+	if err != nil {
 		s.log.Infof("error creating circle candidate: %s", err)
 		return nil, err
 	}
 
+	s.setCircleMemberships(candidateMemberships(true, candidate))
+
 	return candidate, nil
 }
 
 // UpdateCircleCandidate update circle candidate based on given candidate model
-func (s *storage) UpdateCircleCandidate(candidate *model.CircleCandidate) (*model.CircleCandidate, error) {
-	if err := s.db.Save(candidate).Error; err != nil {
+func (s *storage) UpdateCircleCandidate(
+	candidate *model.CircleCandidate,
+	outboxEvents model.OutboxEventsCallback,
+) (*model.CircleCandidate, error) {
+	err := s.db.Transaction(
+		func(tx *gorm.DB) error {
+			if err := tx.Save(candidate).Error; err != nil {
+				return err
+			}
+
+			return s.txCreateOutboxEvents(tx, outboxEvents)
+		},
+	)
+
+	if err != nil {
 		s.log.Errorf("error updating candidate: %s", err)
 		return nil, err
 	}
 
+	s.setCircleMemberships(candidateMemberships(true, candidate))
+
 	return candidate, nil
 }
 
 // deletes circle candidate based on given candidate model
-func (s *storage) DeleteCircleCandidate(candidateId int64) error {
-	if err := s.db.Model(&model.CircleCandidate{}).Delete(&model.CircleCandidate{}, candidateId).Error; err != nil {
+func (s *storage) DeleteCircleCandidate(
+	candidateId int64,
+	outboxEvents model.OutboxEventsCallback,
+) error {
+	var candidates []*model.CircleCandidate
+
+	err := s.db.Transaction(
+		func(tx *gorm.DB) error {
+			if err := tx.Where("id = ?", candidateId).Find(&candidates).Error; err != nil {
+				return err
+			}
+
+			if err := tx.Model(&model.CircleCandidate{}).Delete(&model.CircleCandidate{}, candidateId).Error; err != nil {
+				return err
+			}
+
+			return s.txCreateOutboxEvents(tx, outboxEvents)
+		},
+	)
+
+	if err != nil {
 		s.log.Errorf("error deleting candidate: %s", err)
 		return err
 	}
 
+	s.setCircleMemberships(candidateMemberships(false, candidates...))
+
 	return nil
 }
 
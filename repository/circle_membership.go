@@ -0,0 +1,87 @@
+package repository
+
+import (
+	"context"
+	"github.com/VerzCar/vyf-vote-circle/api/model"
+	"github.com/VerzCar/vyf-vote-circle/app/cache"
+)
+
+type CircleMembershipCache interface {
+	SetCircleMembership(
+		ctx context.Context,
+		circleId int64,
+		member cache.CircleMember,
+		userIdentityId string,
+		isMember bool,
+		onlyIfAbsent bool,
+	) error
+}
+
+// circleMembership of a user in a circle, that has been changed by a write
+type circleMembership struct {
+	member         cache.CircleMember
+	userIdentityId string
+	circleId       int64
+	isMember       bool
+}
+
+// voterMemberships of the written voters
+func voterMemberships(isMember bool, voters ...*model.CircleVoter) []*circleMembership {
+	memberships := make([]*circleMembership, 0, len(voters))
+
+	for _, voter := range voters {
+		memberships = append(
+			memberships, &circleMembership{
+				member:         cache.CircleMemberVoter,
+				userIdentityId: voter.Voter,
+				circleId:       voter.CircleID,
+				isMember:       isMember,
+			},
+		)
+	}
+
+	return memberships
+}
+
+// candidateMemberships of the written candidates
+func candidateMemberships(isMember bool, candidates ...*model.CircleCandidate) []*circleMembership {
+	memberships := make([]*circleMembership, 0, len(candidates))
+
+	for _, candidate := range candidates {
+		memberships = append(
+			memberships, &circleMembership{
+				member:         cache.CircleMemberCandidate,
+				userIdentityId: candidate.Candidate,
+				circleId:       candidate.CircleID,
+				isMember:       isMember,
+			},
+		)
+	}
+
+	return memberships
+}
+
+// setCircleMemberships in the cache of the memberships, after the write
+// of the voters or candidates has been committed. Every write of the
+// voters and candidates must set the changed memberships, so that a
+// cached membership is never outdated. Without a cache nothing is set.
+func (s *storage) setCircleMemberships(memberships ...[]*circleMembership) {
+	if s.membershipCache == nil {
+		return
+	}
+
+	ctx := context.Background()
+
+	for _, changed := range memberships {
+		for _, membership := range changed {
+			_ = s.membershipCache.SetCircleMembership(
+				ctx,
+				membership.circleId,
+				membership.member,
+				membership.userIdentityId,
+				membership.isMember,
+				false,
+			)
+		}
+	}
+}
@@ -0,0 +1,166 @@
+package repository
+
+import (
+	"fmt"
+	"github.com/VerzCar/vyf-vote-circle/api/model"
+	"github.com/VerzCar/vyf-vote-circle/app/database"
+	"gorm.io/gorm"
+)
+
+// CircleNominations of the circle in the order they have been created
+func (s *storage) CircleNominations(circleId int64) ([]*model.CircleNomination, error) {
+	var nominations []*model.CircleNomination
+	err := s.db.Where(&model.CircleNomination{CircleID: circleId}).
+		Order("id").
+		Find(&nominations).
+		Error
+
+	switch {
+	case err != nil && !database.RecordNotFound(err):
+		s.log.Errorf("error reading nominations of circle id %d: %s", circleId, err)
+		return nil, err
+	case database.RecordNotFound(err):
+		s.log.Infof("nominations of circle id %d not found: %s", circleId, err)
+		return nil, err
+	}
+
+	return nominations, nil
+}
+
+// CircleNominationsOfNominee gets the nomination and the seconds of the nominee in the circle
+func (s *storage) CircleNominationsOfNominee(
+	circleId int64,
+	nominee string,
+) ([]*model.CircleNomination, error) {
+	var nominations []*model.CircleNomination
+	err := s.db.Where(&model.CircleNomination{CircleID: circleId, Nominee: nominee}).
+		Order("id").
+		Find(&nominations).
+		Error
+
+	switch {
+	case err != nil && !database.RecordNotFound(err):
+		s.log.Errorf("error reading nominations of nominee %s in circle id %d: %s", nominee, circleId, err)
+		return nil, err
+	case database.RecordNotFound(err):
+		s.log.Infof("nominations of nominee %s in circle id %d not found: %s", nominee, circleId, err)
+		return nil, err
+	}
+
+	return nominations, nil
+}
+
+// CountCircleNominationsOfVoter determines how many nominees the voter
+// nominated in the circle, the seconds of the voter are not counted.
+func (s *storage) CountCircleNominationsOfVoter(
+	circleId int64,
+	voter string,
+) (int64, error) {
+	var count int64
+	err := s.db.Model(&model.CircleNomination{}).
+		Where(&model.CircleNomination{CircleID: circleId, Voter: voter}).
+		Where("seconded = ?", false).
+		Count(&count).
+		Error
+
+	if err != nil {
+		s.log.Errorf("error counting nominations of voter %s in circle id %d: %s", voter, circleId, err)
+		return 0, err
+	}
+
+	return count, nil
+}
+
+// CreateNewCircleNomination based on given nomination model. The nominations
+// of the circle are locked for the transaction, the nomination is a second,
+// if the nominee has been nominated already. A nomination, that is not a
+// second, counts against the max nominations of the voter. Once the
+// nominations of the nominee reach the required seconds, the candidate is
+// created in the same transaction. If the candidate cannot be created,
+// the nomination is rolled back.
+// Returns the nominations of the nominee in the order they have been created.
+func (s *storage) CreateNewCircleNomination(
+	nomination *model.CircleNomination,
+	maxNominationsOfVoter int,
+	secondsRequired int,
+	candidate *model.CircleCandidate,
+	outboxEvents model.OutboxEventsCallback,
+) ([]*model.CircleNomination, error) {
+	var nominations []*model.CircleNomination
+	var candidates []*model.CircleCandidate
+
+	err := s.db.Transaction(
+		func(tx *gorm.DB) error {
+			err := tx.Exec("SELECT id FROM circles WHERE id = ? FOR UPDATE", nomination.CircleID).Error
+
+			if err != nil {
+				return err
+			}
+
+			err = tx.Where(&model.CircleNomination{CircleID: nomination.CircleID, Nominee: nomination.Nominee}).
+				Order("id").
+				Find(&nominations).
+				Error
+
+			if err != nil {
+				return err
+			}
+
+			nomination.Seconded = len(nominations) > 0
+
+			if !nomination.Seconded {
+				var count int64
+				err = tx.Model(&model.CircleNomination{}).
+					Where(&model.CircleNomination{CircleID: nomination.CircleID, Voter: nomination.Voter}).
+					Where("seconded = ?", false).
+					Count(&count).
+					Error
+
+				if err != nil {
+					return err
+				}
+
+				if count >= int64(maxNominationsOfVoter) {
+					return fmt.Errorf("voter can nominate at most %d candidates", maxNominationsOfVoter)
+				}
+			}
+
+			if err := tx.Create(nomination).Error; err != nil {
+				return err
+			}
+
+			nominations = append(nominations, nomination)
+
+			if len(nominations) < secondsRequired+1 {
+				return nil
+			}
+
+			var candidatesCount int64
+			err = tx.Model(&model.CircleCandidate{}).
+				Where(&model.CircleCandidate{Candidate: nomination.Nominee, CircleID: nomination.CircleID}).
+				Count(&candidatesCount).
+				Error
+
+			if err != nil || candidatesCount > 0 {
+				return err
+			}
+
+			if err := tx.Create(candidate).Error; err != nil {
+				return err
+			}
+
+			candidates = append(candidates, candidate)
+
+			return s.txCreateOutboxEvents(tx, outboxEvents)
+		},
+	)
+
+	if err != nil {
+		s.log.Errorf("error creating nomination of nominee %s: %s", nomination.Nominee, err)
+		return nil, err
+	}
+
+	s.setCircleMemberships(candidateMemberships(true, candidates...))
+
+	return nominations, nil
+}
@@ -0,0 +1,128 @@
+package repository
+
+import (
+	"github.com/VerzCar/vyf-vote-circle/api/model"
+	"github.com/VerzCar/vyf-vote-circle/app/database"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"time"
+)
+
+// CircleRounds of the multi round circle with the circle of each created round
+func (s *storage) CircleRounds(circleId int64) ([]*model.CircleRound, error) {
+	var rounds []*model.CircleRound
+	err := s.db.Preload("RoundCircle").
+		Where(&model.CircleRound{CircleID: circleId}).
+		Order("number").
+		Find(&rounds).
+		Error
+
+	switch {
+	case err != nil && !database.RecordNotFound(err):
+		s.log.Errorf("error reading rounds of circle id %d: %s", circleId, err)
+		return nil, err
+	case database.RecordNotFound(err):
+		s.log.Infof("rounds of circle id %d not found: %s", circleId, err)
+		return nil, err
+	}
+
+	return rounds, nil
+}
+
+// PendingCircleRounds gets the rounds, that have not been created yet,
+// whose previous round has been closed. Each round contains the id of the
+// closed circle of the previous round.
+func (s *storage) PendingCircleRounds(limit int) ([]*model.CircleRound, error) {
+	var rounds []*model.CircleRound
+	err := s.db.Model(&model.CircleRound{}).Raw(
+		`SELECT rounds.*, previous.id as previous_circle_id
+			FROM circle_rounds rounds
+				inner join circles previous on previous.round = rounds.number - 1
+					AND (previous.id = rounds.circle_id OR previous.first_round_id = rounds.circle_id)
+			WHERE rounds.round_circle_id IS NULL
+				AND previous.active = ?
+				AND previous.stage = ?
+			ORDER BY rounds.id
+			LIMIT ?;`,
+		true,
+		model.CircleStageClosed,
+		limit,
+	).Scan(&rounds).Error
+
+	switch {
+	case err != nil && !database.RecordNotFound(err):
+		s.log.Errorf("error reading pending circle rounds: %s", err)
+		return nil, err
+	case database.RecordNotFound(err):
+		s.log.Infof("pending circle rounds not found: %s", err)
+		return nil, err
+	}
+
+	return rounds, nil
+}
+
+// CreateCircleRound as the given circle with the voters of the circle of the
+// previous round. Returns a record not found error, if the round has
+// already been created.
+func (s *storage) CreateCircleRound(
+	round *model.CircleRound,
+	circle *model.Circle,
+) (*model.Circle, error) {
+	var copiedVoters []*model.CircleVoter
+
+	err := s.db.Transaction(
+		func(tx *gorm.DB) error {
+			err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+				Where("round_circle_id IS NULL").
+				First(&model.CircleRound{}, round.ID).
+				Error
+
+			if err != nil {
+				return err
+			}
+
+			if err := s.txCreateCircle(tx, circle); err != nil {
+				return err
+			}
+
+			now := time.Now()
+			err = tx.Raw(
+				`INSERT INTO circle_voters (voter, commitment, circle_id, circle_refer, created_at, updated_at)
+					SELECT voters.voter, voters.commitment, ?, ?, ?, ?
+					FROM circle_voters voters
+					WHERE voters.circle_id = ?
+					RETURNING voter, circle_id;`,
+				circle.ID,
+				circle.ID,
+				now,
+				now,
+				round.PreviousCircleID,
+			).Scan(&copiedVoters).Error
+
+			if err != nil {
+				s.log.Errorf("error copying voters of circle id %d: %s", round.PreviousCircleID, err)
+				return err
+			}
+
+			round.RoundCircleID = &circle.ID
+
+			return tx.Model(round).
+				Omit(clause.Associations).
+				Update("round_circle_id", circle.ID).
+				Error
+		},
+	)
+
+	if err != nil {
+		s.log.Errorf("error creating round %d of circle id %d: %s", round.Number, round.CircleID, err)
+		return nil, err
+	}
+
+	s.setCircleMemberships(
+		voterMemberships(true, circle.Voters...),
+		voterMemberships(true, copiedVoters...),
+		candidateMemberships(true, circle.Candidates...),
+	)
+
+	return circle, nil
+}
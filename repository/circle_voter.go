@@ -1,17 +1,35 @@
 package repository
 
 import (
+	"context"
 	"github.com/VerzCar/vyf-vote-circle/api/model"
+	"github.com/VerzCar/vyf-vote-circle/app/cache"
 	"github.com/VerzCar/vyf-vote-circle/app/database"
+	"gorm.io/gorm"
 )
 
 // CreateNewCircleVoter based on given CircleVoter model
-func (s *storage) CreateNewCircleVoter(voter *model.CircleVoter) (*model.CircleVoter, error) {
-	if err := s.db.Create(voter).Error; err != nil {
+func (s *storage) CreateNewCircleVoter(
+	voter *model.CircleVoter,
+	outboxEvents model.OutboxEventsCallback,
+) (*model.CircleVoter, error) {
+	err := s.db.Transaction(
+		func(tx *gorm.DB) error {
+			if err := tx.Create(voter).Error; err != nil {
+				return err
+			}
+
+			return s.txCreateOutboxEvents(tx, outboxEvents)
+		},
+	)
+
+	if err != nil {
 		s.log.Infof("error creating circle voter: %s", err)
 		return nil, err
 	}
 
+	s.setCircleMemberships(voterMemberships(true, voter))
+
 	return voter, nil
 }
 
@@ -22,17 +40,76 @@ func (s *storage) UpdateCircleVoter(voter *model.CircleVoter) (*model.CircleVote
 		return nil, err
 	}
 
+	s.setCircleMemberships(voterMemberships(true, voter))
+
 	return voter, nil
 }
 
-// deletes circle candidate based on given candidate model
-func (s *storage) DeleteCircleVoter(voterId int64) error {
-	if err := s.db.Model(&model.CircleVoter{}).Delete(&model.CircleVoter{}, voterId).Error; err != nil {
+// DeleteCircleVoter of the circle together with the delegations from and to the voter.
+// If delegations have been deleted, the weights of the votes and the rankings of the
+// affected candidates are updated in the same transaction.
+func (s *storage) DeleteCircleVoter(
+	ctx context.Context,
+	circleId int64,
+	voterId int64,
+	upsertRankingCache cache.UpsertRankingCacheCallback,
+	removeRankingCache cache.RemoveRankingCacheCallback,
+	outboxEvents model.OutboxEventsCallback,
+	rankingOutboxEvents model.RankingChangesOutboxEventsCallback,
+) ([]*model.RankingChange, error) {
+	var voters []*model.CircleVoter
+	var changes []*model.RankingChange
+
+	err := s.db.Transaction(
+		func(tx *gorm.DB) error {
+			if _, err := s.txLockVoteDelegations(tx, circleId); err != nil {
+				return err
+			}
+
+			deleted := tx.Where("circle_id = ?", circleId).
+				Where("(delegator_refer = ? OR delegate_refer = ?)", voterId, voterId).
+				Delete(&model.VoteDelegation{})
+
+			if deleted.Error != nil {
+				s.log.Errorf("error deleting delegations of voter id %d: %s", voterId, deleted.Error)
+				return deleted.Error
+			}
+
+			if err := tx.Where("id = ?", voterId).Find(&voters).Error; err != nil {
+				return err
+			}
+
+			if err := tx.Model(&model.CircleVoter{}).Delete(&model.CircleVoter{}, voterId).Error; err != nil {
+				return err
+			}
+
+			if err := s.txCreateOutboxEvents(tx, outboxEvents); err != nil {
+				return err
+			}
+
+			if deleted.RowsAffected == 0 {
+				return nil
+			}
+
+			var err error
+			changes, err = s.txUpdateDelegatedRankings(ctx, tx, circleId, upsertRankingCache, removeRankingCache)
+
+			if err != nil {
+				return err
+			}
+
+			return s.txCreateRankingChangesOutboxEvents(tx, circleId, changes, rankingOutboxEvents)
+		},
+	)
+
+	if err != nil {
 		s.log.Errorf("error deleting voter: %s", err)
-		return err
+		return nil, err
 	}
 
-	return nil
+	s.setCircleMemberships(voterMemberships(false, voters...))
+
+	return changes, nil
 }
 
 // CircleVoterByCircleId returns the queried circle voter in
@@ -130,3 +207,78 @@ func (s *storage) CircleVotersFiltered(
 
 	return circleVoters, nil
 }
+
+// UpdateCircleVoterAbstention of the voter. An abstaining voter casts a
+// ballot without voting for any candidate. Returns a record not found error,
+// if the abstention of the voter has already been changed.
+// As an abstention overrides the delegation of the voter, the rankings
+// of the candidates, whose delegated weight changed, are updated as well.
+func (s *storage) UpdateCircleVoterAbstention(
+	ctx context.Context,
+	voter *model.CircleVoter,
+	abstained bool,
+	upsertRankingCache cache.UpsertRankingCacheCallback,
+	outboxEvents model.RankingChangesOutboxEventsCallback,
+) (*model.CircleVoter, error) {
+	err := s.db.Transaction(
+		func(tx *gorm.DB) error {
+			result := tx.Model(voter).
+				Where("abstained = ?", !abstained).
+				Update("abstained", abstained)
+
+			if result.Error != nil {
+				return result.Error
+			}
+
+			if result.RowsAffected == 0 {
+				return gorm.ErrRecordNotFound
+			}
+
+			changes, err := s.txUpdateDelegatedRankings(ctx, tx, voter.CircleID, upsertRankingCache, nil)
+
+			if err != nil {
+				return err
+			}
+
+			return s.txCreateRankingChangesOutboxEvents(tx, voter.CircleID, changes, outboxEvents)
+		},
+	)
+
+	if err != nil {
+		s.log.Errorf("error updating abstention of voter id %d: %s", voter.ID, err)
+		return nil, err
+	}
+
+	return voter, nil
+}
+
+// CircleTurnout counts the voters of the circle, that voted for
+// any candidate or abstained.
+func (s *storage) CircleTurnout(circleId int64) (*model.CircleTurnout, error) {
+	turnout := &model.CircleTurnout{}
+	err := s.db.Model(&model.CircleVoter{}).Raw(
+		`SELECT count(voters.id) as voters,
+				count(voters.id) FILTER (WHERE EXISTS(
+					SELECT 1 FROM votes WHERE votes.voter_refer = voters.id
+				)) as ballots,
+				count(voters.id) FILTER (WHERE voters.abstained) as abstentions
+			FROM circle_voters voters
+			WHERE voters.circle_id = ?;`,
+		circleId,
+	).Scan(turnout).Error
+
+	switch {
+	case err != nil && !database.RecordNotFound(err):
+		s.log.Errorf("error reading turnout of circle id %d: %s", circleId, err)
+		return nil, err
+	case database.RecordNotFound(err):
+		s.log.Infof("turnout of circle id %d not found: %s", circleId, err)
+		return nil, err
+	}
+
+	if turnout.Voters > 0 {
+		turnout.Turnout = float64(turnout.Ballots+turnout.Abstentions) / float64(turnout.Voters)
+	}
+
+	return turnout, nil
+}
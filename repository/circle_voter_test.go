@@ -0,0 +1,103 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"github.com/VerzCar/vyf-vote-circle/api/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+// TestStorage_DeleteCircleVoter_Delegate removes a delegate, through whom the weight
+// of a delegator flows to the voter, that voted. The delegations from and to the
+// delegate are deleted and the weight of the vote is recalculated.
+// The test runs against the configured test database and is skipped
+// if the database is not reachable.
+func TestStorage_DeleteCircleVoter_Delegate(t *testing.T) {
+	s, db := openTestStorage(t)
+	ctx := context.Background()
+
+	var circleId int64
+	err := db.Raw(
+		`INSERT INTO circles(name, description, image_src, created_from, valid_from, delegation, created_at, updated_at)
+		VALUES ('delegation', '', '', 'test', now(), true, now(), now()) RETURNING id;`,
+	).Scan(&circleId).Error
+	require.NoError(t, err)
+
+	t.Cleanup(
+		func() {
+			for _, table := range []string{"vote_delegations", "rankings", "votes", "circle_voters", "circle_candidates"} {
+				db.Exec(fmt.Sprintf("DELETE FROM %s WHERE circle_id = ?", table), circleId)
+			}
+			db.Exec("DELETE FROM circles WHERE id = ?", circleId)
+		},
+	)
+
+	candidate := &model.CircleCandidate{Candidate: "candidate", CircleID: circleId, CircleRefer: &circleId}
+	require.NoError(t, db.Create(candidate).Error)
+
+	voters := make(map[string]*model.CircleVoter, 3)
+
+	for _, name := range []string{"voter", "delegate", "delegator"} {
+		voter := &model.CircleVoter{Voter: name, CircleID: circleId, CircleRefer: &circleId}
+		require.NoError(t, db.Create(voter).Error)
+		voters[name] = voter
+	}
+
+	vote := &model.Vote{
+		VoterRefer:     voters["voter"].ID,
+		CandidateRefer: candidate.ID,
+		CircleID:       circleId,
+		CircleRefer:    &circleId,
+		Score:          1,
+		Weight:         3,
+	}
+	require.NoError(t, db.Omit("Voter", "Candidate").Create(vote).Error)
+
+	ranking := &model.Ranking{IdentityID: candidate.Candidate, Total: 3, Ballots: 3, Average: 1, Score: 3, CircleID: circleId}
+	require.NoError(t, db.Omit("Circle").Create(ranking).Error)
+
+	delegations := []*model.VoteDelegation{
+		{DelegatorRefer: voters["delegate"].ID, DelegateRefer: voters["voter"].ID, CircleID: circleId},
+		{DelegatorRefer: voters["delegator"].ID, DelegateRefer: voters["delegate"].ID, CircleID: circleId},
+	}
+	require.NoError(t, db.Omit("Delegator", "Delegate").Create(&delegations).Error)
+
+	upsertRankingCache := func(
+		ctx context.Context,
+		circleId int64,
+		candidate *model.CircleCandidate,
+		ranking *model.Ranking,
+	) (*model.RankingResponse, error) {
+		return &model.RankingResponse{ID: ranking.ID, Ballots: ranking.Ballots, CircleID: circleId}, nil
+	}
+	removeRankingCache := func(ctx context.Context, circleId int64, candidate *model.CircleCandidate) error {
+		return nil
+	}
+
+	changes, err := s.DeleteCircleVoter(
+		ctx,
+		circleId,
+		voters["delegate"].ID,
+		upsertRankingCache,
+		removeRankingCache,
+		nil,
+		nil,
+	)
+	require.NoError(t, err)
+	require.Len(t, changes, 1)
+	assert.Equal(t, int64(1), changes[0].Ranking.Ballots)
+
+	var voterCount int64
+	require.NoError(t, db.Model(&model.CircleVoter{}).Where("circle_id = ?", circleId).Count(&voterCount).Error)
+	assert.Equal(t, int64(2), voterCount)
+
+	var delegationCount int64
+	require.NoError(t, db.Model(&model.VoteDelegation{}).Where("circle_id = ?", circleId).Count(&delegationCount).Error)
+	assert.Equal(t, int64(0), delegationCount)
+
+	var weight int64
+	require.NoError(t, db.Model(&model.Vote{}).Where("id = ?", vote.ID).Pluck("weight", &weight).Error)
+	assert.Equal(t, int64(1), weight)
+}
@@ -0,0 +1,7 @@
+BEGIN;
+
+drop table outbox_events;
+
+drop type outboxStatus;
+
+COMMIT;
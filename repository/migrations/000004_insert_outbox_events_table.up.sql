@@ -0,0 +1,32 @@
+BEGIN;
+
+CREATE TYPE outboxStatus AS ENUM (
+    'PENDING',
+    'DELIVERED',
+    'DEAD'
+    );
+
+create table outbox_events
+(
+    id              bigserial
+        constraint outbox_events_pkey
+            primary key,
+    kind            varchar(40)                                  not null,
+    payload         jsonb                                        not null,
+    status          outboxStatus default 'PENDING'::outboxStatus not null,
+    attempts        integer      default 0                       not null,
+    last_error      text         default ''                      not null,
+    next_attempt_at timestamp with time zone                     not null,
+    circle_id       bigint                                       not null,
+    created_at      timestamp with time zone,
+    updated_at      timestamp with time zone
+);
+
+create index idx_outbox_events_circle_id
+    on outbox_events (circle_id);
+
+create index idx_outbox_events_pending
+    on outbox_events (id)
+    where status = 'PENDING';
+
+COMMIT;
@@ -0,0 +1,9 @@
+BEGIN;
+
+drop table webhook_deliveries;
+
+drop table webhooks;
+
+drop type webhookDeliveryStatus;
+
+COMMIT;
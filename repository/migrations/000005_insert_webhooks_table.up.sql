@@ -0,0 +1,59 @@
+BEGIN;
+
+CREATE TYPE webhookDeliveryStatus AS ENUM (
+    'PENDING',
+    'DELIVERED',
+    'DEAD'
+    );
+
+create table webhooks
+(
+    id           bigserial
+        constraint webhooks_pkey
+            primary key,
+    url          text                  not null,
+    secret       varchar(64)           not null,
+    events       text[]                not null,
+    active       boolean default true  not null,
+    created_from varchar(50)           not null,
+    circle_id    bigint                not null
+        constraint fk_webhooks_circle
+            references circles
+            on delete cascade,
+    created_at   timestamp with time zone,
+    updated_at   timestamp with time zone
+);
+
+create index idx_webhooks_circle_id
+    on webhooks (circle_id);
+
+create table webhook_deliveries
+(
+    id              bigserial
+        constraint webhook_deliveries_pkey
+            primary key,
+    event           varchar(40)                                                    not null,
+    payload         jsonb                                                          not null,
+    status          webhookDeliveryStatus default 'PENDING'::webhookDeliveryStatus not null,
+    attempts        integer               default 0                                not null,
+    response_code   integer               default 0                                not null,
+    last_error      text                  default ''                               not null,
+    next_attempt_at timestamp with time zone                                       not null,
+    delivered_at    timestamp with time zone,
+    source_id       bigint                                                         not null,
+    webhook_id      bigint                                                         not null
+        constraint fk_webhook_deliveries_webhook
+            references webhooks
+            on delete cascade,
+    created_at      timestamp with time zone,
+    updated_at      timestamp with time zone
+);
+
+create unique index idx_webhook_deliveries_source
+    on webhook_deliveries (webhook_id, source_id, event);
+
+create index idx_webhook_deliveries_pending
+    on webhook_deliveries (next_attempt_at)
+    where status = 'PENDING';
+
+COMMIT;
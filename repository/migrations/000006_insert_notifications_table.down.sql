@@ -0,0 +1,13 @@
+BEGIN;
+
+drop table notification_deliveries;
+
+drop table notification_preferences;
+
+drop table notifications;
+
+drop type notificationDeliveryStatus;
+
+drop type notificationKind;
+
+COMMIT;
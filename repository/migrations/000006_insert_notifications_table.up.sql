@@ -0,0 +1,85 @@
+BEGIN;
+
+CREATE TYPE notificationKind AS ENUM (
+    'COMMITMENT_PENDING',
+    'CIRCLE_HOT',
+    'CIRCLE_CLOSING_SOON',
+    'CIRCLE_RESULTS'
+    );
+
+CREATE TYPE notificationDeliveryStatus AS ENUM (
+    'PENDING',
+    'DELIVERED',
+    'DEAD'
+    );
+
+create table notifications
+(
+    id               bigserial
+        constraint notifications_pkey
+            primary key,
+    user_identity_id varchar(50)      not null,
+    kind             notificationKind not null,
+    title            varchar(200)     not null,
+    message          text             not null,
+    dedupe_key       varchar(100)     not null,
+    read_at          timestamp with time zone,
+    circle_id        bigint           not null
+        constraint fk_notifications_circle
+            references circles
+            on delete cascade,
+    created_at       timestamp with time zone,
+    updated_at       timestamp with time zone
+);
+
+create unique index idx_notifications_dedupe
+    on notifications (user_identity_id, dedupe_key);
+
+create index idx_notifications_user_identity_id
+    on notifications (user_identity_id, id desc);
+
+create table notification_preferences
+(
+    id               bigserial
+        constraint notification_preferences_pkey
+            primary key,
+    user_identity_id varchar(50)           not null
+        constraint notification_preferences_user_identity_id_key
+            unique,
+    kinds            text[]                not null,
+    email            varchar(320)          default ''    not null,
+    email_enabled    boolean               default false not null,
+    webhook_url      text                  default ''    not null,
+    webhook_secret   varchar(64)           default ''    not null,
+    webhook_enabled  boolean               default false not null,
+    created_at       timestamp with time zone,
+    updated_at       timestamp with time zone
+);
+
+create table notification_deliveries
+(
+    id              bigserial
+        constraint notification_deliveries_pkey
+            primary key,
+    channel         varchar(20)                                                              not null,
+    status          notificationDeliveryStatus default 'PENDING'::notificationDeliveryStatus not null,
+    attempts        integer                    default 0                                     not null,
+    last_error      text                       default ''                                    not null,
+    next_attempt_at timestamp with time zone                                                 not null,
+    delivered_at    timestamp with time zone,
+    notification_id bigint                                                                   not null
+        constraint fk_notification_deliveries_notification
+            references notifications
+            on delete cascade,
+    created_at      timestamp with time zone,
+    updated_at      timestamp with time zone
+);
+
+create unique index idx_notification_deliveries_channel
+    on notification_deliveries (notification_id, channel);
+
+create index idx_notification_deliveries_pending
+    on notification_deliveries (next_attempt_at)
+    where status = 'PENDING';
+
+COMMIT;
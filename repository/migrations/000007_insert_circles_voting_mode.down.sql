@@ -0,0 +1,10 @@
+BEGIN;
+
+drop index idx_votes_voter_candidate;
+
+alter table circles
+    drop column voting_mode;
+
+drop type votingMode;
+
+COMMIT;
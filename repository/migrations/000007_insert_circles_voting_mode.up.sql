@@ -0,0 +1,14 @@
+BEGIN;
+
+CREATE TYPE votingMode AS ENUM (
+    'SINGLE',
+    'APPROVAL'
+    );
+
+alter table circles
+    add voting_mode votingMode default 'SINGLE'::votingMode not null;
+
+create unique index idx_votes_voter_candidate
+    on votes (voter_refer, candidate_refer);
+
+COMMIT;
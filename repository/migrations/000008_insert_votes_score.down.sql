@@ -0,0 +1,25 @@
+BEGIN;
+
+alter table rankings
+    drop column ballots,
+    drop column average,
+    drop column score;
+
+alter table rankings
+    rename column total to votes;
+
+alter table votes
+    drop column score;
+
+alter table circles
+    drop column ranking_order;
+
+drop type rankingOrder;
+
+-- the value SCORE of the votingMode type cannot be dropped,
+-- score circles fall back to single circles.
+update circles
+set voting_mode = 'SINGLE'
+where voting_mode = 'SCORE';
+
+COMMIT;
@@ -0,0 +1,30 @@
+BEGIN;
+
+ALTER TYPE votingMode ADD VALUE 'SCORE';
+
+CREATE TYPE rankingOrder AS ENUM (
+    'TOTAL',
+    'AVERAGE'
+    );
+
+alter table circles
+    add ranking_order rankingOrder default 'TOTAL'::rankingOrder not null;
+
+alter table votes
+    add score smallint default 1 not null
+        constraint chk_votes_score check (score between 0 and 5);
+
+alter table rankings
+    rename column votes to total;
+
+alter table rankings
+    add ballots bigint default 0 not null,
+    add average double precision default 0 not null,
+    add score double precision default 0 not null;
+
+update rankings
+set ballots = total,
+    average = case when total > 0 then 1 else 0 end,
+    score   = total;
+
+COMMIT;
@@ -0,0 +1,9 @@
+BEGIN;
+
+alter table circles
+    drop column none_of_the_above;
+
+alter table circle_voters
+    drop column abstained;
+
+COMMIT;
@@ -0,0 +1,9 @@
+BEGIN;
+
+alter table circle_voters
+    add abstained boolean default false not null;
+
+alter table circles
+    add none_of_the_above boolean default false not null;
+
+COMMIT;
@@ -0,0 +1,11 @@
+BEGIN;
+
+drop table vote_delegations;
+
+alter table votes
+    drop column weight;
+
+alter table circles
+    drop column delegation;
+
+COMMIT;
@@ -0,0 +1,36 @@
+BEGIN;
+
+alter table circles
+    add delegation boolean default false not null;
+
+alter table votes
+    add weight integer default 1 not null;
+
+create table vote_delegations
+(
+    id              bigserial
+        constraint vote_delegations_pkey
+            primary key,
+    delegator_refer bigint not null
+        constraint fk_vote_delegations_delegator
+            references circle_voters
+            on delete restrict,
+    delegate_refer  bigint not null
+        constraint fk_vote_delegations_delegate
+            references circle_voters
+            on delete restrict,
+    circle_id       bigint not null
+        constraint fk_vote_delegations_circle
+            references circles
+            on delete cascade,
+    created_at      timestamp with time zone,
+    updated_at      timestamp with time zone
+);
+
+create unique index idx_vote_delegations_delegator_refer
+    on vote_delegations (delegator_refer);
+
+create index idx_vote_delegations_circle_id
+    on vote_delegations (circle_id);
+
+COMMIT;
@@ -0,0 +1,14 @@
+BEGIN;
+
+alter table circles
+    drop column result;
+
+alter table circles
+    drop column quorum_winner_votes;
+
+alter table circles
+    drop column quorum_turnout;
+
+drop type circleResult;
+
+COMMIT;
@@ -0,0 +1,18 @@
+BEGIN;
+
+CREATE TYPE circleResult AS ENUM (
+    'VALID',
+    'INVALID',
+    'INCONCLUSIVE'
+    );
+
+alter table circles
+    add quorum_turnout double precision default 0 not null;
+
+alter table circles
+    add quorum_winner_votes bigint default 0 not null;
+
+alter table circles
+    add result circleResult;
+
+COMMIT;
@@ -0,0 +1,11 @@
+BEGIN;
+
+drop table circle_rounds;
+
+alter table circles
+    drop column first_round_id;
+
+alter table circles
+    drop column round;
+
+COMMIT;
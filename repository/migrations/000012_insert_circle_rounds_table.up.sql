@@ -0,0 +1,39 @@
+BEGIN;
+
+alter table circles
+    add round integer default 1 not null;
+
+alter table circles
+    add first_round_id bigint
+        constraint fk_circles_first_round
+            references circles;
+
+create table circle_rounds
+(
+    id              bigserial
+        constraint circle_rounds_pkey
+            primary key,
+    valid_from      timestamp with time zone not null,
+    valid_until     timestamp with time zone not null,
+    number          integer                  not null,
+    candidates      integer                  not null,
+    round_circle_id bigint
+        constraint fk_circle_rounds_round_circle
+            references circles
+            on delete restrict,
+    circle_id       bigint                   not null
+        constraint fk_circles_rounds
+            references circles
+            on delete cascade,
+    created_at      timestamp with time zone,
+    updated_at      timestamp with time zone
+);
+
+create unique index idx_circle_rounds_circle_number
+    on circle_rounds (circle_id, number);
+
+create index idx_circle_rounds_pending
+    on circle_rounds (circle_id)
+    where round_circle_id IS NULL;
+
+COMMIT;
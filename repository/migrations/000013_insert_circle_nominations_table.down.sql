@@ -0,0 +1,14 @@
+BEGIN;
+
+drop table circle_nominations;
+
+alter table circles
+    drop column nomination_seconds;
+
+alter table circles
+    drop column nominations_per_voter;
+
+alter table circles
+    drop column nomination;
+
+COMMIT;
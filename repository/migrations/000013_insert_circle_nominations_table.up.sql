@@ -0,0 +1,34 @@
+BEGIN;
+
+alter table circles
+    add nomination boolean default false not null;
+
+alter table circles
+    add nominations_per_voter integer default 1 not null;
+
+alter table circles
+    add nomination_seconds integer default 0 not null;
+
+create table circle_nominations
+(
+    id         bigserial
+        constraint circle_nominations_pkey
+            primary key,
+    nominee    varchar(50)              not null,
+    voter      varchar(50)              not null,
+    seconded   boolean default false    not null,
+    circle_id  bigint                   not null
+        constraint fk_circles_nominations
+            references circles
+            on delete cascade,
+    created_at timestamp with time zone,
+    updated_at timestamp with time zone
+);
+
+create index idx_circle_nominations_circle_id
+    on circle_nominations (circle_id);
+
+create unique index idx_circle_nominations_circle_nominee_voter
+    on circle_nominations (circle_id, nominee, voter);
+
+COMMIT;
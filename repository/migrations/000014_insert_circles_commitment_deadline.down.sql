@@ -0,0 +1,29 @@
+BEGIN;
+
+delete
+from notifications
+where kind = 'COMMITMENT_DEADLINE_SOON';
+
+update notification_preferences
+set kinds = array_remove(kinds, 'COMMITMENT_DEADLINE_SOON');
+
+ALTER TYPE notificationKind RENAME TO notificationKind_old;
+
+CREATE TYPE notificationKind AS ENUM (
+    'COMMITMENT_PENDING',
+    'CIRCLE_HOT',
+    'CIRCLE_CLOSING_SOON',
+    'CIRCLE_RESULTS'
+    );
+
+alter table notifications
+    alter column kind type notificationKind using kind::text::notificationKind;
+
+drop type notificationKind_old;
+
+drop index idx_circles_commitment_deadline;
+
+alter table circles
+    drop column commitment_deadline;
+
+COMMIT;
@@ -0,0 +1,16 @@
+BEGIN;
+
+alter table circles
+    add commitment_deadline timestamp with time zone;
+
+create index idx_circles_commitment_deadline
+    on circles (commitment_deadline)
+    where commitment_deadline IS NOT NULL;
+
+ALTER TYPE notificationKind ADD VALUE 'COMMITMENT_DEADLINE_SOON';
+
+update notification_preferences
+set kinds = array_append(kinds, 'COMMITMENT_DEADLINE_SOON')
+where 'COMMITMENT_PENDING' = any (kinds);
+
+COMMIT;
@@ -0,0 +1,7 @@
+BEGIN;
+
+drop table user_option_audits;
+
+drop index idx_user_options_identity_id;
+
+COMMIT;
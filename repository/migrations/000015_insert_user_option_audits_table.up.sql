@@ -0,0 +1,26 @@
+BEGIN;
+
+delete
+from user_options
+where id not in (select max(id) from user_options group by identity_id);
+
+create unique index idx_user_options_identity_id
+    on user_options (identity_id);
+
+create table user_option_audits
+(
+    id          bigserial
+        constraint user_option_audits_pkey
+            primary key,
+    identity_id varchar(50) not null,
+    changed_by  varchar(50) not null,
+    field       varchar(40) not null,
+    previous    varchar(20) not null,
+    current     varchar(20) not null,
+    created_at  timestamp with time zone
+);
+
+create index idx_user_option_audits_identity_id
+    on user_option_audits (identity_id);
+
+COMMIT;
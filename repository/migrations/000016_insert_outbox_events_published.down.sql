@@ -0,0 +1,6 @@
+BEGIN;
+
+alter table outbox_events
+    drop column published;
+
+COMMIT;
@@ -0,0 +1,6 @@
+BEGIN;
+
+alter table outbox_events
+    add published boolean default false not null;
+
+COMMIT;
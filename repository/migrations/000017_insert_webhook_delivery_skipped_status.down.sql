@@ -0,0 +1,26 @@
+BEGIN;
+
+delete
+from webhook_deliveries
+where status = 'SKIPPED';
+
+ALTER TYPE webhookDeliveryStatus RENAME TO webhookDeliveryStatus_old;
+
+CREATE TYPE webhookDeliveryStatus AS ENUM (
+    'PENDING',
+    'DELIVERED',
+    'DEAD'
+    );
+
+alter table webhook_deliveries
+    alter column status drop default;
+
+alter table webhook_deliveries
+    alter column status type webhookDeliveryStatus using status::text::webhookDeliveryStatus;
+
+alter table webhook_deliveries
+    alter column status set default 'PENDING'::webhookDeliveryStatus;
+
+drop type webhookDeliveryStatus_old;
+
+COMMIT;
@@ -0,0 +1,5 @@
+BEGIN;
+
+ALTER TYPE webhookDeliveryStatus ADD VALUE 'SKIPPED';
+
+COMMIT;
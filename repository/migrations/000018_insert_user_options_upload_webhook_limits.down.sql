@@ -0,0 +1,9 @@
+BEGIN;
+
+alter table user_options
+    drop column max_webhooks;
+
+alter table user_options
+    drop column max_image_uploads;
+
+COMMIT;
@@ -0,0 +1,9 @@
+BEGIN;
+
+alter table user_options
+    add max_image_uploads int default 0 not null;
+
+alter table user_options
+    add max_webhooks int default 0 not null;
+
+COMMIT;
@@ -0,0 +1,12 @@
+BEGIN;
+
+alter table notification_preferences
+    drop column email_verification_expires_at;
+
+alter table notification_preferences
+    drop column email_verification_hash;
+
+alter table notification_preferences
+    drop column email_verified_at;
+
+COMMIT;
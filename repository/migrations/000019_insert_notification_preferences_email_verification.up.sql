@@ -0,0 +1,12 @@
+BEGIN;
+
+alter table notification_preferences
+    add email_verified_at timestamp with time zone;
+
+alter table notification_preferences
+    add email_verification_hash varchar(64) default '' not null;
+
+alter table notification_preferences
+    add email_verification_expires_at timestamp with time zone;
+
+COMMIT;
@@ -0,0 +1,18 @@
+BEGIN;
+
+alter table user_options
+    alter column max_private_candidates drop default;
+
+alter table user_options
+    alter column max_private_voters drop default;
+
+alter table user_options
+    alter column max_candidates drop default;
+
+alter table user_options
+    alter column max_voters drop default;
+
+alter table user_options
+    alter column max_circles drop default;
+
+COMMIT;
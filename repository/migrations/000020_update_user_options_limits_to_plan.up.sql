@@ -0,0 +1,53 @@
+BEGIN;
+
+update user_options
+set max_circles = 0
+where not exists(select 1
+                 from user_option_audits
+                 where user_option_audits.identity_id = user_options.identity_id
+                   and user_option_audits.field = 'maxCircles');
+
+update user_options
+set max_voters = 0
+where not exists(select 1
+                 from user_option_audits
+                 where user_option_audits.identity_id = user_options.identity_id
+                   and user_option_audits.field = 'maxVoters');
+
+update user_options
+set max_candidates = 0
+where not exists(select 1
+                 from user_option_audits
+                 where user_option_audits.identity_id = user_options.identity_id
+                   and user_option_audits.field = 'maxCandidates');
+
+update user_options
+set max_private_voters = 0
+where not exists(select 1
+                 from user_option_audits
+                 where user_option_audits.identity_id = user_options.identity_id
+                   and user_option_audits.field = 'maxPrivateVoters');
+
+update user_options
+set max_private_candidates = 0
+where not exists(select 1
+                 from user_option_audits
+                 where user_option_audits.identity_id = user_options.identity_id
+                   and user_option_audits.field = 'maxPrivateCandidates');
+
+alter table user_options
+    alter column max_circles set default 0;
+
+alter table user_options
+    alter column max_voters set default 0;
+
+alter table user_options
+    alter column max_candidates set default 0;
+
+alter table user_options
+    alter column max_private_voters set default 0;
+
+alter table user_options
+    alter column max_private_candidates set default 0;
+
+COMMIT;
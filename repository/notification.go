@@ -0,0 +1,441 @@
+package repository
+
+import (
+	"github.com/VerzCar/vyf-vote-circle/api/model"
+	"github.com/VerzCar/vyf-vote-circle/app/database"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"time"
+)
+
+// NotificationsByUserIdentityId gets the latest notifications of the user
+func (s *storage) NotificationsByUserIdentityId(
+	userIdentityId string,
+	unread bool,
+	limit int,
+) ([]*model.Notification, error) {
+	var notifications []*model.Notification
+	query := s.db.Where(&model.Notification{UserIdentityID: userIdentityId})
+
+	if unread {
+		query = query.Where("read_at IS NULL")
+	}
+
+	err := query.Order("id desc").
+		Limit(limit).
+		Find(&notifications).
+		Error
+
+	switch {
+	case err != nil && !database.RecordNotFound(err):
+		s.log.Errorf("error reading notifications of user id %s: %s", userIdentityId, err)
+		return nil, err
+	case database.RecordNotFound(err):
+		s.log.Infof("notifications of user id %s not found: %s", userIdentityId, err)
+		return nil, err
+	}
+
+	return notifications, nil
+}
+
+// CountUnreadNotifications determines how many unread notifications the user has
+func (s *storage) CountUnreadNotifications(userIdentityId string) (int64, error) {
+	var count int64
+	err := s.db.Model(&model.Notification{}).
+		Where(&model.Notification{UserIdentityID: userIdentityId}).
+		Where("read_at IS NULL").
+		Count(&count).Error
+
+	if err != nil {
+		s.log.Errorf("error reading unread notification count of user id %s: %s", userIdentityId, err)
+		return 0, err
+	}
+
+	return count, nil
+}
+
+// MarkNotificationRead of the user. Returns a record not found error,
+// if the notification does not exist or belongs to another user.
+func (s *storage) MarkNotificationRead(
+	userIdentityId string,
+	notificationId int64,
+) error {
+	result := s.db.Model(&model.Notification{}).
+		Where(&model.Notification{ID: notificationId, UserIdentityID: userIdentityId}).
+		Where("read_at IS NULL").
+		Update("read_at", time.Now())
+
+	if result.Error != nil {
+		s.log.Errorf("error marking notification id %d as read: %s", notificationId, result.Error)
+		return result.Error
+	}
+
+	if result.RowsAffected > 0 {
+		return nil
+	}
+
+	// the notification may have already been read
+	var count int64
+	err := s.db.Model(&model.Notification{}).
+		Where(&model.Notification{ID: notificationId, UserIdentityID: userIdentityId}).
+		Count(&count).Error
+
+	if err != nil {
+		s.log.Errorf("error reading notification id %d: %s", notificationId, err)
+		return err
+	}
+
+	if count == 0 {
+		s.log.Infof("notification id %d of user id %s not found", notificationId, userIdentityId)
+		return gorm.ErrRecordNotFound
+	}
+
+	return nil
+}
+
+// MarkAllNotificationsRead of the user
+func (s *storage) MarkAllNotificationsRead(userIdentityId string) error {
+	err := s.db.Model(&model.Notification{}).
+		Where(&model.Notification{UserIdentityID: userIdentityId}).
+		Where("read_at IS NULL").
+		Update("read_at", time.Now()).
+		Error
+
+	if err != nil {
+		s.log.Errorf("error marking notifications of user id %s as read: %s", userIdentityId, err)
+		return err
+	}
+
+	return nil
+}
+
+// NotificationPreferenceByUserIdentityId gets the notification preference of the user
+func (s *storage) NotificationPreferenceByUserIdentityId(
+	userIdentityId string,
+) (*model.NotificationPreference, error) {
+	preference := &model.NotificationPreference{}
+	err := s.db.Where(&model.NotificationPreference{UserIdentityID: userIdentityId}).
+		First(preference).
+		Error
+
+	switch {
+	case err != nil && !database.RecordNotFound(err):
+		s.log.Errorf("error reading notification preference of user id %s: %s", userIdentityId, err)
+		return nil, err
+	case database.RecordNotFound(err):
+		s.log.Infof("notification preference of user id %s not found: %s", userIdentityId, err)
+		return nil, err
+	}
+
+	return preference, nil
+}
+
+// NotificationPreferencesByUserIdentityIds gets the notification preferences of the users.
+// Users without a preference are not contained.
+func (s *storage) NotificationPreferencesByUserIdentityIds(
+	userIdentityIds []string,
+) ([]*model.NotificationPreference, error) {
+	var preferences []*model.NotificationPreference
+	err := s.db.Where("user_identity_id IN ?", userIdentityIds).
+		Find(&preferences).
+		Error
+
+	if err != nil {
+		s.log.Errorf("error reading notification preferences: %s", err)
+		return nil, err
+	}
+
+	return preferences, nil
+}
+
+// SaveNotificationPreference based on given preference model
+func (s *storage) SaveNotificationPreference(
+	preference *model.NotificationPreference,
+) (*model.NotificationPreference, error) {
+	if err := s.db.Save(preference).Error; err != nil {
+		s.log.Errorf("error saving notification preference of user id %s: %s", preference.UserIdentityID, err)
+		return nil, err
+	}
+
+	return preference, nil
+}
+
+// CreateNotifications in the inbox of the users and the pending deliveries
+// to the given channels of each user. A notification with a dedupe key, the
+// user has already been notified with, is skipped.
+func (s *storage) CreateNotifications(
+	notifications []*model.Notification,
+	channels map[string][]model.NotificationChannel,
+) error {
+	if len(notifications) == 0 {
+		return nil
+	}
+
+	err := s.db.Transaction(
+		func(tx *gorm.DB) error {
+			var deliveries []*model.NotificationDelivery
+			now := time.Now()
+
+			// the notifications are created one by one, as the returned ids
+			// of a batch cannot be assigned if notifications have been skipped
+			for _, notification := range notifications {
+				result := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(notification)
+
+				if result.Error != nil {
+					s.log.Errorf("error creating notification: %s", result.Error)
+					return result.Error
+				}
+
+				if result.RowsAffected == 0 {
+					continue
+				}
+
+				for _, channel := range channels[notification.UserIdentityID] {
+					deliveries = append(
+						deliveries, &model.NotificationDelivery{
+							Channel:        channel,
+							Status:         model.NotificationDeliveryStatusPending,
+							NextAttemptAt:  now,
+							NotificationID: notification.ID,
+						},
+					)
+				}
+			}
+
+			if len(deliveries) == 0 {
+				return nil
+			}
+
+			if err := tx.Omit(clause.Associations).Create(&deliveries).Error; err != nil {
+				s.log.Errorf("error creating notification deliveries: %s", err)
+				return err
+			}
+
+			return nil
+		},
+	)
+
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// CircleMemberIdentityIds gets the identities of the creator, the voters
+// and the not rejected candidates of the circle.
+func (s *storage) CircleMemberIdentityIds(circleId int64) ([]string, error) {
+	var identityIds []string
+	err := s.db.Model(&model.Circle{}).Raw(
+		`SELECT circles.created_from FROM circles WHERE circles.id = ?
+			UNION
+			SELECT voters.voter FROM circle_voters voters WHERE voters.circle_id = ?
+			UNION
+			SELECT candidates.candidate FROM circle_candidates candidates
+			WHERE candidates.circle_id = ? AND candidates.commitment <> ? AND candidates.candidate <> ?;`,
+		circleId,
+		circleId,
+		circleId,
+		model.CommitmentRejected,
+		model.NoneOfTheAboveCandidate,
+	).Scan(&identityIds).Error
+
+	switch {
+	case err != nil && !database.RecordNotFound(err):
+		s.log.Errorf("error reading member identities of circle id %d: %s", circleId, err)
+		return nil, err
+	case database.RecordNotFound(err):
+		s.log.Infof("member identities of circle id %d not found: %s", circleId, err)
+		return nil, err
+	}
+
+	return identityIds, nil
+}
+
+// CirclesClosingSoon gets the hot circles closing before the given time,
+// whose members have not been notified about the closing yet.
+// The circles closing first are returned first.
+func (s *storage) CirclesClosingSoon(
+	until time.Time,
+	limit int,
+) ([]*model.Circle, error) {
+	var circles []*model.Circle
+	err := s.db.Where("active = ?", true).
+		Where("stage = ?", model.CircleStageHot).
+		Where("valid_until > ? AND valid_until <= ?", time.Now().UTC(), until).
+		Where(
+			"NOT EXISTS(SELECT 1 FROM notifications WHERE notifications.circle_id = circles.id AND notifications.kind = ?)",
+			model.NotificationKindCircleClosingSoon,
+		).
+		Order("valid_until").
+		Limit(limit).
+		Find(&circles).
+		Error
+
+	switch {
+	case err != nil && !database.RecordNotFound(err):
+		s.log.Errorf("error reading circles closing soon: %s", err)
+		return nil, err
+	case database.RecordNotFound(err):
+		s.log.Infof("circles closing soon not found: %s", err)
+		return nil, err
+	}
+
+	return circles, nil
+}
+
+// CirclesCommitmentDeadlineSoon gets the circles with open commitments, whose
+// commitment deadline is before the given time and whose creator has not been
+// warned about the deadline yet. The circles with the first deadline are returned first.
+func (s *storage) CirclesCommitmentDeadlineSoon(
+	until time.Time,
+	limit int,
+) ([]*model.Circle, error) {
+	var circles []*model.Circle
+	err := s.db.Where("active = ?", true).
+		Where("stage <> ?", model.CircleStageClosed).
+		Where("commitment_deadline > ? AND commitment_deadline <= ?", time.Now().UTC(), until).
+		Where(
+			`EXISTS(SELECT 1 FROM circle_candidates WHERE circle_candidates.circle_id = circles.id AND circle_candidates.commitment = ?)
+			OR EXISTS(SELECT 1 FROM circle_voters WHERE circle_voters.circle_id = circles.id AND circle_voters.commitment = ?)`,
+			model.CommitmentOpen,
+			model.CommitmentOpen,
+		).
+		Where(
+			"NOT EXISTS(SELECT 1 FROM notifications WHERE notifications.circle_id = circles.id AND notifications.kind = ?)",
+			model.NotificationKindCommitmentDeadlineSoon,
+		).
+		Order("commitment_deadline").
+		Limit(limit).
+		Find(&circles).
+		Error
+
+	switch {
+	case err != nil && !database.RecordNotFound(err):
+		s.log.Errorf("error reading circles with commitment deadline soon: %s", err)
+		return nil, err
+	case database.RecordNotFound(err):
+		s.log.Infof("circles with commitment deadline soon not found: %s", err)
+		return nil, err
+	}
+
+	return circles, nil
+}
+
+// ClaimNotificationDeliveries reads the due pending deliveries with their
+// notification and claims them for the given lease. The deliveries are locked
+// while they are claimed, so that several instances can claim different
+// deliveries at the same time. A claimed delivery is not due for the lease,
+// the result of the delivery is written with UpdateNotificationDeliveries.
+func (s *storage) ClaimNotificationDeliveries(
+	limit int,
+	lease time.Duration,
+) ([]*model.NotificationDelivery, error) {
+	var deliveries []*model.NotificationDelivery
+
+	err := s.db.Transaction(
+		func(tx *gorm.DB) error {
+			err := tx.Clauses(clause.Locking{Strength: "UPDATE", Options: "SKIP LOCKED"}).
+				Where(&model.NotificationDelivery{Status: model.NotificationDeliveryStatusPending}).
+				Where("next_attempt_at <= ?", time.Now()).
+				Order("id").
+				Limit(limit).
+				Find(&deliveries).
+				Error
+
+			if err != nil {
+				s.log.Errorf("error reading pending notification deliveries: %s", err)
+				return err
+			}
+
+			if len(deliveries) == 0 {
+				return nil
+			}
+
+			ids := make([]int64, 0, len(deliveries))
+
+			for _, delivery := range deliveries {
+				ids = append(ids, delivery.ID)
+			}
+
+			err = tx.Model(&model.NotificationDelivery{}).
+				Where("id IN ?", ids).
+				UpdateColumn("next_attempt_at", time.Now().Add(lease)).
+				Error
+
+			if err != nil {
+				s.log.Errorf("error claiming notification deliveries: %s", err)
+				return err
+			}
+
+			return s.preloadNotifications(tx, deliveries)
+		},
+	)
+
+	if err != nil {
+		s.log.Errorf("error claiming notification deliveries: %s", err)
+		return nil, err
+	}
+
+	return deliveries, nil
+}
+
+// UpdateNotificationDeliveries with the result of the delivery
+func (s *storage) UpdateNotificationDeliveries(deliveries []*model.NotificationDelivery) error {
+	err := s.db.Transaction(
+		func(tx *gorm.DB) error {
+			for _, delivery := range deliveries {
+				err := tx.Model(delivery).
+					Omit(clause.Associations).
+					Select("status", "attempts", "last_error", "next_attempt_at", "delivered_at").
+					Updates(delivery).
+					Error
+
+				if err != nil {
+					s.log.Errorf("error updating notification delivery id %d: %s", delivery.ID, err)
+					return err
+				}
+			}
+
+			return nil
+		},
+	)
+
+	if err != nil {
+		s.log.Errorf("error updating notification deliveries: %s", err)
+		return err
+	}
+
+	return nil
+}
+
+// preloadNotifications of the deliveries
+func (s *storage) preloadNotifications(
+	tx *gorm.DB,
+	deliveries []*model.NotificationDelivery,
+) error {
+	notificationIds := make([]int64, 0, len(deliveries))
+
+	for _, delivery := range deliveries {
+		notificationIds = append(notificationIds, delivery.NotificationID)
+	}
+
+	var notifications []*model.Notification
+
+	if err := tx.Find(&notifications, notificationIds).Error; err != nil {
+		s.log.Errorf("error reading notifications of deliveries: %s", err)
+		return err
+	}
+
+	notificationsById := make(map[int64]*model.Notification, len(notifications))
+
+	for _, notification := range notifications {
+		notificationsById[notification.ID] = notification
+	}
+
+	for _, delivery := range deliveries {
+		delivery.Notification = notificationsById[delivery.NotificationID]
+	}
+
+	return nil
+}
@@ -0,0 +1,267 @@
+package repository
+
+import (
+	"github.com/VerzCar/vyf-vote-circle/api/model"
+	"github.com/VerzCar/vyf-vote-circle/app/database"
+	"gorm.io/gorm"
+	"time"
+)
+
+// outboxDispatchLockKey of the advisory lock, that ensures
+// that only one instance dispatches the outbox events at a time.
+const outboxDispatchLockKey = int64(7_340_031)
+
+// ClaimOutboxEvents reads the pending outbox events ordered by creation and
+// claims them for the given lease. Circles, whose oldest pending event is not
+// due yet, are skipped to keep the order of the events per circle.
+// A claimed event is not due for the lease, therefore its circle is skipped
+// by all instances until the result of the delivery is written with
+// UpdateOutboxEvents or the lease expired.
+// If another instance claims the outbox events at the moment,
+// no events will be returned.
+func (s *storage) ClaimOutboxEvents(
+	limit int,
+	lease time.Duration,
+) ([]*model.OutboxEvent, error) {
+	var events []*model.OutboxEvent
+
+	err := s.db.Transaction(
+		func(tx *gorm.DB) error {
+			locked := false
+			err := tx.Raw("SELECT pg_try_advisory_xact_lock(?)", outboxDispatchLockKey).
+				Scan(&locked).
+				Error
+
+			if err != nil {
+				s.log.Errorf("error acquiring outbox dispatch lock: %s", err)
+				return err
+			}
+
+			if !locked {
+				return nil
+			}
+
+			err = tx.Where(&model.OutboxEvent{Status: model.OutboxStatusPending}).
+				Where(
+					"circle_id NOT IN (?)",
+					tx.Model(&model.OutboxEvent{}).
+						Select("circle_id").
+						Where(&model.OutboxEvent{Status: model.OutboxStatusPending}).
+						Where("next_attempt_at > ?", time.Now()),
+				).
+				Order("id").
+				Limit(limit).
+				Find(&events).
+				Error
+
+			if err != nil {
+				s.log.Errorf("error reading pending outbox events: %s", err)
+				return err
+			}
+
+			if len(events) == 0 {
+				return nil
+			}
+
+			ids := make([]int64, 0, len(events))
+
+			for _, event := range events {
+				ids = append(ids, event.ID)
+			}
+
+			// the claimed events keep their next attempt in memory, it will
+			// be written back for the events that have not been attempted
+			err = tx.Model(&model.OutboxEvent{}).
+				Where("id IN ?", ids).
+				UpdateColumn("next_attempt_at", time.Now().Add(lease)).
+				Error
+
+			if err != nil {
+				s.log.Errorf("error claiming outbox events: %s", err)
+				return err
+			}
+
+			return nil
+		},
+	)
+
+	if err != nil {
+		s.log.Errorf("error claiming outbox events: %s", err)
+		return nil, err
+	}
+
+	return events, nil
+}
+
+// UpdateOutboxEvents with the result of the delivery. Delivered events
+// will be removed, the changed state of all other events will be persisted.
+func (s *storage) UpdateOutboxEvents(events []*model.OutboxEvent) error {
+	err := s.db.Transaction(
+		func(tx *gorm.DB) error {
+			for _, event := range events {
+				var err error
+
+				if event.Status == model.OutboxStatusDelivered {
+					err = tx.Delete(&model.OutboxEvent{}, event.ID).Error
+				} else {
+					err = tx.Model(event).
+						Select("status", "attempts", "last_error", "next_attempt_at", "payload", "published").
+						Updates(event).
+						Error
+				}
+
+				if err != nil {
+					s.log.Errorf("error updating outbox event id %d: %s", event.ID, err)
+					return err
+				}
+			}
+
+			return nil
+		},
+	)
+
+	if err != nil {
+		s.log.Errorf("error updating outbox events: %s", err)
+		return err
+	}
+
+	return nil
+}
+
+// DeadOutboxEventsByCircleId gets all outbox events of the circle,
+// that could not be delivered.
+func (s *storage) DeadOutboxEventsByCircleId(circleId int64) ([]*model.OutboxEvent, error) {
+	var events []*model.OutboxEvent
+	err := s.db.Where(&model.OutboxEvent{CircleID: circleId, Status: model.OutboxStatusDead}).
+		Order("id desc").
+		Find(&events).Error
+
+	switch {
+	case err != nil && !database.RecordNotFound(err):
+		s.log.Errorf("error reading dead outbox events by circle id %d: %s", circleId, err)
+		return nil, err
+	case database.RecordNotFound(err):
+		s.log.Infof("dead outbox events with circle id %d not found: %s", circleId, err)
+		return nil, err
+	}
+
+	return events, nil
+}
+
+// txCreateOutboxEvents of the callback in the given transaction.
+// If no callback is given, no events will be created.
+func (s *storage) txCreateOutboxEvents(
+	tx *gorm.DB,
+	outboxEvents model.OutboxEventsCallback,
+) error {
+	if outboxEvents == nil {
+		return nil
+	}
+
+	events, err := outboxEvents()
+
+	if err != nil {
+		s.log.Errorf("error creating outbox events: %s", err)
+		return err
+	}
+
+	return s.txInsertOutboxEvents(tx, events)
+}
+
+// txCreateRankingOutboxEvents of the callback for the changed ranking
+// in the given transaction. The rankings passed to the callback are
+// read in the same transaction.
+// If no callback is given, no events will be created.
+func (s *storage) txCreateRankingOutboxEvents(
+	tx *gorm.DB,
+	circleId int64,
+	ranking *model.RankingResponse,
+	voteCount int64,
+	outboxEvents model.RankingOutboxEventsCallback,
+) error {
+	if outboxEvents == nil {
+		return nil
+	}
+
+	rankings := func() ([]*model.Ranking, error) {
+		return s.txRankingsByCircleId(tx, circleId)
+	}
+
+	events, err := outboxEvents(ranking, voteCount, rankings)
+
+	if err != nil {
+		s.log.Errorf("error creating outbox events for ranking id %d: %s", ranking.ID, err)
+		return err
+	}
+
+	return s.txInsertOutboxEvents(tx, events)
+}
+
+// txCreateRankingChangesOutboxEvents of the callback for the changed rankings
+// in the given transaction. The rankings passed to the callback are
+// read in the same transaction.
+// If no callback is given, no events will be created.
+func (s *storage) txCreateRankingChangesOutboxEvents(
+	tx *gorm.DB,
+	circleId int64,
+	changes []*model.RankingChange,
+	outboxEvents model.RankingChangesOutboxEventsCallback,
+) error {
+	if outboxEvents == nil {
+		return nil
+	}
+
+	rankings := func() ([]*model.Ranking, error) {
+		return s.txRankingsByCircleId(tx, circleId)
+	}
+
+	events, err := outboxEvents(changes, rankings)
+
+	if err != nil {
+		s.log.Errorf("error creating outbox events for ranking changes of circle id %d: %s", circleId, err)
+		return err
+	}
+
+	return s.txInsertOutboxEvents(tx, events)
+}
+
+// txCreateRejectedCommitmentsOutboxEvents of the callback for the rejected
+// commitments of the candidates and voters in the given transaction.
+// If no callback is given, no events will be created.
+func (s *storage) txCreateRejectedCommitmentsOutboxEvents(
+	tx *gorm.DB,
+	circleId int64,
+	candidates []*model.CircleCandidate,
+	voters []*model.CircleVoter,
+	outboxEvents model.RejectedCommitmentsOutboxEventsCallback,
+) error {
+	if outboxEvents == nil {
+		return nil
+	}
+
+	events, err := outboxEvents(candidates, voters)
+
+	if err != nil {
+		s.log.Errorf("error creating outbox events for rejected commitments of circle id %d: %s", circleId, err)
+		return err
+	}
+
+	return s.txInsertOutboxEvents(tx, events)
+}
+
+// txInsertOutboxEvents in the given transaction
+func (s *storage) txInsertOutboxEvents(
+	tx *gorm.DB,
+	events []*model.OutboxEvent,
+) error {
+	if len(events) == 0 {
+		return nil
+	}
+
+	if err := tx.Create(events).Error; err != nil {
+		s.log.Errorf("error inserting outbox events: %s", err)
+		return err
+	}
+
+	return nil
+}
@@ -1,6 +1,7 @@
 package repository
 
 import (
+	"database/sql"
 	"errors"
 	"github.com/VerzCar/vyf-vote-circle/api/model"
 	"github.com/VerzCar/vyf-vote-circle/app/database"
@@ -37,11 +38,14 @@ func (s *storage) DeleteRanking(rankingId int64) error {
 	return nil
 }
 
-// RankingsByCircleId gets all rankings by the given circle id
+// RankingsByCircleId gets all rankings by the given circle id.
+// Rankings with the same score are ordered by the identity in
+// reverse order, the same way as in the cached ranking list.
 func (s *storage) RankingsByCircleId(circleId int64) ([]*model.Ranking, error) {
 	var rankings []*model.Ranking
 	err := s.db.Where(&model.Ranking{CircleID: circleId}).
-		Order("votes desc").
+		Order("score desc").
+		Order("identity_id desc").
 		Find(&rankings).Error
 
 	switch {
@@ -56,6 +60,22 @@ func (s *storage) RankingsByCircleId(circleId int64) ([]*model.Ranking, error) {
 	return rankings, nil
 }
 
+// txRankingsByCircleId reads the rankings of the circle in the given transaction
+func (s *storage) txRankingsByCircleId(tx *gorm.DB, circleId int64) ([]*model.Ranking, error) {
+	var rankings []*model.Ranking
+	err := tx.Where(&model.Ranking{CircleID: circleId}).
+		Order("score desc").
+		Order("identity_id desc").
+		Find(&rankings).Error
+
+	if err != nil {
+		s.log.Errorf("error reading rankings by circle id %d: %s", circleId, err)
+		return nil, err
+	}
+
+	return rankings, nil
+}
+
 func (s *storage) RankingByCircleId(circleId int64, identityId string) (*model.Ranking, error) {
 	ranking := &model.Ranking{}
 	err := s.db.Where(&model.Ranking{IdentityID: identityId, CircleID: circleId}).
@@ -73,6 +93,102 @@ func (s *storage) RankingByCircleId(circleId int64, identityId string) (*model.R
 	return ranking, nil
 }
 
+// RankingCacheItems aggregates the votes of the circle grouped by candidate
+// in one single query. Each item contains the candidate and the persisted
+// ranking, if any exists, with the tally of the votes of the candidate.
+// Returns an empty list if the circle does not contain any votes.
+func (s *storage) RankingCacheItems(circleId int64) ([]*model.RankingCacheItem, error) {
+	rows, err := s.db.Model(&model.Vote{}).Raw(
+		`SELECT candidates.id,
+				candidates.candidate,
+				candidates.commitment,
+				candidates.circle_id,
+				candidates.created_at,
+				candidates.updated_at,
+				COALESCE(rankings.id, 0),
+				COALESCE(rankings.number, 0),
+				rankings.created_at,
+				rankings.updated_at,
+				circles.ranking_order,
+				COALESCE(sum(votes.weight), 0) as ballots,
+				COALESCE(sum(votes.score * votes.weight), 0) as total
+			FROM votes
+				inner join circles on circles.id = votes.circle_id
+				inner join circle_candidates candidates on candidates.id = votes.candidate_refer
+				left join rankings on rankings.circle_id = votes.circle_id
+					AND rankings.identity_id = candidates.candidate
+			WHERE votes.circle_id = ?
+			GROUP BY candidates.id, rankings.id, circles.ranking_order
+			ORDER BY total desc;`,
+		circleId,
+	).Rows()
+
+	switch {
+	case err != nil && !database.RecordNotFound(err):
+		s.log.Errorf("error reading ranking cache items by circle id %d: %s", circleId, err)
+		return nil, err
+	case database.RecordNotFound(err):
+		s.log.Infof("ranking cache items with circle id %d not found: %s", circleId, err)
+		return nil, err
+	}
+
+	items := make([]*model.RankingCacheItem, 0)
+
+	defer rows.Close()
+	for rows.Next() {
+		candidate := &model.CircleCandidate{}
+		ranking := &model.Ranking{}
+		var candidateCreatedAt, candidateUpdatedAt, rankingCreatedAt, rankingUpdatedAt sql.NullTime
+		var rankingOrder model.RankingOrder
+		tally := &model.RankingTally{}
+
+		err := rows.Scan(
+			&candidate.ID,
+			&candidate.Candidate,
+			&candidate.Commitment,
+			&candidate.CircleID,
+			&candidateCreatedAt,
+			&candidateUpdatedAt,
+			&ranking.ID,
+			&ranking.Number,
+			&rankingCreatedAt,
+			&rankingUpdatedAt,
+			&rankingOrder,
+			&tally.Ballots,
+			&tally.Total,
+		)
+
+		if err != nil {
+			s.log.Errorf("error scanning ranking cache items by circle id %d: %s", circleId, err)
+			return nil, err
+		}
+
+		candidate.CircleRefer = &candidate.CircleID
+		candidate.CreatedAt = candidateCreatedAt.Time
+		candidate.UpdatedAt = candidateUpdatedAt.Time
+
+		ranking.IdentityID = candidate.Candidate
+		ranking.CircleID = circleId
+		ranking.SetTally(tally, rankingOrder)
+		ranking.CreatedAt = rankingCreatedAt.Time
+		ranking.UpdatedAt = rankingUpdatedAt.Time
+
+		items = append(
+			items, &model.RankingCacheItem{
+				Candidate: candidate,
+				Ranking:   ranking,
+			},
+		)
+	}
+
+	if err := rows.Err(); err != nil {
+		s.log.Errorf("error reading ranking cache items by circle id %d: %s", circleId, err)
+		return nil, err
+	}
+
+	return items, nil
+}
+
 // CreateNewRanking based on given model.Ranking model
 func (s *storage) CreateNewRankingLastViewed(
 	circleId int64,
@@ -112,10 +228,13 @@ func (s *storage) RankingsLastViewedByUserIdentityId(identityId string) ([]*mode
 	return rankings, nil
 }
 
+// txUpsertRanking of the candidate with the tally of the votes and the
+// score of the given ranking order in the given transaction.
 func (s *storage) txUpsertRanking(
 	tx *gorm.DB,
 	circleId int64,
-	voteCount int64,
+	tally *model.RankingTally,
+	rankingOrder model.RankingOrder,
 	candidate *model.CircleCandidate,
 ) (*model.Ranking, error) {
 	ranking := &model.Ranking{}
@@ -137,9 +256,9 @@ func (s *storage) txUpsertRanking(
 		newRanking := &model.Ranking{
 			IdentityID: candidate.Candidate,
 			Number:     0,
-			Votes:      voteCount,
 			CircleID:   circleId,
 		}
+		newRanking.SetTally(tally, rankingOrder)
 
 		err = tx.Create(newRanking).Error
 
@@ -151,10 +270,11 @@ func (s *storage) txUpsertRanking(
 		ranking = newRanking
 		break
 	default:
-		ranking.Votes = voteCount
+		ranking.SetTally(tally, rankingOrder)
 
 		err = tx.Model(ranking).
-			Update("votes", voteCount).
+			Select("total", "ballots", "average", "score").
+			Updates(ranking).
 			Error
 
 		if err != nil {
@@ -0,0 +1,200 @@
+package repository
+
+import (
+	"fmt"
+	logger "github.com/VerzCar/vyf-lib-logger"
+	"github.com/VerzCar/vyf-vote-circle/api/model"
+	appConfig "github.com/VerzCar/vyf-vote-circle/app/config"
+	"github.com/VerzCar/vyf-vote-circle/utils"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+	gormLogger "gorm.io/gorm/logger"
+	"testing"
+)
+
+const (
+	benchVotesCount      = 100000
+	benchCandidatesCount = 50
+)
+
+// BenchmarkRankingCacheItems compares the aggregated query that is used to
+// rebuild the ranking cache against the former per vote approach on a
+// circle with 100k votes.
+// The benchmark runs against the configured test database and is skipped
+// if the database is not reachable.
+func BenchmarkRankingCacheItems(b *testing.B) {
+	s, circleId := setupBenchRankingCircle(b)
+
+	b.Run(
+		"aggregate", func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				items, err := s.RankingCacheItems(circleId)
+
+				if err != nil {
+					b.Fatal(err)
+				}
+				if len(items) != benchCandidatesCount {
+					b.Fatalf("expected %d items, got %d", benchCandidatesCount, len(items))
+				}
+			}
+		},
+	)
+
+	b.Run(
+		"per-vote", func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				items, err := rankingCacheItemsPerVote(s, circleId)
+
+				if err != nil {
+					b.Fatal(err)
+				}
+				if len(items) != benchVotesCount {
+					b.Fatalf("expected %d items, got %d", benchVotesCount, len(items))
+				}
+			}
+		},
+	)
+}
+
+// rankingCacheItemsPerVote reproduces the former rebuild of the ranking cache
+// that issued two queries for every single vote of the circle.
+func rankingCacheItemsPerVote(s *storage, circleId int64) ([]*model.RankingCacheItem, error) {
+	votes, err := s.Votes(circleId)
+
+	if err != nil {
+		return nil, err
+	}
+
+	var rankingCacheItems []*model.RankingCacheItem
+	for _, vote := range votes {
+		voteCount, err := s.CountsVotesOfCandidateByCircleId(circleId, vote.Candidate.ID)
+
+		if err != nil {
+			return nil, err
+		}
+
+		ranking, err := s.RankingByCircleId(circleId, vote.Candidate.Candidate)
+
+		if err != nil {
+			return nil, err
+		}
+
+		ranking.SetTally(&model.RankingTally{Total: voteCount, Ballots: voteCount}, model.RankingOrderTotal)
+
+		rankingCacheItems = append(
+			rankingCacheItems, &model.RankingCacheItem{
+				Candidate: vote.Candidate,
+				Ranking:   ranking,
+			},
+		)
+	}
+
+	return rankingCacheItems, nil
+}
+
+// setupBenchRankingCircle creates a circle with benchVotesCount votes
+// spread over benchCandidatesCount candidates. The circle is removed
+// after the benchmark has finished.
+func setupBenchRankingCircle(b *testing.B) (*storage, int64) {
+	b.Helper()
+
+	s, db := openTestStorage(b)
+
+	var circleId int64
+	err := db.Raw(
+		`INSERT INTO circles(name, description, image_src, created_from, valid_from, created_at, updated_at)
+		VALUES ('bench', '', '', 'bench', now(), now(), now()) RETURNING id;`,
+	).Scan(&circleId).Error
+
+	if err != nil {
+		b.Fatal(err)
+	}
+
+	seed := []string{
+		`INSERT INTO circle_candidates(candidate, commitment, circle_id, circle_refer, created_at, updated_at)
+		SELECT 'candidate-' || n, 'COMMITTED', @circle, @circle, now(), now()
+		FROM generate_series(1, @candidates) n;`,
+		`INSERT INTO circle_voters(voter, commitment, circle_id, circle_refer, created_at, updated_at)
+		SELECT 'voter-' || n, 'COMMITTED', @circle, @circle, now(), now()
+		FROM generate_series(1, @votes) n;`,
+		`INSERT INTO votes(voter_refer, candidate_refer, circle_id, circle_refer, created_at, updated_at)
+		SELECT voters.id, candidates.id, @circle, @circle, now(), now()
+		FROM circle_voters voters
+			inner join circle_candidates candidates on candidates.circle_id = voters.circle_id
+				AND candidates.candidate = 'candidate-' || (voters.id % @candidates + 1)
+		WHERE voters.circle_id = @circle;`,
+		`INSERT INTO rankings(identity_id, number, total, ballots, average, score, circle_id, created_at, updated_at)
+		SELECT candidates.candidate, 0, count(votes.id), count(votes.id), 1, count(votes.id), @circle, now(), now()
+		FROM votes
+			inner join circle_candidates candidates on candidates.id = votes.candidate_refer
+		WHERE votes.circle_id = @circle
+		GROUP BY candidates.candidate;`,
+	}
+
+	args := map[string]interface{}{
+		"circle":     circleId,
+		"candidates": benchCandidatesCount,
+		"votes":      benchVotesCount,
+	}
+
+	for _, query := range seed {
+		if err := db.Exec(query, args).Error; err != nil {
+			b.Fatal(err)
+		}
+	}
+
+	b.Cleanup(
+		func() {
+			for _, table := range []string{"rankings", "votes", "circle_voters", "circle_candidates"} {
+				db.Exec(fmt.Sprintf("DELETE FROM %s WHERE circle_id = ?", table), circleId)
+			}
+			db.Exec("DELETE FROM circles WHERE id = ?", circleId)
+		},
+	)
+
+	b.ResetTimer()
+
+	return s, circleId
+}
+
+// openTestStorage of the configured test database with the migrations applied.
+// The test is skipped if the database is not reachable.
+func openTestStorage(tb testing.TB) (*storage, *gorm.DB) {
+	tb.Helper()
+
+	configPath := utils.FromBase("app/config/")
+	config := appConfig.NewConfig(configPath)
+	log := logger.NewLogger(configPath)
+
+	dsn := fmt.Sprintf(
+		"host=%s port=%d user=%s dbname=%s password=%s sslmode=disable",
+		config.Db.Test.Host,
+		config.Db.Test.Port,
+		config.Db.Test.User,
+		config.Db.Test.Name,
+		config.Db.Test.Password,
+	)
+
+	db, err := gorm.Open(
+		postgres.Open(dsn),
+		&gorm.Config{Logger: gormLogger.Default.LogMode(gormLogger.Silent)},
+	)
+
+	if err != nil {
+		tb.Skipf("test database not reachable: %s", err)
+	}
+
+	sqlDb, err := db.DB()
+
+	if err != nil || sqlDb.Ping() != nil {
+		tb.Skip("test database not reachable")
+	}
+
+	s := &storage{db: db, config: config, log: log}
+
+	if err := s.RunMigrationsUp(sqlDb); err != nil {
+		tb.Fatal(err)
+	}
+
+	return s, db
+}
@@ -15,6 +15,7 @@ import (
 	_ "github.com/golang-migrate/migrate/v4/source/file"
 	_ "github.com/lib/pq"
 	"path/filepath"
+	"time"
 )
 
 type Storage interface {
@@ -26,30 +27,96 @@ type Storage interface {
 	Circles(userIdentityId string) ([]*model.Circle, error)
 	CirclesFiltered(name string) ([]*model.CirclePaginated, error)
 	CirclesOfInterest(userIdentityId string) ([]*model.CirclePaginated, error)
-	UpdateCircle(circle *model.Circle) (*model.Circle, error)
+	UpdateCircle(
+		circle *model.Circle,
+		outboxEvents model.OutboxEventsCallback,
+	) (*model.Circle, error)
+	RefreshCircleStages(limit int) ([]*model.Circle, error)
+	ClosedCirclesWithoutResult(limit int) ([]*model.Circle, error)
+	CirclesPastCommitmentDeadline(limit int) ([]*model.Circle, error)
+	RejectOpenCommitments(
+		circleId int64,
+		outboxEvents model.RejectedCommitmentsOutboxEventsCallback,
+	) ([]*model.CircleCandidate, []*model.CircleVoter, error)
+	CircleRounds(circleId int64) ([]*model.CircleRound, error)
+	PendingCircleRounds(limit int) ([]*model.CircleRound, error)
+	CreateCircleRound(
+		round *model.CircleRound,
+		circle *model.Circle,
+	) (*model.Circle, error)
+	UpdateCircleResult(
+		circle *model.Circle,
+		result model.CircleResult,
+		outboxEvents model.OutboxEventsCallback,
+	) (*model.Circle, error)
 	CreateNewCircle(circle *model.Circle) (*model.Circle, error)
 	CountCirclesOfUser(userIdentityId string) (int64, error)
+	CircleIdsOfUser(userIdentityId string, limit int) ([]int64, error)
 
-	CreateNewCircleVoter(voter *model.CircleVoter) (*model.CircleVoter, error)
+	CreateNewCircleVoter(
+		voter *model.CircleVoter,
+		outboxEvents model.OutboxEventsCallback,
+	) (*model.CircleVoter, error)
 	UpdateCircleVoter(voter *model.CircleVoter) (*model.CircleVoter, error)
-	DeleteCircleVoter(voterId int64) error
+	DeleteCircleVoter(
+		ctx context.Context,
+		circleId int64,
+		voterId int64,
+		upsertRankingCache cache.UpsertRankingCacheCallback,
+		removeRankingCache cache.RemoveRankingCacheCallback,
+		outboxEvents model.OutboxEventsCallback,
+		rankingOutboxEvents model.RankingChangesOutboxEventsCallback,
+	) ([]*model.RankingChange, error)
+	UpdateCircleVoterAbstention(
+		ctx context.Context,
+		voter *model.CircleVoter,
+		abstained bool,
+		upsertRankingCache cache.UpsertRankingCacheCallback,
+		outboxEvents model.RankingChangesOutboxEventsCallback,
+	) (*model.CircleVoter, error)
 	CircleVoterByCircleId(circleId int64, userIdentityId string) (*model.CircleVoter, error)
 	CircleVoterCountByCircleId(
 		circleId int64,
 	) (int64, error)
+	CircleTurnout(circleId int64) (*model.CircleTurnout, error)
 	IsVoterInCircle(userIdentityId string, circleId int64) (bool, error)
 	CircleVotersFiltered(
 		circleId int64,
 		filterBy *model.CircleVotersFilterBy,
 	) ([]*model.CircleVoter, error)
 
-	CreateNewCircleCandidate(candidate *model.CircleCandidate) (*model.CircleCandidate, error)
-	UpdateCircleCandidate(candidate *model.CircleCandidate) (*model.CircleCandidate, error)
-	DeleteCircleCandidate(candidateId int64) error
+	CreateNewCircleCandidate(
+		candidate *model.CircleCandidate,
+		outboxEvents model.OutboxEventsCallback,
+	) (*model.CircleCandidate, error)
+	UpdateCircleCandidate(
+		candidate *model.CircleCandidate,
+		outboxEvents model.OutboxEventsCallback,
+	) (*model.CircleCandidate, error)
+	DeleteCircleCandidate(
+		candidateId int64,
+		outboxEvents model.OutboxEventsCallback,
+	) error
 	CircleCandidateByCircleId(
 		circleId int64,
 		userIdentityId string,
 	) (*model.CircleCandidate, error)
+	CircleNominations(circleId int64) ([]*model.CircleNomination, error)
+	CircleNominationsOfNominee(
+		circleId int64,
+		nominee string,
+	) ([]*model.CircleNomination, error)
+	CountCircleNominationsOfVoter(
+		circleId int64,
+		voter string,
+	) (int64, error)
+	CreateNewCircleNomination(
+		nomination *model.CircleNomination,
+		maxNominationsOfVoter int,
+		secondsRequired int,
+		candidate *model.CircleCandidate,
+		outboxEvents model.OutboxEventsCallback,
+	) ([]*model.CircleNomination, error)
 	IsCandidateInCircle(
 		userIdentityId string,
 		circleId int64,
@@ -70,6 +137,7 @@ type Storage interface {
 	DeleteRanking(rankingId int64) error
 	RankingsByCircleId(circleId int64) ([]*model.Ranking, error)
 	RankingByCircleId(circleId int64, identityId string) (*model.Ranking, error)
+	RankingCacheItems(circleId int64) ([]*model.RankingCacheItem, error)
 	CreateNewRankingLastViewed(
 		circleId int64,
 		identityId string,
@@ -82,6 +150,7 @@ type Storage interface {
 		voter *model.CircleVoter,
 		candidate *model.CircleCandidate,
 		upsertRankingCache cache.UpsertRankingCacheCallback,
+		outboxEvents model.RankingOutboxEventsCallback,
 	) (*model.RankingResponse, int64, error)
 	DeleteVote(
 		ctx context.Context,
@@ -90,7 +159,29 @@ type Storage interface {
 		voter *model.CircleVoter,
 		upsertRankingCache cache.UpsertRankingCacheCallback,
 		removeRankingCache cache.RemoveRankingCacheCallback,
+		outboxEvents model.RankingOutboxEventsCallback,
 	) (*model.RankingResponse, int64, error)
+	CreateNewVotes(
+		ctx context.Context,
+		circleId int64,
+		voter *model.CircleVoter,
+		votes []*model.Vote,
+		upsertRankingCache cache.UpsertRankingCacheCallback,
+		outboxEvents model.RankingChangesOutboxEventsCallback,
+	) ([]*model.RankingChange, error)
+	DeleteVotes(
+		ctx context.Context,
+		circleId int64,
+		votes []*model.Vote,
+		voter *model.CircleVoter,
+		upsertRankingCache cache.UpsertRankingCacheCallback,
+		removeRankingCache cache.RemoveRankingCacheCallback,
+		outboxEvents model.RankingChangesOutboxEventsCallback,
+	) ([]*model.RankingChange, error)
+	VotesByVoterId(
+		circleId int64,
+		voterId int64,
+	) ([]*model.Vote, error)
 	VoteByCircleId(
 		circleId int64,
 		voterId int64,
@@ -109,15 +200,121 @@ type Storage interface {
 		circleId int64,
 	) (bool, error)
 
+	VoteDelegationByVoterId(
+		circleId int64,
+		voterId int64,
+	) (*model.VoteDelegation, error)
+	SaveVoteDelegation(
+		ctx context.Context,
+		delegation *model.VoteDelegation,
+		upsertRankingCache cache.UpsertRankingCacheCallback,
+		removeRankingCache cache.RemoveRankingCacheCallback,
+		outboxEvents model.RankingChangesOutboxEventsCallback,
+	) (*model.VoteDelegation, []*model.RankingChange, error)
+	DeleteVoteDelegation(
+		ctx context.Context,
+		delegation *model.VoteDelegation,
+		upsertRankingCache cache.UpsertRankingCacheCallback,
+		removeRankingCache cache.RemoveRankingCacheCallback,
+		outboxEvents model.RankingChangesOutboxEventsCallback,
+	) ([]*model.RankingChange, error)
+
+	ClaimOutboxEvents(
+		limit int,
+		lease time.Duration,
+	) ([]*model.OutboxEvent, error)
+	UpdateOutboxEvents(events []*model.OutboxEvent) error
+	DeadOutboxEventsByCircleId(circleId int64) ([]*model.OutboxEvent, error)
+
+	WebhookById(id int64) (*model.Webhook, error)
+	WebhooksByCircleId(circleId int64) ([]*model.Webhook, error)
+	CountWebhooksByCircleId(circleId int64) (int64, error)
+	CreateNewWebhook(webhook *model.Webhook) (*model.Webhook, error)
+	UpdateWebhook(webhook *model.Webhook) (*model.Webhook, error)
+	DeleteWebhook(webhookId int64) error
+	WebhookDeliveriesByWebhookId(
+		webhookId int64,
+		limit int,
+	) ([]*model.WebhookDelivery, error)
+	CreateWebhookDeliveries(
+		sourceId int64,
+		circleId int64,
+		event model.WebhookEvent,
+		payload string,
+	) error
+	ClaimWebhookDeliveries(
+		limit int,
+		lease time.Duration,
+	) ([]*model.WebhookDelivery, error)
+	UpdateWebhookDeliveries(deliveries []*model.WebhookDelivery) error
+
+	NotificationsByUserIdentityId(
+		userIdentityId string,
+		unread bool,
+		limit int,
+	) ([]*model.Notification, error)
+	CountUnreadNotifications(userIdentityId string) (int64, error)
+	MarkNotificationRead(
+		userIdentityId string,
+		notificationId int64,
+	) error
+	MarkAllNotificationsRead(userIdentityId string) error
+	NotificationPreferenceByUserIdentityId(
+		userIdentityId string,
+	) (*model.NotificationPreference, error)
+	NotificationPreferencesByUserIdentityIds(
+		userIdentityIds []string,
+	) ([]*model.NotificationPreference, error)
+	SaveNotificationPreference(
+		preference *model.NotificationPreference,
+	) (*model.NotificationPreference, error)
+	CreateNotifications(
+		notifications []*model.Notification,
+		channels map[string][]model.NotificationChannel,
+	) error
+	CircleMemberIdentityIds(circleId int64) ([]string, error)
+	CirclesClosingSoon(
+		until time.Time,
+		limit int,
+	) ([]*model.Circle, error)
+	CirclesCommitmentDeadlineSoon(
+		until time.Time,
+		limit int,
+	) ([]*model.Circle, error)
+	ClaimNotificationDeliveries(
+		limit int,
+		lease time.Duration,
+	) ([]*model.NotificationDelivery, error)
+	UpdateNotificationDeliveries(deliveries []*model.NotificationDelivery) error
+
 	CreateNewUserOption(option *model.UserOption) (*model.UserOption, error)
 	DeleteUserOption(optionId int64) error
 	UserOptionByUserIdentityId(userIdentityId string) (*model.UserOption, error)
+	CircleUsagesOfUser(
+		userIdentityId string,
+		imageSrcPattern string,
+	) ([]*model.CircleUsage, error)
+	SaveUserOption(
+		option *model.UserOption,
+		audits []*model.UserOptionAudit,
+	) (*model.UserOption, error)
+	UserOptionAuditsByUserIdentityId(
+		userIdentityId string,
+		limit int,
+	) ([]*model.UserOptionAudit, error)
+	CountUploadedCircleImagesOfUser(
+		userIdentityId string,
+		imageSrcPattern string,
+	) (int64, error)
 }
 
 type storage struct {
-	db     database.Client
-	config *config.Config
-	log    logger.Logger
+	db database.Client
+	// membershipCache of the memberships of the users in the circles,
+	// it is nil if the storage is not cached
+	membershipCache CircleMembershipCache
+	config          *config.Config
+	log             logger.Logger
 }
 
 func NewStorage(
@@ -5,8 +5,14 @@ import (
 	"github.com/VerzCar/vyf-vote-circle/app/database"
 )
 
-// CreateNewUserOption based on given UserOption model
+// CreateNewUserOption of the user with the package of the given option. The limits
+// are not stored, so that the quotas of the plan of the package apply to the user.
 func (s *storage) CreateNewUserOption(option *model.UserOption) (*model.UserOption, error) {
+	option = &model.UserOption{
+		IdentityID: option.IdentityID,
+		Package:    option.Package,
+	}
+
 	if err := s.db.Create(option).Error; err != nil {
 		s.log.Infof("error creating user option: %s", err)
 		return nil, err
@@ -40,3 +46,56 @@ func (s *storage) UserOptionByUserIdentityId(userIdentityId string) (*model.User
 
 	return option, nil
 }
+
+// CircleUsagesOfUser gets the counts of the voters, candidates and webhooks of
+// the active circles the user created. The image of a circle is counted as
+// uploaded if its source matches the given pattern.
+func (s *storage) CircleUsagesOfUser(
+	userIdentityId string,
+	imageSrcPattern string,
+) ([]*model.CircleUsage, error) {
+	var usages []*model.CircleUsage
+	err := s.db.Model(&model.Circle{}).Raw(
+		`SELECT circles.id, circles.name, circles.private,
+			(SELECT count(*) FROM circle_voters WHERE circle_voters.circle_id = circles.id) AS voters,
+			(SELECT count(*) FROM circle_candidates WHERE circle_candidates.circle_id = circles.id) AS candidates,
+			(SELECT count(*) FROM webhooks WHERE webhooks.circle_id = circles.id) AS webhooks,
+			circles.image_src LIKE ? AS image
+		FROM circles
+		WHERE circles.created_from = ? AND circles.active = true
+		ORDER BY circles.id`,
+		imageSrcPattern,
+		userIdentityId,
+	).Scan(&usages).Error
+
+	switch {
+	case err != nil && !database.RecordNotFound(err):
+		s.log.Errorf("error reading circle usages of user id %s: %s", userIdentityId, err)
+		return nil, err
+	case database.RecordNotFound(err):
+		s.log.Infof("circle usages of user id %s not found: %s", userIdentityId, err)
+		return nil, err
+	}
+
+	return usages, nil
+}
+
+// CountUploadedCircleImagesOfUser counts the active circles the user created,
+// whose image source matches the given pattern of the uploaded images.
+func (s *storage) CountUploadedCircleImagesOfUser(
+	userIdentityId string,
+	imageSrcPattern string,
+) (int64, error) {
+	var count int64
+	err := s.db.Model(&model.Circle{}).
+		Where(&model.Circle{CreatedFrom: userIdentityId, Active: true}).
+		Where("image_src LIKE ?", imageSrcPattern).
+		Count(&count).Error
+
+	if err != nil {
+		s.log.Errorf("error counting uploaded circle images of user id %s: %s", userIdentityId, err)
+		return 0, err
+	}
+
+	return count, nil
+}
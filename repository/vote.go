@@ -17,15 +17,16 @@ func (s *storage) CreateNewVote(
 	voter *model.CircleVoter,
 	candidate *model.CircleCandidate,
 	upsertRankingCache cache.UpsertRankingCacheCallback,
+	outboxEvents model.RankingOutboxEventsCallback,
 ) (*model.RankingResponse, int64, error) {
 	vote := &model.Vote{
 		VoterRefer:     voter.ID,
 		CandidateRefer: candidate.ID,
 		CircleID:       circleId,
 		CircleRefer:    &circleId,
+		Score:          1,
 	}
 	voteCount := int64(0)
-	ranking := &model.Ranking{}
 	var cachedRanking *model.RankingResponse
 
 	err := s.db.Transaction(
@@ -47,39 +48,20 @@ func (s *storage) CreateNewVote(
 				return err
 			}
 
-			err = tx.Model(&model.Vote{}).
-				Where(&model.Vote{CircleID: circleId, CircleRefer: &circleId, CandidateRefer: candidate.ID}).
-				Count(&voteCount).
-				Error
-
-			if err != nil {
-				s.log.Errorf("error reading votes for candidate id %d by circle id %d: %s", candidate.ID, circleId, err)
-				return err
-			}
-
-			ranking, err = s.txUpsertRanking(tx, circleId, voteCount, candidate)
-
-			if err != nil {
-				return err
-			}
-
-			cachedRanking, err = upsertRankingCache(ctx, circleId, candidate, ranking, voteCount)
+			cachedRanking, voteCount, err = s.txUpdateCandidateRanking(
+				ctx,
+				tx,
+				circleId,
+				candidate,
+				upsertRankingCache,
+				nil,
+			)
 
 			if err != nil {
 				return err
 			}
 
-			// update ranking with newly indexed order
-			err = tx.Model(&model.Ranking{ID: cachedRanking.ID}).
-				Update("number", cachedRanking.Number).
-				Error
-
-			if err != nil {
-				s.log.Errorf("error updating ranking for ranking id %d: %s", ranking.ID, err)
-				return err
-			}
-
-			return nil
+			return s.txCreateRankingOutboxEvents(tx, circleId, cachedRanking, voteCount, outboxEvents)
 		},
 	)
 
@@ -100,9 +82,9 @@ func (s *storage) DeleteVote(
 	voter *model.CircleVoter,
 	upsertRankingCache cache.UpsertRankingCacheCallback,
 	removeRankingCache cache.RemoveRankingCacheCallback,
+	outboxEvents model.RankingOutboxEventsCallback,
 ) (*model.RankingResponse, int64, error) {
 	voteCount := int64(0)
-	ranking := &model.Ranking{}
 	var cachedRanking *model.RankingResponse
 
 	err := s.db.Transaction(
@@ -124,95 +106,324 @@ func (s *storage) DeleteVote(
 				return err
 			}
 
-			err = tx.Model(&model.Vote{}).
-				Where(&model.Vote{CircleID: circleId, CircleRefer: &circleId, CandidateRefer: vote.Candidate.ID}).
-				Count(&voteCount).
-				Error
+			cachedRanking, voteCount, err = s.txUpdateCandidateRanking(
+				ctx,
+				tx,
+				circleId,
+				vote.Candidate,
+				upsertRankingCache,
+				removeRankingCache,
+			)
 
 			if err != nil {
-				s.log.Errorf(
-					"error reading votes for candidate id %d by circle id %d: %s",
-					vote.Candidate.ID,
-					circleId,
-					err,
-				)
 				return err
 			}
 
-			// if still has votes update ranking
-			if voteCount > 0 {
-				ranking, err = s.txUpsertRanking(tx, circleId, voteCount, vote.Candidate)
+			return s.txCreateRankingOutboxEvents(tx, circleId, cachedRanking, voteCount, outboxEvents)
+		},
+	)
+
+	if err != nil {
+		s.log.Error("error deleting vote: %s", err)
+		return nil, 0, err
+	}
+
+	return cachedRanking, voteCount, nil
+}
 
-				if err != nil {
-					return err
-				}
+// CreateNewVotes of the voter for several candidates at once, e.g. the
+// approvals or the scores of a ballot. Each vote must contain the candidate
+// and its score. The rankings of all candidates are updated in one transaction.
+func (s *storage) CreateNewVotes(
+	ctx context.Context,
+	circleId int64,
+	voter *model.CircleVoter,
+	votes []*model.Vote,
+	upsertRankingCache cache.UpsertRankingCacheCallback,
+	outboxEvents model.RankingChangesOutboxEventsCallback,
+) ([]*model.RankingChange, error) {
+	newVotes := make([]*model.Vote, 0, len(votes))
+
+	for _, vote := range votes {
+		newVotes = append(
+			newVotes, &model.Vote{
+				VoterRefer:     voter.ID,
+				CandidateRefer: vote.Candidate.ID,
+				CircleID:       circleId,
+				CircleRefer:    &circleId,
+				Score:          vote.Score,
+			},
+		)
+	}
 
-				cachedRanking, err = upsertRankingCache(ctx, circleId, vote.Candidate, ranking, voteCount)
+	var changes []*model.RankingChange
 
-				if err != nil {
-					return err
-				}
+	err := s.db.Transaction(
+		func(tx *gorm.DB) error {
+			err := tx.Model(&model.Vote{}).Create(newVotes).Error
 
-				// update ranking with newly indexed order
-				err = tx.Model(&model.Ranking{ID: cachedRanking.ID}).
-					Update("number", cachedRanking.Number).
-					Error
+			if err != nil {
+				s.log.Errorf("error creating votes in circle %d: %s", circleId, err)
+				return err
+			}
 
-				if err != nil {
-					s.log.Errorf("error updating ranking for ranking id %d: %s", ranking.ID, err)
-					return err
-				}
+			// the voter is marked as voted with the candidate of the first vote
+			voter.VotedFor = &votes[0].Candidate.Candidate
+			err = tx.Model(voter).Update("voted_for", votes[0].Candidate.Candidate).Error
 
-				return nil
+			if err != nil {
+				s.log.Errorf("error updating voter id %d for circle id %d: %s", voter.ID, circleId, err)
+				return err
 			}
 
-			// if it does not have any votes delete ranking
-			err = tx.Where(&model.Ranking{IdentityID: vote.Candidate.Candidate, CircleID: circleId}).
-				First(ranking).
-				Error
-
-			if err != nil && !database.RecordNotFound(err) {
-				s.log.Errorf(
-					"error reading ranking by circle id %d for user %s: %s",
-					circleId,
-					vote.Candidate.Candidate,
-					err,
-				)
+			changes, err = s.txUpdateVotedRankings(ctx, tx, circleId, votes, upsertRankingCache, nil)
+
+			if err != nil {
 				return err
 			}
 
-			// Ranking exists
-			if err == nil {
-				err = tx.Model(&model.Ranking{}).
-					Delete(&model.Ranking{}, ranking.ID).
-					Error
+			return s.txCreateRankingChangesOutboxEvents(tx, circleId, changes, outboxEvents)
+		},
+	)
+
+	if err != nil {
+		s.log.Errorf("error creating votes: %s", err)
+		return nil, err
+	}
+
+	return changes, nil
+}
+
+// DeleteVotes of the voter and updates the rankings of all
+// candidates of the votes in one transaction.
+func (s *storage) DeleteVotes(
+	ctx context.Context,
+	circleId int64,
+	votes []*model.Vote,
+	voter *model.CircleVoter,
+	upsertRankingCache cache.UpsertRankingCacheCallback,
+	removeRankingCache cache.RemoveRankingCacheCallback,
+	outboxEvents model.RankingChangesOutboxEventsCallback,
+) ([]*model.RankingChange, error) {
+	voteIds := make([]int64, 0, len(votes))
+
+	for _, vote := range votes {
+		voteIds = append(voteIds, vote.ID)
+	}
+
+	var changes []*model.RankingChange
+
+	err := s.db.Transaction(
+		func(tx *gorm.DB) error {
+			err := tx.Model(&model.Vote{}).Delete(&model.Vote{}, voteIds).Error
 
-				if err != nil {
-					s.log.Errorf("error deleting ranking: %s", err)
-					return err
-				}
+			if err != nil {
+				s.log.Errorf("error deleting votes of voter id %d: %s", voter.ID, err)
+				return err
 			}
 
-			err = removeRankingCache(ctx, circleId, vote.Candidate)
+			// update the voters meta information
+			voter.VotedFor = nil
+			err = tx.Model(voter).Update("voted_for", nil).Error
 
 			if err != nil {
+				s.log.Errorf("error updating voter id %d for circle id %d: %s", voter.ID, circleId, err)
 				return err
 			}
 
-			cachedRanking = &model.RankingResponse{
-				ID: ranking.ID,
+			changes, err = s.txUpdateVotedRankings(ctx, tx, circleId, votes, upsertRankingCache, removeRankingCache)
+
+			if err != nil {
+				return err
 			}
 
-			return nil
+			return s.txCreateRankingChangesOutboxEvents(tx, circleId, changes, outboxEvents)
 		},
 	)
 
 	if err != nil {
-		s.log.Error("error deleting vote: %s", err)
+		s.log.Errorf("error deleting votes: %s", err)
+		return nil, err
+	}
+
+	return changes, nil
+}
+
+// txUpdateVotedRankings of the candidates of the votes and of the candidates,
+// whose votes changed their weight, as the votes changed the delegated weights.
+func (s *storage) txUpdateVotedRankings(
+	ctx context.Context,
+	tx *gorm.DB,
+	circleId int64,
+	votes []*model.Vote,
+	upsertRankingCache cache.UpsertRankingCacheCallback,
+	removeRankingCache cache.RemoveRankingCacheCallback,
+) ([]*model.RankingChange, error) {
+	delegatedCandidates, err := s.txUpdateDelegatedWeights(tx, circleId)
+
+	if err != nil {
+		return nil, err
+	}
+
+	candidates := make([]*model.CircleCandidate, 0, len(votes)+len(delegatedCandidates))
+	candidateIds := make(map[int64]bool, len(votes))
+
+	for _, vote := range votes {
+		candidates = append(candidates, vote.Candidate)
+		candidateIds[vote.Candidate.ID] = true
+	}
+
+	for _, candidate := range delegatedCandidates {
+		if !candidateIds[candidate.ID] {
+			candidates = append(candidates, candidate)
+		}
+	}
+
+	return s.txUpdateCandidateRankings(ctx, tx, circleId, candidates, upsertRankingCache, removeRankingCache)
+}
+
+// txUpdateCandidateRankings of the given candidates in the given transaction
+func (s *storage) txUpdateCandidateRankings(
+	ctx context.Context,
+	tx *gorm.DB,
+	circleId int64,
+	candidates []*model.CircleCandidate,
+	upsertRankingCache cache.UpsertRankingCacheCallback,
+	removeRankingCache cache.RemoveRankingCacheCallback,
+) ([]*model.RankingChange, error) {
+	changes := make([]*model.RankingChange, 0, len(candidates))
+
+	for _, candidate := range candidates {
+		cachedRanking, voteCount, err := s.txUpdateCandidateRanking(
+			ctx,
+			tx,
+			circleId,
+			candidate,
+			upsertRankingCache,
+			removeRankingCache,
+		)
+
+		if err != nil {
+			return nil, err
+		}
+
+		changes = append(
+			changes, &model.RankingChange{
+				Candidate: candidate,
+				Ranking:   cachedRanking,
+				VoteCount: voteCount,
+			},
+		)
+	}
+
+	return changes, nil
+}
+
+// txUpdateCandidateRanking with the current tally of the votes of the candidate
+// in the given transaction. Each vote counts with its delegated weight.
+// The returned vote count is the number of votes of the candidate. If the candidate has no votes anymore, the ranking
+// will be removed and only the id of the removed ranking is returned.
+func (s *storage) txUpdateCandidateRanking(
+	ctx context.Context,
+	tx *gorm.DB,
+	circleId int64,
+	candidate *model.CircleCandidate,
+	upsertRankingCache cache.UpsertRankingCacheCallback,
+	removeRankingCache cache.RemoveRankingCacheCallback,
+) (*model.RankingResponse, int64, error) {
+	var votes struct {
+		Count   int64
+		Ballots int64
+		Total   int64
+	}
+
+	err := tx.Model(&model.Vote{}).
+		Select("count(id) as count, COALESCE(sum(weight), 0) as ballots, COALESCE(sum(score * weight), 0) as total").
+		Where(&model.Vote{CircleID: circleId, CircleRefer: &circleId, CandidateRefer: candidate.ID}).
+		Scan(&votes).
+		Error
+
+	if err != nil {
+		s.log.Errorf("error reading votes for candidate id %d by circle id %d: %s", candidate.ID, circleId, err)
 		return nil, 0, err
 	}
 
-	return cachedRanking, voteCount, nil
+	tally := &model.RankingTally{Total: votes.Total, Ballots: votes.Ballots}
+	voteCount := votes.Count
+
+	// if still has votes update ranking
+	if voteCount > 0 {
+		var rankingOrder model.RankingOrder
+
+		err = tx.Model(&model.Circle{}).
+			Select("ranking_order").
+			Where("id = ?", circleId).
+			Scan(&rankingOrder).
+			Error
+
+		if err != nil {
+			s.log.Errorf("error reading ranking order of circle id %d: %s", circleId, err)
+			return nil, 0, err
+		}
+
+		ranking, err := s.txUpsertRanking(tx, circleId, tally, rankingOrder, candidate)
+
+		if err != nil {
+			return nil, 0, err
+		}
+
+		cachedRanking, err := upsertRankingCache(ctx, circleId, candidate, ranking)
+
+		if err != nil {
+			return nil, 0, err
+		}
+
+		// update ranking with newly indexed order
+		err = tx.Model(&model.Ranking{ID: cachedRanking.ID}).
+			Update("number", cachedRanking.Number).
+			Error
+
+		if err != nil {
+			s.log.Errorf("error updating ranking for ranking id %d: %s", ranking.ID, err)
+			return nil, 0, err
+		}
+
+		return cachedRanking, voteCount, nil
+	}
+
+	// if it does not have any votes delete ranking
+	ranking := &model.Ranking{}
+	err = tx.Where(&model.Ranking{IdentityID: candidate.Candidate, CircleID: circleId}).
+		First(ranking).
+		Error
+
+	if err != nil && !database.RecordNotFound(err) {
+		s.log.Errorf(
+			"error reading ranking by circle id %d for user %s: %s",
+			circleId,
+			candidate.Candidate,
+			err,
+		)
+		return nil, 0, err
+	}
+
+	// Ranking exists
+	if err == nil {
+		err = tx.Model(&model.Ranking{}).
+			Delete(&model.Ranking{}, ranking.ID).
+			Error
+
+		if err != nil {
+			s.log.Errorf("error deleting ranking: %s", err)
+			return nil, 0, err
+		}
+	}
+
+	if err := removeRankingCache(ctx, circleId, candidate); err != nil {
+		return nil, 0, err
+	}
+
+	return &model.RankingResponse{ID: ranking.ID}, 0, nil
 }
 
 // Gets the number of votes for the candidate id
@@ -234,6 +445,29 @@ func (s *storage) CountsVotesOfCandidateByCircleId(circleId int64, candidateId i
 	return count, nil
 }
 
+// VotesByVoterId returns all votes of the voter in the circle
+func (s *storage) VotesByVoterId(
+	circleId int64,
+	voterId int64,
+) ([]*model.Vote, error) {
+	var votes []*model.Vote
+	err := s.db.Preload(clause.Associations).
+		Where(&model.Vote{VoterRefer: voterId, CircleID: circleId}).
+		Order("id").
+		Find(&votes).Error
+
+	switch {
+	case err != nil && !database.RecordNotFound(err):
+		s.log.Errorf("error reading votes for voter id %d by circle id %d: %s", voterId, circleId, err)
+		return nil, err
+	case database.RecordNotFound(err):
+		s.log.Infof("votes of voter id %d in circle %d not found: %s", voterId, circleId, err)
+		return nil, err
+	}
+
+	return votes, nil
+}
+
 // VoteByCircleId returns the queried vote in
 // the circle based on the given voter id
 func (s *storage) VoteByCircleId(
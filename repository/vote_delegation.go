@@ -0,0 +1,306 @@
+package repository
+
+import (
+	"context"
+	"github.com/VerzCar/vyf-vote-circle/api/model"
+	"github.com/VerzCar/vyf-vote-circle/app/cache"
+	"github.com/VerzCar/vyf-vote-circle/app/database"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// VoteDelegationByVoterId gets the delegation of the voter in the circle
+func (s *storage) VoteDelegationByVoterId(
+	circleId int64,
+	voterId int64,
+) (*model.VoteDelegation, error) {
+	delegation := &model.VoteDelegation{}
+	err := s.db.Preload(clause.Associations).
+		Where(&model.VoteDelegation{CircleID: circleId, DelegatorRefer: voterId}).
+		First(delegation).
+		Error
+
+	switch {
+	case err != nil && !database.RecordNotFound(err):
+		s.log.Errorf("error reading delegation of voter id %d by circle id %d: %s", voterId, circleId, err)
+		return nil, err
+	case database.RecordNotFound(err):
+		s.log.Infof("delegation of voter id %d in circle %d not found: %s", voterId, circleId, err)
+		return nil, err
+	}
+
+	return delegation, nil
+}
+
+// SaveVoteDelegation of the delegator to the delegate, an existing delegation of the
+// delegator is replaced. Returns a delegation cycle error, if the delegate delegates
+// directly or transitively to the delegator. The weights of the votes and the
+// rankings of the affected candidates are updated in the same transaction.
+func (s *storage) SaveVoteDelegation(
+	ctx context.Context,
+	delegation *model.VoteDelegation,
+	upsertRankingCache cache.UpsertRankingCacheCallback,
+	removeRankingCache cache.RemoveRankingCacheCallback,
+	outboxEvents model.RankingChangesOutboxEventsCallback,
+) (*model.VoteDelegation, []*model.RankingChange, error) {
+	circleId := delegation.CircleID
+	var changes []*model.RankingChange
+
+	err := s.db.Transaction(
+		func(tx *gorm.DB) error {
+			delegations, err := s.txLockVoteDelegations(tx, circleId)
+
+			if err != nil {
+				return err
+			}
+
+			if model.IsDelegationCycle(delegations, delegation.DelegatorRefer, delegation.DelegateRefer) {
+				s.log.Infof(
+					"delegation of voter id %d to voter id %d in circle id %d is a cycle",
+					delegation.DelegatorRefer,
+					delegation.DelegateRefer,
+					circleId,
+				)
+				return model.ErrDelegationCycle
+			}
+
+			err = tx.Omit(clause.Associations).
+				Clauses(
+					clause.OnConflict{
+						Columns:   []clause.Column{{Name: "delegator_refer"}},
+						DoUpdates: clause.AssignmentColumns([]string{"delegate_refer", "updated_at"}),
+					},
+				).
+				Create(delegation).
+				Error
+
+			if err != nil {
+				s.log.Errorf("error saving delegation of voter id %d: %s", delegation.DelegatorRefer, err)
+				return err
+			}
+
+			changes, err = s.txUpdateDelegatedRankings(ctx, tx, circleId, upsertRankingCache, removeRankingCache)
+
+			if err != nil {
+				return err
+			}
+
+			return s.txCreateRankingChangesOutboxEvents(tx, circleId, changes, outboxEvents)
+		},
+	)
+
+	if err != nil {
+		s.log.Errorf("error saving delegation: %s", err)
+		return nil, nil, err
+	}
+
+	return delegation, changes, nil
+}
+
+// DeleteVoteDelegation of the delegator and updates the weights of the votes
+// and the rankings of the affected candidates in one transaction.
+func (s *storage) DeleteVoteDelegation(
+	ctx context.Context,
+	delegation *model.VoteDelegation,
+	upsertRankingCache cache.UpsertRankingCacheCallback,
+	removeRankingCache cache.RemoveRankingCacheCallback,
+	outboxEvents model.RankingChangesOutboxEventsCallback,
+) ([]*model.RankingChange, error) {
+	circleId := delegation.CircleID
+	var changes []*model.RankingChange
+
+	err := s.db.Transaction(
+		func(tx *gorm.DB) error {
+			if _, err := s.txLockVoteDelegations(tx, circleId); err != nil {
+				return err
+			}
+
+			err := tx.Model(&model.VoteDelegation{}).
+				Delete(&model.VoteDelegation{}, delegation.ID).
+				Error
+
+			if err != nil {
+				s.log.Errorf("error deleting delegation id %d: %s", delegation.ID, err)
+				return err
+			}
+
+			changes, err = s.txUpdateDelegatedRankings(ctx, tx, circleId, upsertRankingCache, removeRankingCache)
+
+			if err != nil {
+				return err
+			}
+
+			return s.txCreateRankingChangesOutboxEvents(tx, circleId, changes, outboxEvents)
+		},
+	)
+
+	if err != nil {
+		s.log.Errorf("error deleting delegation: %s", err)
+		return nil, err
+	}
+
+	return changes, nil
+}
+
+// txLockVoteDelegations of the circle by locking the circle, so that the
+// delegations of a circle are changed one after another and no concurrent
+// delegations can close a cycle. Returns the delegates by the delegators.
+func (s *storage) txLockVoteDelegations(
+	tx *gorm.DB,
+	circleId int64,
+) (map[int64]int64, error) {
+	err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Select("id").
+		First(&model.Circle{}, circleId).
+		Error
+
+	if err != nil {
+		s.log.Errorf("error locking circle id %d: %s", circleId, err)
+		return nil, err
+	}
+
+	return s.txVoteDelegations(tx, circleId)
+}
+
+// txVoteDelegations of the circle as delegates by the delegators
+func (s *storage) txVoteDelegations(
+	tx *gorm.DB,
+	circleId int64,
+) (map[int64]int64, error) {
+	var delegations []*model.VoteDelegation
+	err := tx.Where(&model.VoteDelegation{CircleID: circleId}).
+		Find(&delegations).
+		Error
+
+	if err != nil {
+		s.log.Errorf("error reading delegations of circle id %d: %s", circleId, err)
+		return nil, err
+	}
+
+	delegates := make(map[int64]int64, len(delegations))
+
+	for _, delegation := range delegations {
+		delegates[delegation.DelegatorRefer] = delegation.DelegateRefer
+	}
+
+	return delegates, nil
+}
+
+// txUpdateDelegatedRankings updates the weights of the votes of the circle and the
+// rankings of the candidates, whose votes changed their weight.
+func (s *storage) txUpdateDelegatedRankings(
+	ctx context.Context,
+	tx *gorm.DB,
+	circleId int64,
+	upsertRankingCache cache.UpsertRankingCacheCallback,
+	removeRankingCache cache.RemoveRankingCacheCallback,
+) ([]*model.RankingChange, error) {
+	candidates, err := s.txUpdateDelegatedWeights(tx, circleId)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return s.txUpdateCandidateRankings(ctx, tx, circleId, candidates, upsertRankingCache, removeRankingCache)
+}
+
+// txUpdateDelegatedWeights of the votes of the circle with the current delegations
+// and returns the candidates, whose votes changed their weight.
+// Circles without delegations and weighted votes are skipped.
+func (s *storage) txUpdateDelegatedWeights(
+	tx *gorm.DB,
+	circleId int64,
+) ([]*model.CircleCandidate, error) {
+	var delegated bool
+	err := tx.Model(&model.VoteDelegation{}).Raw(
+		`SELECT EXISTS(SELECT 1 FROM vote_delegations WHERE circle_id = ?)
+			OR EXISTS(SELECT 1 FROM votes WHERE circle_id = ? AND weight <> 1);`,
+		circleId,
+		circleId,
+	).Scan(&delegated).Error
+
+	if err != nil {
+		s.log.Errorf("error reading delegations of circle id %d: %s", circleId, err)
+		return nil, err
+	}
+
+	if !delegated {
+		return nil, nil
+	}
+
+	delegations, err := s.txVoteDelegations(tx, circleId)
+
+	if err != nil {
+		return nil, err
+	}
+
+	var votes []*model.Vote
+	err = tx.Select("id", "voter_refer", "candidate_refer", "weight").
+		Where(&model.Vote{CircleID: circleId}).
+		Find(&votes).
+		Error
+
+	if err != nil {
+		s.log.Errorf("error reading votes by circle id %d: %s", circleId, err)
+		return nil, err
+	}
+
+	var abstainedVoterIds []int64
+	err = tx.Model(&model.CircleVoter{}).
+		Where("circle_id = ? AND abstained = ?", circleId, true).
+		Pluck("id", &abstainedVoterIds).
+		Error
+
+	if err != nil {
+		s.log.Errorf("error reading abstained voters by circle id %d: %s", circleId, err)
+		return nil, err
+	}
+
+	voted := make(map[int64]bool, len(votes))
+	abstained := make(map[int64]bool, len(abstainedVoterIds))
+
+	for _, vote := range votes {
+		voted[vote.VoterRefer] = true
+	}
+
+	for _, voterId := range abstainedVoterIds {
+		abstained[voterId] = true
+	}
+
+	weights := model.DelegatedWeights(delegations, voted, abstained)
+	candidateIds := make([]int64, 0)
+	changedCandidateIds := make(map[int64]bool)
+
+	for _, vote := range votes {
+		weight := weights[vote.VoterRefer]
+
+		if vote.Weight == weight {
+			continue
+		}
+
+		err = tx.Model(&model.Vote{ID: vote.ID}).Update("weight", weight).Error
+
+		if err != nil {
+			s.log.Errorf("error updating weight of vote id %d: %s", vote.ID, err)
+			return nil, err
+		}
+
+		if !changedCandidateIds[vote.CandidateRefer] {
+			changedCandidateIds[vote.CandidateRefer] = true
+			candidateIds = append(candidateIds, vote.CandidateRefer)
+		}
+	}
+
+	if len(candidateIds) == 0 {
+		return nil, nil
+	}
+
+	var candidates []*model.CircleCandidate
+
+	if err := tx.Order("id").Find(&candidates, candidateIds).Error; err != nil {
+		s.log.Errorf("error reading candidates of circle id %d: %s", circleId, err)
+		return nil, err
+	}
+
+	return candidates, nil
+}
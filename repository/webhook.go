@@ -0,0 +1,269 @@
+package repository
+
+import (
+	"github.com/VerzCar/vyf-vote-circle/api/model"
+	"github.com/VerzCar/vyf-vote-circle/app/database"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"time"
+)
+
+// WebhookById gets the webhook by id
+func (s *storage) WebhookById(id int64) (*model.Webhook, error) {
+	webhook := &model.Webhook{}
+	err := s.db.Where(&model.Webhook{ID: id}).
+		First(webhook).
+		Error
+
+	switch {
+	case err != nil && !database.RecordNotFound(err):
+		s.log.Errorf("error reading webhook by id %d: %s", id, err)
+		return nil, err
+	case database.RecordNotFound(err):
+		s.log.Infof("webhook with id %d not found: %s", id, err)
+		return nil, err
+	}
+
+	return webhook, nil
+}
+
+// WebhooksByCircleId gets all webhooks of the circle
+func (s *storage) WebhooksByCircleId(circleId int64) ([]*model.Webhook, error) {
+	var webhooks []*model.Webhook
+	err := s.db.Where(&model.Webhook{CircleID: circleId}).
+		Order("id").
+		Find(&webhooks).
+		Error
+
+	switch {
+	case err != nil && !database.RecordNotFound(err):
+		s.log.Errorf("error reading webhooks by circle id %d: %s", circleId, err)
+		return nil, err
+	case database.RecordNotFound(err):
+		s.log.Infof("webhooks with circle id %d not found: %s", circleId, err)
+		return nil, err
+	}
+
+	return webhooks, nil
+}
+
+// CountWebhooksByCircleId determines how many webhooks the circle has
+func (s *storage) CountWebhooksByCircleId(circleId int64) (int64, error) {
+	var count int64
+	err := s.db.Model(&model.Webhook{}).
+		Where(&model.Webhook{CircleID: circleId}).
+		Count(&count).Error
+
+	if err != nil {
+		s.log.Errorf("error reading webhook count by circle id %d: %s", circleId, err)
+		return 0, err
+	}
+
+	return count, nil
+}
+
+// CreateNewWebhook based on given webhook model
+func (s *storage) CreateNewWebhook(webhook *model.Webhook) (*model.Webhook, error) {
+	if err := s.db.Create(webhook).Error; err != nil {
+		s.log.Errorf("error creating webhook: %s", err)
+		return nil, err
+	}
+
+	return webhook, nil
+}
+
+// UpdateWebhook based on given webhook model
+func (s *storage) UpdateWebhook(webhook *model.Webhook) (*model.Webhook, error) {
+	if err := s.db.Save(webhook).Error; err != nil {
+		s.log.Errorf("error updating webhook: %s", err)
+		return nil, err
+	}
+
+	return webhook, nil
+}
+
+// DeleteWebhook with all its deliveries
+func (s *storage) DeleteWebhook(webhookId int64) error {
+	if err := s.db.Model(&model.Webhook{}).Delete(&model.Webhook{}, webhookId).Error; err != nil {
+		s.log.Errorf("error deleting webhook id %d: %s", webhookId, err)
+		return err
+	}
+
+	return nil
+}
+
+// WebhookDeliveriesByWebhookId gets the latest deliveries of the webhook
+func (s *storage) WebhookDeliveriesByWebhookId(
+	webhookId int64,
+	limit int,
+) ([]*model.WebhookDelivery, error) {
+	var deliveries []*model.WebhookDelivery
+	err := s.db.Where(&model.WebhookDelivery{WebhookID: webhookId}).
+		Order("id desc").
+		Limit(limit).
+		Find(&deliveries).
+		Error
+
+	switch {
+	case err != nil && !database.RecordNotFound(err):
+		s.log.Errorf("error reading webhook deliveries by webhook id %d: %s", webhookId, err)
+		return nil, err
+	case database.RecordNotFound(err):
+		s.log.Infof("webhook deliveries with webhook id %d not found: %s", webhookId, err)
+		return nil, err
+	}
+
+	return deliveries, nil
+}
+
+// CreateWebhookDeliveries of the event for all active webhooks of the circle,
+// that subscribed to the event. A delivery of the same source and event
+// is created only once per webhook.
+func (s *storage) CreateWebhookDeliveries(
+	sourceId int64,
+	circleId int64,
+	event model.WebhookEvent,
+	payload string,
+) error {
+	err := s.db.Model(&model.WebhookDelivery{}).Exec(
+		`INSERT INTO webhook_deliveries (event, payload, status, next_attempt_at, source_id, webhook_id, created_at, updated_at)
+			SELECT ?, CAST(? AS jsonb), CAST(? AS webhookDeliveryStatus), now(), ?, webhooks.id, now(), now()
+			FROM webhooks
+			WHERE webhooks.circle_id = ?
+			  AND webhooks.active = ?
+			  AND ? = ANY (webhooks.events)
+			ON CONFLICT (webhook_id, source_id, event) DO NOTHING;`,
+		string(event),
+		payload,
+		string(model.WebhookDeliveryStatusPending),
+		sourceId,
+		circleId,
+		true,
+		string(event),
+	).Error
+
+	if err != nil {
+		s.log.Errorf("error creating webhook deliveries of circle id %d: %s", circleId, err)
+		return err
+	}
+
+	return nil
+}
+
+// ClaimWebhookDeliveries reads the due pending deliveries with their webhook
+// and claims them for the given lease. The deliveries are locked while they
+// are claimed, so that several instances can claim different deliveries at
+// the same time. A claimed delivery is not due for the lease, the result of
+// the delivery is written with UpdateWebhookDeliveries.
+func (s *storage) ClaimWebhookDeliveries(
+	limit int,
+	lease time.Duration,
+) ([]*model.WebhookDelivery, error) {
+	var deliveries []*model.WebhookDelivery
+
+	err := s.db.Transaction(
+		func(tx *gorm.DB) error {
+			err := tx.Clauses(clause.Locking{Strength: "UPDATE", Options: "SKIP LOCKED"}).
+				Where(&model.WebhookDelivery{Status: model.WebhookDeliveryStatusPending}).
+				Where("next_attempt_at <= ?", time.Now()).
+				Order("id").
+				Limit(limit).
+				Find(&deliveries).
+				Error
+
+			if err != nil {
+				s.log.Errorf("error reading pending webhook deliveries: %s", err)
+				return err
+			}
+
+			if len(deliveries) == 0 {
+				return nil
+			}
+
+			ids := make([]int64, 0, len(deliveries))
+
+			for _, delivery := range deliveries {
+				ids = append(ids, delivery.ID)
+			}
+
+			err = tx.Model(&model.WebhookDelivery{}).
+				Where("id IN ?", ids).
+				UpdateColumn("next_attempt_at", time.Now().Add(lease)).
+				Error
+
+			if err != nil {
+				s.log.Errorf("error claiming webhook deliveries: %s", err)
+				return err
+			}
+
+			return s.preloadWebhooks(tx, deliveries)
+		},
+	)
+
+	if err != nil {
+		s.log.Errorf("error claiming webhook deliveries: %s", err)
+		return nil, err
+	}
+
+	return deliveries, nil
+}
+
+// UpdateWebhookDeliveries with the result of the delivery
+func (s *storage) UpdateWebhookDeliveries(deliveries []*model.WebhookDelivery) error {
+	err := s.db.Transaction(
+		func(tx *gorm.DB) error {
+			for _, delivery := range deliveries {
+				err := tx.Model(delivery).
+					Omit(clause.Associations).
+					Select("status", "attempts", "response_code", "last_error", "next_attempt_at", "delivered_at").
+					Updates(delivery).
+					Error
+
+				if err != nil {
+					s.log.Errorf("error updating webhook delivery id %d: %s", delivery.ID, err)
+					return err
+				}
+			}
+
+			return nil
+		},
+	)
+
+	if err != nil {
+		s.log.Errorf("error updating webhook deliveries: %s", err)
+		return err
+	}
+
+	return nil
+}
+
+// preloadWebhooks of the deliveries
+func (s *storage) preloadWebhooks(
+	tx *gorm.DB,
+	deliveries []*model.WebhookDelivery,
+) error {
+	webhookIds := make([]int64, 0, len(deliveries))
+
+	for _, delivery := range deliveries {
+		webhookIds = append(webhookIds, delivery.WebhookID)
+	}
+
+	var webhooks []*model.Webhook
+
+	if err := tx.Find(&webhooks, webhookIds).Error; err != nil {
+		s.log.Errorf("error reading webhooks of deliveries: %s", err)
+		return err
+	}
+
+	webhooksById := make(map[int64]*model.Webhook, len(webhooks))
+
+	for _, webhook := range webhooks {
+		webhooksById[webhook.ID] = webhook
+	}
+
+	for _, delivery := range deliveries {
+		delivery.Webhook = webhooksById[delivery.WebhookID]
+	}
+
+	return nil
+}